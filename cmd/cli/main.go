@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"os"
 
-	"kdb/internal/cli"
-	"kdb/internal/config"
-	logger "kdb/internal/logs"
-	"kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/cli"
+	"github.com/cat-go-dev/kdb/internal/config"
+	logger "github.com/cat-go-dev/kdb/internal/logs"
+	"github.com/cat-go-dev/kdb/internal/network/tcp"
 
 	"github.com/joho/godotenv"
 )
@@ -3,17 +3,28 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
-	"kdb/internal/config"
-	"kdb/internal/database"
-	"kdb/internal/database/compute"
-	"kdb/internal/database/storage"
-	"kdb/internal/database/storage/engine"
-	logger "kdb/internal/logs"
-	"kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/config"
+	"github.com/cat-go-dev/kdb/internal/database"
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	_ "github.com/cat-go-dev/kdb/internal/database/storage/engine/lsm"
+	_ "github.com/cat-go-dev/kdb/internal/database/storage/engine/mvcc"
+	"github.com/cat-go-dev/kdb/internal/database/storage/snapshot"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	logger "github.com/cat-go-dev/kdb/internal/logs"
+	"github.com/cat-go-dev/kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/utils"
 )
 
 func init() {
@@ -25,7 +36,7 @@ func init() {
 const defaultEnv string = "local"
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
 	env, ok := os.LookupEnv("ENV")
 	if !ok {
@@ -55,13 +66,86 @@ func main() {
 		return
 	}
 
-	storage, err := storage.NewStorage(engine.NewEngine(), logger)
+	engine, err := engine.New(cfg.Data.Engine, logger)
+	if err != nil {
+		wErr := fmt.Errorf("creating engine: %w", err)
+		logger.ErrorContext(ctx, wErr.Error())
+		return
+	}
+	// the engine is closed after the WAL, which applies its last writes
+	if closer, ok := engine.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	var storageOpts []storage.Option
+	if cfg.Data.WAL != nil {
+		wal, err := newWAL(cfg.Data.WAL, logger)
+		if err != nil {
+			wErr := fmt.Errorf("creating wal: %w", err)
+			logger.ErrorContext(ctx, wErr.Error())
+			return
+		}
+		defer wal.Close()
+
+		storageOpts = append(storageOpts, storage.WithWAL(wal))
+	}
+
+	var snapshotInterval time.Duration
+	if cfg.Data.Snapshot != nil {
+		store, err := snapshot.NewStore(logger, &snapshot.Opts{
+			DataDir: cfg.Data.Snapshot.DataDirectory,
+		})
+		if err != nil {
+			wErr := fmt.Errorf("creating snapshot store: %w", err)
+			logger.ErrorContext(ctx, wErr.Error())
+			return
+		}
+
+		if cfg.Data.Snapshot.Interval != "" {
+			snapshotInterval, err = time.ParseDuration(cfg.Data.Snapshot.Interval)
+			if err != nil {
+				wErr := fmt.Errorf("parsing snapshot interval: %w", err)
+				logger.ErrorContext(ctx, wErr.Error())
+				return
+			}
+		}
+
+		storageOpts = append(storageOpts, storage.WithSnapshots(store))
+	}
+
+	storage, err := storage.NewStorage(engine, logger, storageOpts...)
 	if err != nil {
 		wErr := fmt.Errorf("creating storage: %w", err)
 		logger.ErrorContext(ctx, wErr.Error())
 		return
 	}
 
+	err = storage.Recover(ctx)
+	if err != nil {
+		wErr := fmt.Errorf("recovering storage: %w", err)
+		logger.ErrorContext(ctx, wErr.Error())
+		return
+	}
+
+	// background loops are stopped before the WAL and the engine are closed
+	var background sync.WaitGroup
+	defer background.Wait()
+	defer cancel()
+
+	if snapshotInterval > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			storage.RunSnapshots(ctx, snapshotInterval)
+		}()
+	}
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		storage.RunExpiration(ctx)
+	}()
+
 	database, err := database.NewDatabase(compute, storage, logger)
 	if err != nil {
 		wErr := fmt.Errorf("creating database: %w", err)
@@ -73,6 +157,7 @@ func main() {
 		Host:           cfg.Data.Network.Host,
 		Port:           uint(cfg.Data.Network.Port),
 		MaxConnections: uint(cfg.Data.Network.MaxConnections),
+		Protocol:       cfg.Data.Network.Protocol,
 	})
 	if err != nil {
 		wErr := fmt.Errorf("creating tcp server: %w", err)
@@ -85,6 +170,29 @@ func main() {
 		wErr := fmt.Errorf("running tcp server: %w", err)
 		logger.ErrorContext(ctx, wErr.Error())
 	}
+}
+
+func newWAL(cfg *config.WAL, logger *slog.Logger) (*wal.WAL, error) {
+	opts := &wal.Opts{
+		DataDir:      cfg.DataDirectory,
+		MaxBatchSize: cfg.MaxBatchSize,
+	}
+
+	if cfg.FlushTimeout != "" {
+		flushTimeout, err := time.ParseDuration(cfg.FlushTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("parsing flush timeout: %w", err)
+		}
+		opts.FlushTimeout = flushTimeout
+	}
+
+	if cfg.MaxSegmentSize != "" {
+		maxSegmentSize, err := utils.ParseSize(cfg.MaxSegmentSize)
+		if err != nil {
+			return nil, fmt.Errorf("parsing max segment size: %w", err)
+		}
+		opts.MaxSegmentSize = maxSegmentSize
+	}
 
-	cancel()
+	return wal.NewWAL(logger, opts)
 }
@@ -1,7 +1,7 @@
 package cli
 
 import (
-	"kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/network/tcp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -7,7 +7,7 @@ import (
 
 	"github.com/spf13/viper"
 
-	"kdb/internal/utils"
+	"github.com/cat-go-dev/kdb/internal/utils"
 )
 
 type AppConfig struct {
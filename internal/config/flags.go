@@ -6,18 +6,22 @@ import (
 )
 
 const (
-	flagEngineType = "engine_type"
+	flagEngineType   = "engine_type"
+	flagEngineShards = "engine_shards"
 
 	flagHost           = "host"
 	flagPort           = "port"
 	flagMaxConnections = "max_connections"
 	flagMaxMessageSize = "max_message_size"
 	flagIdleTimeout    = "idle_timeout"
+	flagProtocol       = "protocol"
 
 	flagLogLevel     = "log_level"
 	flagLogOutputDir = "output_dir"
 )
 
+const shardedEngineType = "sharded_in_memory"
+
 func (a *AppConfig) overrideByFlags() {
 	a.overideEngine()
 	a.overideNetwork()
@@ -26,6 +30,7 @@ func (a *AppConfig) overrideByFlags() {
 
 func (a *AppConfig) overideEngine() {
 	pflag.String(flagEngineType, "", "engine type")
+	pflag.Int(flagEngineShards, 0, "engine shards number")
 
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
@@ -34,6 +39,25 @@ func (a *AppConfig) overideEngine() {
 	if engineType != "" {
 		a.Data.Engine.Type = engineType
 	}
+
+	shards := viper.GetInt(flagEngineShards)
+	if shards != 0 {
+		a.Data.Engine.setSetting(shardedEngineType, "shards", shards)
+	}
+}
+
+func (e *Engine) setSetting(engineType, key string, value any) {
+	if e.Settings == nil {
+		e.Settings = make(map[string]any)
+	}
+
+	section, ok := e.Settings[engineType].(map[string]any)
+	if !ok {
+		section = make(map[string]any)
+		e.Settings[engineType] = section
+	}
+
+	section[key] = value
 }
 
 func (a *AppConfig) overideNetwork() {
@@ -42,6 +66,7 @@ func (a *AppConfig) overideNetwork() {
 	pflag.Int(flagMaxConnections, 0, "max connections")
 	pflag.String(flagMaxMessageSize, "", "max message size")
 	pflag.String(flagIdleTimeout, "", "idle timeout")
+	pflag.String(flagProtocol, "", "wire protocol: auto, line, resp or framed")
 
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
@@ -70,6 +95,11 @@ func (a *AppConfig) overideNetwork() {
 	if idleTimeout != "" {
 		a.Data.Network.IdleTimeout = idleTimeout
 	}
+
+	protocol := viper.GetString(flagProtocol)
+	if protocol != "" {
+		a.Data.Network.Protocol = protocol
+	}
 }
 
 func (a *AppConfig) overideLogging() {
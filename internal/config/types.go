@@ -1,13 +1,29 @@
 package config
 
 type Config struct {
-	Engine  Engine  `mapstructure:"engine"`
-	Network Network `mapstructure:"network"`
-	Logging Logging `mapstructure:"logging"`
+	Engine   Engine    `mapstructure:"engine"`
+	WAL      *WAL      `mapstructure:"wal"`
+	Snapshot *Snapshot `mapstructure:"snapshot"`
+	Network  Network   `mapstructure:"network"`
+	Logging  Logging   `mapstructure:"logging"`
 }
 
 type Engine struct {
 	Type string `mapstructure:"type"`
+	// engine specific subsections keyed by the engine type
+	Settings map[string]any `mapstructure:",remain"`
+}
+
+type WAL struct {
+	FlushTimeout   string `mapstructure:"flush_timeout"`
+	MaxBatchSize   int    `mapstructure:"max_batch_size"`
+	MaxSegmentSize string `mapstructure:"max_segment_size"`
+	DataDirectory  string `mapstructure:"data_directory"`
+}
+
+type Snapshot struct {
+	Interval      string `mapstructure:"interval"`
+	DataDirectory string `mapstructure:"data_directory"`
 }
 
 type Network struct {
@@ -16,9 +32,11 @@ type Network struct {
 	MaxConnections int    `mapstructure:"max_connections"`
 	MaxMessageSize string `mapstructure:"max_message_size"`
 	IdleTimeout    string `mapstructure:"idle_timeout"`
+	// Protocol is auto, line, resp or framed
+	Protocol string `mapstructure:"protocol"`
 }
 
 type Logging struct {
-	Level  string `mapstructure:"level"`
+	Level     string `mapstructure:"level"`
 	OutputDir string `mapstructure:"output_dir"`
 }
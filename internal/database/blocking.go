@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// waiters tracks clients blocked on empty lists, a push to a key wakes all
+// clients waiting for it and they race for the new elements.
+type waiters struct {
+	mu   *sync.Mutex
+	keys map[string]map[chan struct{}]struct{}
+}
+
+func newWaiters() *waiters {
+	return &waiters{
+		mu:   &sync.Mutex{},
+		keys: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// add registers a waiter for all keys, the channel receives a signal after
+// a push to any of them.
+func (w *waiters) add(keys []compute.Argument) chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	for _, key := range keys {
+		chans, ok := w.keys[string(key)]
+		if !ok {
+			chans = make(map[chan struct{}]struct{})
+			w.keys[string(key)] = chans
+		}
+		chans[ch] = struct{}{}
+	}
+
+	return ch
+}
+
+func (w *waiters) remove(keys []compute.Argument, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, key := range keys {
+		chans := w.keys[string(key)]
+		delete(chans, ch)
+		if len(chans) == 0 {
+			delete(w.keys, string(key))
+		}
+	}
+}
+
+func (w *waiters) notify(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.keys[key] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// blockingPop pops from the first non-empty list of BLPOP and BRPOP. When
+// all lists are empty it waits for a push, the timeout or the end of ctx
+// without holding the database lock, a timeout gives a nil result.
+func (d Database) blockingPop(ctx context.Context, command *compute.Command) (*ports.Result, error) {
+	logAttrs := []any{
+		slog.String("component", "database"),
+		slog.String("method", "blockingPop"),
+		slog.Any("command", command),
+	}
+
+	var timeout <-chan time.Time
+	args, _ := command.Args.(compute.BlockingPopArgs)
+	if args.Timeout > 0 {
+		timer := time.NewTimer(args.Timeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	keys := command.Keys
+	for {
+		// registered before the attempt, so a push right after it is not missed
+		wake := d.waiters.add(keys)
+
+		d.mu.RLock()
+		result, err := d.execute(ctx, command)
+		d.mu.RUnlock()
+
+		if err != nil || result.Kind != ports.KindNil {
+			d.waiters.remove(keys, wake)
+			return result, err
+		}
+
+		ports.Blocking(ctx)
+		select {
+		case <-wake:
+			d.waiters.remove(keys, wake)
+		case <-timeout:
+			d.waiters.remove(keys, wake)
+			return ports.NilArray(), nil
+		case <-ctx.Done():
+			d.waiters.remove(keys, wake)
+
+			wErr := fmt.Errorf("waiting for list: %w", ctx.Err())
+			d.logger.InfoContext(ctx, wErr.Error(), logAttrs...)
+			return nil, wErr
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestBlockingPopReturnsAvailableElement(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	_, err := db.Execute(ctx, "RPUSH second a b")
+	require.NoError(t, err)
+
+	result, err := db.Execute(ctx, "BRPOP first second 0")
+	require.NoError(t, err)
+	assert.Equal(t, ports.BulkArray([]string{"second", "b"}), result)
+}
+
+func TestBlockingPopWaitsForPush(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	type reply struct {
+		rows []string
+		err  error
+	}
+	replies := make(chan reply)
+	go func() {
+		session := db.NewSession()
+		defer session.Close()
+
+		result, err := session.Execute(ctx, "BLPOP list 5")
+		if err != nil {
+			replies <- reply{err: err}
+			return
+		}
+		replies <- reply{rows: collectRows(result)}
+	}()
+
+	// the waiting client must not block other commands
+	require.Eventually(t, func() bool {
+		db.waiters.mu.Lock()
+		defer db.waiters.mu.Unlock()
+
+		return len(db.waiters.keys["list"]) == 1
+	}, time.Second, time.Millisecond)
+
+	result, err := db.Execute(ctx, "LPUSH list value")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Integer(1), result)
+
+	select {
+	case r := <-replies:
+		require.NoError(t, r.err)
+		assert.Equal(t, []string{"list", "value"}, r.rows)
+	case <-time.After(time.Second):
+		t.Fatal("blocked pop is not woken up by push")
+	}
+
+	result, err = db.Execute(ctx, "LLEN list")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Integer(0), result)
+	assert.Empty(t, db.waiters.keys)
+}
+
+func TestBlockingPopTimeout(t *testing.T) {
+	db := newTestDatabase(t)
+
+	start := time.Now()
+	result, err := db.Execute(context.Background(), "BLPOP list 0.05")
+	require.NoError(t, err)
+	assert.Equal(t, ports.NilArray(), result)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Empty(t, db.waiters.keys)
+}
+
+func TestBlockingPopReportsWaiting(t *testing.T) {
+	db := newTestDatabase(t)
+
+	waited := 0
+	ctx := ports.WithBlocking(context.Background(), func() { waited++ })
+
+	_, err := db.Execute(ctx, "RPUSH list a")
+	require.NoError(t, err)
+	_, err = db.Execute(ctx, "BLPOP list 0.01")
+	require.NoError(t, err)
+	assert.Equal(t, 0, waited)
+
+	_, err = db.Execute(ctx, "BLPOP list 0.01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, waited)
+}
+
+func TestBlockingPopCanceled(t *testing.T) {
+	db := newTestDatabase(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := db.Execute(ctx, "BLPOP list 0")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, db.waiters.keys)
+}
+
+func TestBlockingPopInsideMulti(t *testing.T) {
+	session := newTestDatabase(t).NewSession()
+	defer session.Close()
+
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "BLPOP list 0", ports.Simple(responseQueued))
+
+	result, err := session.Execute(context.Background(), "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.NilArray()), result)
+}
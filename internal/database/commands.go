@@ -0,0 +1,543 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Handler executes a parsed command, the caller holds the database lock.
+type Handler func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error)
+
+// Changes lists keys modified by a write judging by its result, so writes
+// which changed nothing do not abort transactions watching their keys.
+type Changes func(command *compute.Command, result *ports.Result) []compute.Argument
+
+// Command declares a command for Register, the spec tells how it is parsed
+// and the handler executes it.
+type Command struct {
+	compute.Spec
+	Handler Handler
+	// Changes of a write, by default it modifies all its keys
+	Changes Changes
+}
+
+// WithArgs adapts a handler of the arguments built by Parse of the spec,
+// other arguments fail the command.
+func WithArgs[A any](handler func(ctx context.Context, storage StorageLayer, command *compute.Command, args A) (*ports.Result, error)) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		args, ok := command.Args.(A)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", errInvalidArguments, command.Args)
+		}
+
+		return handler(ctx, storage, command, args)
+	}
+}
+
+// builtinHandlers execute commands registered by compute, transaction
+// commands are handled by sessions.
+var builtinHandlers = map[compute.CommandType]Handler{
+	compute.Get:           handleGet,
+	compute.Set:           WithArgs(handleSet),
+	compute.Del:           handleDel,
+	compute.Save:          handleSave,
+	compute.BgSave:        handleBgSave,
+	compute.Expire:        WithArgs(handleExpire),
+	compute.PExpire:       WithArgs(handleExpire),
+	compute.TTL:           handleTTL(false),
+	compute.PTTL:          handleTTL(true),
+	compute.Persist:       handlePersist,
+	compute.Range:         WithArgs(handleRange),
+	compute.Prefix:        WithArgs(handlePrefix),
+	compute.Scan:          WithArgs(handleScan),
+	compute.Keys:          WithArgs(handleKeys),
+	compute.Incr:          WithArgs(handleIncrBy),
+	compute.Decr:          WithArgs(handleIncrBy),
+	compute.IncrBy:        WithArgs(handleIncrBy),
+	compute.DecrBy:        WithArgs(handleIncrBy),
+	compute.IncrByFloat:   WithArgs(handleIncrByFloat),
+	compute.LPush:         handlePush(engine.Left),
+	compute.RPush:         handlePush(engine.Right),
+	compute.LPop:          handlePop(engine.Left),
+	compute.RPop:          handlePop(engine.Right),
+	compute.BLPop:         handleBlockingPop(engine.Left),
+	compute.BRPop:         handleBlockingPop(engine.Right),
+	compute.LRange:        WithArgs(handleListRange),
+	compute.LLen:          handleListLen,
+	compute.LIndex:        WithArgs(handleListIndex),
+	compute.LTrim:         WithArgs(handleListTrim),
+	compute.HSet:          WithArgs(handleHashSet),
+	compute.HGet:          WithArgs(handleHashGet),
+	compute.HMGet:         WithArgs(handleHashMultiGet),
+	compute.HGetAll:       handleHashGetAll(everyPair),
+	compute.HKeys:         handleHashGetAll(everyField),
+	compute.HVals:         handleHashGetAll(everyValue),
+	compute.HDel:          WithArgs(handleHashDel),
+	compute.HExists:       WithArgs(handleHashExists),
+	compute.HLen:          handleHashLen,
+	compute.HIncrBy:       WithArgs(handleHashIncrBy),
+	compute.SAdd:          WithArgs(handleSetAdd),
+	compute.SRem:          WithArgs(handleSetRemove),
+	compute.SMembers:      handleSetMembers,
+	compute.SIsMember:     WithArgs(handleSetIsMember),
+	compute.SCard:         handleSetCard,
+	compute.SInter:        handleSetCombine(engine.SetInter),
+	compute.SUnion:        handleSetCombine(engine.SetUnion),
+	compute.SDiff:         handleSetCombine(engine.SetDiff),
+	compute.ZAdd:          WithArgs(handleSortedSetAdd),
+	compute.ZRem:          WithArgs(handleSortedSetRemove),
+	compute.ZScore:        WithArgs(handleSortedSetScore),
+	compute.ZRank:         WithArgs(handleSortedSetRank),
+	compute.ZRange:        WithArgs(handleSortedSetRange),
+	compute.ZRangeByScore: WithArgs(handleSortedSetRangeByScore),
+	compute.ZIncrBy:       WithArgs(handleSortedSetIncrBy),
+}
+
+// builtinChanges are changes of writes which may modify nothing.
+var builtinChanges = map[compute.CommandType]Changes{
+	compute.Del:     countedChanges,
+	compute.Expire:  countedChanges,
+	compute.PExpire: countedChanges,
+	compute.Persist: countedChanges,
+	compute.LPop:    poppedChanges,
+	compute.RPop:    poppedChanges,
+	compute.BLPop:   blockingPopChanges,
+	compute.BRPop:   blockingPopChanges,
+	compute.HDel:    countedChanges,
+	compute.SAdd:    countedChanges,
+	compute.SRem:    countedChanges,
+	compute.ZRem:    countedChanges,
+}
+
+// countedChanges modify keys of writes replying with the number of changed
+// keys or elements, a partial DEL still touches all its keys.
+func countedChanges(command *compute.Command, result *ports.Result) []compute.Argument {
+	if result.Int == 0 {
+		return nil
+	}
+
+	return command.Keys
+}
+
+func poppedChanges(command *compute.Command, result *ports.Result) []compute.Argument {
+	if result.Kind == ports.KindNil {
+		return nil
+	}
+
+	return command.Keys
+}
+
+// blockingPopChanges modify the key the element was popped from, it is the
+// first element of the reply.
+func blockingPopChanges(_ *compute.Command, result *ports.Result) []compute.Argument {
+	if result.Kind == ports.KindNil {
+		return nil
+	}
+
+	return []compute.Argument{compute.Argument(result.Elems[0].Str)}
+}
+
+func handleGet(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	value, ok, err := storage.Get(ctx, string(command.Key()))
+	if err != nil || !ok {
+		return ports.Nil(), err
+	}
+
+	return ports.Bulk(value), nil
+}
+
+func handleSet(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.SetArgs) (*ports.Result, error) {
+	if args.TTL > 0 {
+		return ports.Simple(responseOK), storage.SetWithTTL(ctx, string(command.Key()), string(args.Value), args.TTL)
+	}
+
+	return ports.Simple(responseOK), storage.Set(ctx, string(command.Key()), string(args.Value))
+}
+
+func handleDel(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	removed, err := storage.Del(ctx, toStrings(command.Keys)...)
+
+	return ports.Integer(int64(removed)), err
+}
+
+func handleSave(ctx context.Context, storage StorageLayer, _ *compute.Command) (*ports.Result, error) {
+	return ports.Simple(responseOK), storage.Save(ctx)
+}
+
+func handleBgSave(ctx context.Context, storage StorageLayer, _ *compute.Command) (*ports.Result, error) {
+	return ports.Simple(responseBackgroundSaving), storage.BackgroundSave(ctx)
+}
+
+func handleExpire(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ExpireArgs) (*ports.Result, error) {
+	exists, err := storage.Expire(ctx, string(command.Key()), args.TTL)
+
+	return formatBool(exists), err
+}
+
+func handleTTL(inMilliseconds bool) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		ttl, exists, err := storage.TTL(ctx, string(command.Key()))
+
+		return formatTTL(ttl, exists, inMilliseconds), err
+	}
+}
+
+func handlePersist(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	persisted, err := storage.Persist(ctx, string(command.Key()))
+
+	return formatBool(persisted), err
+}
+
+func handleRange(ctx context.Context, storage StorageLayer, _ *compute.Command, args compute.RangeArgs) (*ports.Result, error) {
+	it, err := storage.Range(ctx, string(args.Start), string(args.End), args.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ports.Stream(newEntryRows(it)), nil
+}
+
+func handlePrefix(ctx context.Context, storage StorageLayer, _ *compute.Command, args compute.PrefixArgs) (*ports.Result, error) {
+	it, err := storage.Prefix(ctx, string(args.Prefix), args.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ports.Stream(newEntryRows(it)), nil
+}
+
+func handleScan(ctx context.Context, storage StorageLayer, _ *compute.Command, args compute.ScanArgs) (*ports.Result, error) {
+	keys, next, err := storage.Scan(ctx, string(args.Cursor), string(args.Pattern), args.Count)
+
+	// the first element is the cursor of the next call
+	return ports.Array(ports.Bulk(next), ports.BulkArray(keys)), err
+}
+
+func handleKeys(ctx context.Context, storage StorageLayer, _ *compute.Command, args compute.PatternArgs) (*ports.Result, error) {
+	keys, err := storage.Keys(ctx, string(args.Pattern))
+
+	return ports.BulkArray(keys), err
+}
+
+func handleIncrBy(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.IncrementArgs) (*ports.Result, error) {
+	value, err := storage.IncrBy(ctx, string(command.Key()), args.Increment)
+
+	return ports.Integer(value), err
+}
+
+func handleIncrByFloat(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.FloatIncrementArgs) (*ports.Result, error) {
+	value, err := storage.IncrByFloat(ctx, string(command.Key()), args.Increment)
+
+	return ports.Bulk(value), err
+}
+
+func handlePush(side engine.Side) Handler {
+	return WithArgs(func(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+		length, err := storage.Push(ctx, string(command.Key()), side, toStrings(args.Values)...)
+
+		return ports.Integer(int64(length)), err
+	})
+}
+
+func handlePop(side engine.Side) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		value, ok, err := storage.Pop(ctx, string(command.Key()), side)
+		if err != nil || !ok {
+			return ports.Nil(), err
+		}
+
+		return ports.Bulk(value), nil
+	}
+}
+
+// handleBlockingPop makes a single attempt, waiting for data is done by
+// blockingPop.
+func handleBlockingPop(side engine.Side) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		for _, key := range command.Keys {
+			value, ok, err := storage.Pop(ctx, string(key), side)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return ports.BulkArray([]string{string(key), value}), nil
+			}
+		}
+
+		return ports.NilArray(), nil
+	}
+}
+
+func handleListRange(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.IndexRangeArgs) (*ports.Result, error) {
+	values, err := storage.ListRange(ctx, string(command.Key()), args.Start, args.Stop)
+
+	return ports.BulkArray(values), err
+}
+
+func handleListLen(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	length, err := storage.ListLen(ctx, string(command.Key()))
+
+	return ports.Integer(int64(length)), err
+}
+
+func handleListIndex(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.IndexArgs) (*ports.Result, error) {
+	value, ok, err := storage.ListIndex(ctx, string(command.Key()), args.Index)
+	if err != nil || !ok {
+		return ports.Nil(), err
+	}
+
+	return ports.Bulk(value), nil
+}
+
+func handleListTrim(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.IndexRangeArgs) (*ports.Result, error) {
+	return ports.Simple(responseOK), storage.ListTrim(ctx, string(command.Key()), args.Start, args.Stop)
+}
+
+func handleHashSet(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	added, err := storage.HSet(ctx, string(command.Key()), toStrings(args.Values)...)
+
+	return ports.Integer(int64(added)), err
+}
+
+func handleHashGet(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.FieldsArgs) (*ports.Result, error) {
+	values, exists, err := storage.HGet(ctx, string(command.Key()), toStrings(args.Fields)...)
+	if err != nil || !exists[0] {
+		return ports.Nil(), err
+	}
+
+	return ports.Bulk(values[0]), nil
+}
+
+func handleHashMultiGet(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.FieldsArgs) (*ports.Result, error) {
+	values, exists, err := storage.HGet(ctx, string(command.Key()), toStrings(args.Fields)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// missing fields are nil elements
+	elems := make([]*ports.Result, 0, len(values))
+	for i, value := range values {
+		if !exists[i] {
+			elems = append(elems, ports.Nil())
+			continue
+		}
+		elems = append(elems, ports.Bulk(value))
+	}
+
+	return ports.Array(elems...), nil
+}
+
+// HGETALL lists pairs, HKEYS and HVALS pick fields or values of them.
+const (
+	everyPair = iota
+	everyField
+	everyValue
+)
+
+func handleHashGetAll(pick int) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		pairs, err := storage.HGetAll(ctx, string(command.Key()))
+		switch pick {
+		case everyField:
+			return ports.BulkArray(everyOther(pairs, 0)), err
+		case everyValue:
+			return ports.BulkArray(everyOther(pairs, 1)), err
+		}
+
+		return ports.Map(ports.BulkArray(pairs).Elems...), err
+	}
+}
+
+func handleHashDel(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.FieldsArgs) (*ports.Result, error) {
+	removed, err := storage.HDel(ctx, string(command.Key()), toStrings(args.Fields)...)
+
+	return ports.Integer(int64(removed)), err
+}
+
+func handleHashExists(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.FieldsArgs) (*ports.Result, error) {
+	exists, err := storage.HExists(ctx, string(command.Key()), string(args.Fields[0]))
+
+	return formatBool(exists), err
+}
+
+func handleHashLen(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	length, err := storage.HLen(ctx, string(command.Key()))
+
+	return ports.Integer(int64(length)), err
+}
+
+func handleHashIncrBy(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.HashIncrementArgs) (*ports.Result, error) {
+	value, err := storage.HIncrBy(ctx, string(command.Key()), string(args.Field), args.Increment)
+
+	return ports.Integer(value), err
+}
+
+func handleSetAdd(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	added, err := storage.SAdd(ctx, string(command.Key()), toStrings(args.Values)...)
+
+	return ports.Integer(int64(added)), err
+}
+
+func handleSetRemove(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	removed, err := storage.SRem(ctx, string(command.Key()), toStrings(args.Values)...)
+
+	return ports.Integer(int64(removed)), err
+}
+
+func handleSetMembers(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	members, err := storage.SMembers(ctx, string(command.Key()))
+
+	return ports.BulkArray(members), err
+}
+
+func handleSetIsMember(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	isMember, err := storage.SIsMember(ctx, string(command.Key()), string(args.Values[0]))
+
+	return formatBool(isMember), err
+}
+
+func handleSetCard(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+	card, err := storage.SCard(ctx, string(command.Key()))
+
+	return ports.Integer(int64(card)), err
+}
+
+func handleSetCombine(op engine.SetOp) Handler {
+	return func(ctx context.Context, storage StorageLayer, command *compute.Command) (*ports.Result, error) {
+		members, err := storage.SCombine(ctx, op, toStrings(command.Keys)...)
+
+		return ports.BulkArray(members), err
+	}
+}
+
+func handleSortedSetAdd(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ScoredMembersArgs) (*ports.Result, error) {
+	members := make([]engine.ScoredMember, 0, len(args.Members))
+	for i, member := range args.Members {
+		members = append(members, engine.ScoredMember{Member: string(member), Score: args.Scores[i]})
+	}
+
+	added, err := storage.ZAdd(ctx, string(command.Key()), members...)
+
+	return ports.Integer(int64(added)), err
+}
+
+func handleSortedSetRemove(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	removed, err := storage.ZRem(ctx, string(command.Key()), toStrings(args.Values)...)
+
+	return ports.Integer(int64(removed)), err
+}
+
+func handleSortedSetScore(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	score, exists, err := storage.ZScore(ctx, string(command.Key()), string(args.Values[0]))
+	if !exists {
+		return ports.Nil(), err
+	}
+
+	return ports.Bulk(formatScore(score)), nil
+}
+
+func handleSortedSetRank(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ValuesArgs) (*ports.Result, error) {
+	rank, exists, err := storage.ZRank(ctx, string(command.Key()), string(args.Values[0]))
+	if !exists {
+		return ports.Nil(), err
+	}
+
+	return ports.Integer(int64(rank)), nil
+}
+
+func handleSortedSetRange(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.RankRangeArgs) (*ports.Result, error) {
+	members, err := storage.ZRange(ctx, string(command.Key()), args.Start, args.Stop)
+
+	return ports.BulkArray(formatScored(members, args.WithScores)), err
+}
+
+func handleSortedSetRangeByScore(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ScoreRangeArgs) (*ports.Result, error) {
+	min := engine.ScoreBound(args.Min)
+	max := engine.ScoreBound(args.Max)
+
+	members, err := storage.ZRangeByScore(ctx, string(command.Key()), min, max, args.Offset, args.Limit)
+
+	return ports.BulkArray(formatScored(members, args.WithScores)), err
+}
+
+func handleSortedSetIncrBy(ctx context.Context, storage StorageLayer, command *compute.Command, args compute.ScoreIncrementArgs) (*ports.Result, error) {
+	score, err := storage.ZIncrBy(ctx, string(command.Key()), string(args.Member), args.Increment)
+
+	return ports.Bulk(formatScore(score)), err
+}
+
+func toStrings(args []compute.Argument) []string {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		values = append(values, string(arg))
+	}
+
+	return values
+}
+
+// everyOther picks fields (offset 0) or values (offset 1) of flat pairs.
+func everyOther(pairs []string, offset int) []string {
+	picked := make([]string, 0, len(pairs)/2)
+	for i := offset; i < len(pairs); i += 2 {
+		picked = append(picked, pairs[i])
+	}
+
+	return picked
+}
+
+// formatScored lists members, with scores each member is followed by its
+// score.
+func formatScored(members []engine.ScoredMember, withScores bool) []string {
+	rows := make([]string, 0, len(members))
+	for _, m := range members {
+		rows = append(rows, m.Member)
+		if withScores {
+			rows = append(rows, formatScore(m.Score))
+		}
+	}
+
+	return rows
+}
+
+// formatScore uses the shortest exact representation, infinities are
+// "inf" and "-inf" like in redis.
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(score, 'g', -1, 64)
+	}
+}
+
+func formatBool(value bool) *ports.Result {
+	if value {
+		return ports.Integer(1)
+	}
+
+	return ports.Integer(0)
+}
+
+// formatTTL follows the redis convention: -2 for a missing key and -1 for
+// a key without expiration.
+func formatTTL(ttl time.Duration, exists bool, inMilliseconds bool) *ports.Result {
+	switch {
+	case !exists:
+		return ports.Integer(-2)
+	case ttl < 0:
+		return ports.Integer(-1)
+	case inMilliseconds:
+		return ports.Integer(ttl.Milliseconds())
+	default:
+		return ports.Integer(int64((ttl + time.Second/2) / time.Second))
+	}
+}
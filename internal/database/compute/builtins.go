@@ -0,0 +1,462 @@
+package compute
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtins are registered by every Compute.
+var builtins = []Spec{
+	{Name: Get, Arity: 2, Keys: singleKey},
+	{Name: Set, Arity: -3, Write: true, Keys: singleKey, Parse: parseSet},
+	{Name: Del, Arity: -2, Write: true, Keys: everyKey},
+	{Name: Save, Arity: 1},
+	{Name: BgSave, Arity: 1},
+	{Name: Expire, Arity: 3, Write: true, Keys: singleKey, Parse: parseExpire(time.Second)},
+	{Name: PExpire, Arity: 3, Write: true, Keys: singleKey, Parse: parseExpire(time.Millisecond)},
+	{Name: TTL, Arity: 2, Keys: singleKey},
+	{Name: PTTL, Arity: 2, Keys: singleKey},
+	{Name: Persist, Arity: 2, Write: true, Keys: singleKey},
+	{Name: Range, Arity: -3, Parse: parseRange},
+	{Name: Prefix, Arity: -2, Parse: parsePrefix},
+	{Name: Scan, Arity: -2, Parse: parseScan},
+	{Name: Keys, Arity: 2, Parse: parsePattern},
+	{Name: Multi, Arity: 1},
+	{Name: Exec, Arity: 1},
+	{Name: Discard, Arity: 1},
+	{Name: Watch, Arity: -2, Keys: everyKey},
+	{Name: Unwatch, Arity: 1},
+	{Name: Begin, Arity: 2, Parse: parseBegin},
+	{Name: Commit, Arity: 1},
+	{Name: Rollback, Arity: 1},
+	{Name: Incr, Arity: 2, Write: true, Keys: singleKey, Parse: fixedIncrement(1)},
+	{Name: Decr, Arity: 2, Write: true, Keys: singleKey, Parse: fixedIncrement(-1)},
+	{Name: IncrBy, Arity: 3, Write: true, Keys: singleKey, Parse: parseIncrement(false)},
+	{Name: DecrBy, Arity: 3, Write: true, Keys: singleKey, Parse: parseIncrement(true)},
+	{Name: IncrByFloat, Arity: 3, Write: true, Keys: singleKey, Parse: parseFloatIncrement},
+	{Name: LPush, Arity: -3, Write: true, Keys: singleKey, Parse: parseValues},
+	{Name: RPush, Arity: -3, Write: true, Keys: singleKey, Parse: parseValues},
+	{Name: LPop, Arity: 2, Write: true, Keys: singleKey},
+	{Name: RPop, Arity: 2, Write: true, Keys: singleKey},
+	{Name: LRange, Arity: 4, Keys: singleKey, Parse: parseIndexRange},
+	{Name: LLen, Arity: 2, Keys: singleKey},
+	{Name: LIndex, Arity: 3, Keys: singleKey, Parse: parseIndex},
+	{Name: LTrim, Arity: 4, Write: true, Keys: singleKey, Parse: parseIndexRange},
+	{Name: BLPop, Arity: -3, Write: true, Keys: blockingKeys, Parse: parseBlockingPop},
+	{Name: BRPop, Arity: -3, Write: true, Keys: blockingKeys, Parse: parseBlockingPop},
+	{Name: HSet, Arity: -4, Write: true, Keys: singleKey, Parse: parseHashPairs},
+	{Name: HGet, Arity: 3, Keys: singleKey, Parse: parseFields},
+	{Name: HMGet, Arity: -3, Keys: singleKey, Parse: parseFields},
+	{Name: HGetAll, Arity: 2, Keys: singleKey},
+	{Name: HDel, Arity: -3, Write: true, Keys: singleKey, Parse: parseFields},
+	{Name: HExists, Arity: 3, Keys: singleKey, Parse: parseFields},
+	{Name: HLen, Arity: 2, Keys: singleKey},
+	{Name: HKeys, Arity: 2, Keys: singleKey},
+	{Name: HVals, Arity: 2, Keys: singleKey},
+	{Name: HIncrBy, Arity: 4, Write: true, Keys: singleKey, Parse: parseHashIncrement},
+	{Name: SAdd, Arity: -3, Write: true, Keys: singleKey, Parse: parseValues},
+	{Name: SRem, Arity: -3, Write: true, Keys: singleKey, Parse: parseValues},
+	{Name: SMembers, Arity: 2, Keys: singleKey},
+	{Name: SIsMember, Arity: 3, Keys: singleKey, Parse: parseValues},
+	{Name: SCard, Arity: 2, Keys: singleKey},
+	{Name: SInter, Arity: -2, Keys: everyKey},
+	{Name: SUnion, Arity: -2, Keys: everyKey},
+	{Name: SDiff, Arity: -2, Keys: everyKey},
+	{Name: ZAdd, Arity: -4, Write: true, Keys: singleKey, Parse: parseScoredMembers},
+	{Name: ZRem, Arity: -3, Write: true, Keys: singleKey, Parse: parseValues},
+	{Name: ZScore, Arity: 3, Keys: singleKey, Parse: parseValues},
+	{Name: ZRank, Arity: 3, Keys: singleKey, Parse: parseValues},
+	{Name: ZRange, Arity: -4, Keys: singleKey, Parse: parseRankRange},
+	{Name: ZRangeByScore, Arity: -4, Keys: singleKey, Parse: parseScoreRange},
+	{Name: ZIncrBy, Arity: 4, Write: true, Keys: singleKey, Parse: parseScoreIncrement},
+}
+
+var (
+	singleKey = KeyRange{First: 1, Last: 1}
+	everyKey  = KeyRange{First: 1, Last: -1}
+	// blockingKeys are followed by the timeout
+	blockingKeys = KeyRange{First: 1, Last: -2}
+)
+
+const (
+	optionEX = "EX"
+	optionPX = "PX"
+)
+
+// parseSet parses "SET key value [EX seconds|PX milliseconds]".
+func parseSet(tokens []string) (any, error) {
+	args := SetArgs{Value: Argument(tokens[2])}
+	if len(tokens) == 3 {
+		return args, nil
+	}
+	if len(tokens) != 5 {
+		return nil, errSyntax
+	}
+
+	var unit time.Duration
+	switch strings.ToUpper(tokens[3]) {
+	case optionEX:
+		unit = time.Second
+	case optionPX:
+		unit = time.Millisecond
+	default:
+		return nil, errSyntax
+	}
+
+	amount, err := strconv.ParseInt(tokens[4], 10, 64)
+	if err != nil || amount <= 0 || amount > int64(math.MaxInt64/unit) {
+		return nil, errInvalidExpireTime
+	}
+	args.TTL = time.Duration(amount) * unit
+
+	return args, nil
+}
+
+// parseExpire parses "EXPIRE key seconds" and "PEXPIRE key milliseconds".
+func parseExpire(unit time.Duration) func(tokens []string) (any, error) {
+	return func(tokens []string) (any, error) {
+		amount, err := strconv.ParseInt(tokens[2], 10, 64)
+		if err != nil || amount > int64(math.MaxInt64/unit) || amount < int64(math.MinInt64/unit) {
+			return nil, errInvalidExpireTime
+		}
+
+		return ExpireArgs{TTL: time.Duration(amount) * unit}, nil
+	}
+}
+
+const optionLimit = "LIMIT"
+
+// parseRange parses "RANGE start end [LIMIT n]".
+func parseRange(tokens []string) (any, error) {
+	limit, err := parseLimit(tokens, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return RangeArgs{Start: Argument(tokens[1]), End: Argument(tokens[2]), Limit: limit}, nil
+}
+
+// parsePrefix parses "PREFIX prefix [LIMIT n]".
+func parsePrefix(tokens []string) (any, error) {
+	limit, err := parseLimit(tokens, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return PrefixArgs{Prefix: Argument(tokens[1]), Limit: limit}, nil
+}
+
+// parseLimit parses the "[LIMIT n]" option, argsNum is the number of tokens
+// before it.
+func parseLimit(tokens []string, argsNum int) (int, error) {
+	switch len(tokens) {
+	case argsNum:
+		return 0, nil
+	case argsNum + 2:
+	default:
+		return 0, errWrongArgumentsNumber
+	}
+
+	if !strings.EqualFold(tokens[argsNum], optionLimit) {
+		return 0, errSyntax
+	}
+
+	limit, err := strconv.Atoi(tokens[argsNum+1])
+	if err != nil || limit <= 0 {
+		return 0, errInvalidLimit
+	}
+
+	return limit, nil
+}
+
+const (
+	optionMatch = "MATCH"
+	optionCount = "COUNT"
+
+	defaultScanCount = 10
+)
+
+// parseScan parses "SCAN cursor [MATCH pattern] [COUNT n]" with options in
+// any order.
+func parseScan(tokens []string) (any, error) {
+	if len(tokens)%2 != 0 {
+		return nil, errWrongArgumentsNumber
+	}
+
+	args := ScanArgs{Cursor: Argument(tokens[1]), Count: defaultScanCount}
+	for i := 2; i < len(tokens); i += 2 {
+		switch strings.ToUpper(tokens[i]) {
+		case optionMatch:
+			args.Pattern = Argument(tokens[i+1])
+		case optionCount:
+			count, err := strconv.Atoi(tokens[i+1])
+			if err != nil || count <= 0 {
+				return nil, errInvalidCount
+			}
+			args.Count = count
+		default:
+			return nil, errSyntax
+		}
+	}
+
+	return args, nil
+}
+
+// parsePattern parses "KEYS pattern".
+func parsePattern(tokens []string) (any, error) {
+	return PatternArgs{Pattern: Argument(tokens[1])}, nil
+}
+
+const optionReadOnly = "READONLY"
+
+// parseBegin parses "BEGIN READONLY", only read-only transactions are
+// supported.
+func parseBegin(tokens []string) (any, error) {
+	if !strings.EqualFold(tokens[1], optionReadOnly) {
+		return nil, errSyntax
+	}
+
+	return nil, nil
+}
+
+// fixedIncrement parses "INCR key" and "DECR key".
+func fixedIncrement(delta int64) func(tokens []string) (any, error) {
+	return func(_ []string) (any, error) {
+		return IncrementArgs{Increment: delta}, nil
+	}
+}
+
+// parseIncrement parses "INCRBY key n" and "DECRBY key n", decrements are
+// negated.
+func parseIncrement(negate bool) func(tokens []string) (any, error) {
+	return func(tokens []string) (any, error) {
+		increment, err := strconv.ParseInt(tokens[2], 10, 64)
+		if err != nil {
+			return nil, errInvalidIncrement
+		}
+
+		if negate {
+			if increment == math.MinInt64 {
+				return nil, errInvalidIncrement
+			}
+			increment = -increment
+		}
+
+		return IncrementArgs{Increment: increment}, nil
+	}
+}
+
+// parseFloatIncrement parses "INCRBYFLOAT key f".
+func parseFloatIncrement(tokens []string) (any, error) {
+	increment, err := strconv.ParseFloat(tokens[2], 64)
+	if err != nil || math.IsNaN(increment) || math.IsInf(increment, 0) {
+		return nil, errInvalidFloat
+	}
+
+	return FloatIncrementArgs{Increment: increment}, nil
+}
+
+// parseValues parses values or members after the key like
+// "LPUSH key value..." and "SISMEMBER key member".
+func parseValues(tokens []string) (any, error) {
+	return ValuesArgs{Values: toArguments(tokens[2:])}, nil
+}
+
+// parseIndex parses "LINDEX key index".
+func parseIndex(tokens []string) (any, error) {
+	index, err := strconv.Atoi(tokens[2])
+	if err != nil {
+		return nil, errInvalidIndex
+	}
+
+	return IndexArgs{Index: index}, nil
+}
+
+// parseIndexRange parses "LRANGE key start stop" and "LTRIM key start stop".
+func parseIndexRange(tokens []string) (any, error) {
+	start, stop, err := parseStartStop(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return IndexRangeArgs{Start: start, Stop: stop}, nil
+}
+
+func parseStartStop(tokens []string) (int, int, error) {
+	start, err := strconv.Atoi(tokens[2])
+	if err != nil {
+		return 0, 0, errInvalidIndex
+	}
+	stop, err := strconv.Atoi(tokens[3])
+	if err != nil {
+		return 0, 0, errInvalidIndex
+	}
+
+	return start, stop, nil
+}
+
+// parseBlockingPop parses "BLPOP key... timeout" and "BRPOP key... timeout".
+func parseBlockingPop(tokens []string) (any, error) {
+	seconds, err := strconv.ParseFloat(tokens[len(tokens)-1], 64)
+	if err != nil || seconds < 0 || math.IsNaN(seconds) || seconds > float64(math.MaxInt64/time.Second) {
+		return nil, errInvalidTimeout
+	}
+
+	return BlockingPopArgs{Timeout: time.Duration(seconds * float64(time.Second))}, nil
+}
+
+// parseHashPairs parses "HSET key field value [field value...]".
+func parseHashPairs(tokens []string) (any, error) {
+	if len(tokens)%2 != 0 {
+		return nil, errWrongArgumentsNumber
+	}
+
+	return ValuesArgs{Values: toArguments(tokens[2:])}, nil
+}
+
+// parseFields parses "HGET key field", "HEXISTS key field", "HMGET key
+// field..." and "HDEL key field...".
+func parseFields(tokens []string) (any, error) {
+	return FieldsArgs{Fields: toArguments(tokens[2:])}, nil
+}
+
+// parseHashIncrement parses "HINCRBY key field n".
+func parseHashIncrement(tokens []string) (any, error) {
+	increment, err := strconv.ParseInt(tokens[3], 10, 64)
+	if err != nil {
+		return nil, errInvalidIncrement
+	}
+
+	return HashIncrementArgs{Field: Argument(tokens[2]), Increment: increment}, nil
+}
+
+// parseScoredMembers parses "ZADD key score member [score member...]".
+func parseScoredMembers(tokens []string) (any, error) {
+	if len(tokens)%2 != 0 {
+		return nil, errWrongArgumentsNumber
+	}
+
+	args := ScoredMembersArgs{}
+	for i := 2; i < len(tokens); i += 2 {
+		score, err := parseScore(tokens[i])
+		if err != nil {
+			return nil, err
+		}
+
+		args.Scores = append(args.Scores, score)
+		args.Members = append(args.Members, Argument(tokens[i+1]))
+	}
+
+	return args, nil
+}
+
+// parseScoreIncrement parses "ZINCRBY key increment member".
+func parseScoreIncrement(tokens []string) (any, error) {
+	increment, err := parseScore(tokens[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return ScoreIncrementArgs{Member: Argument(tokens[3]), Increment: increment}, nil
+}
+
+const optionWithScores = "WITHSCORES"
+
+// parseRankRange parses "ZRANGE key start stop [WITHSCORES]".
+func parseRankRange(tokens []string) (any, error) {
+	args := RankRangeArgs{}
+	switch {
+	case len(tokens) == 5 && strings.EqualFold(tokens[4], optionWithScores):
+		args.WithScores = true
+	case len(tokens) == 5:
+		return nil, errSyntax
+	case len(tokens) != 4:
+		return nil, errWrongArgumentsNumber
+	}
+
+	var err error
+	args.Start, args.Stop, err = parseStartStop(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return args, nil
+}
+
+// parseScoreRange parses "ZRANGEBYSCORE key min max [WITHSCORES]
+// [LIMIT offset count]".
+func parseScoreRange(tokens []string) (any, error) {
+	args := ScoreRangeArgs{Limit: -1}
+
+	var err error
+	args.Min, err = parseScoreBound(tokens[2])
+	if err != nil {
+		return nil, err
+	}
+	args.Max, err = parseScoreBound(tokens[3])
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 4; i < len(tokens); i++ {
+		switch strings.ToUpper(tokens[i]) {
+		case optionWithScores:
+			args.WithScores = true
+		case optionLimit:
+			if i+2 >= len(tokens) {
+				return nil, errSyntax
+			}
+
+			offset, err := strconv.Atoi(tokens[i+1])
+			if err != nil || offset < 0 {
+				return nil, errInvalidLimit
+			}
+			count, err := strconv.Atoi(tokens[i+2])
+			if err != nil {
+				return nil, errInvalidLimit
+			}
+
+			// a negative count returns all members like a missing limit
+			args.Offset, args.Limit = offset, max(count, -1)
+			i += 2
+		default:
+			return nil, errSyntax
+		}
+	}
+
+	return args, nil
+}
+
+func parseScore(token string) (float64, error) {
+	score, err := strconv.ParseFloat(token, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, errInvalidScore
+	}
+
+	return score, nil
+}
+
+// parseScoreBound parses scores with an optional "(" prefix.
+func parseScoreBound(token string) (ScoreBound, error) {
+	bound := ScoreBound{}
+	if strings.HasPrefix(token, "(") {
+		bound.Exclusive = true
+		token = token[1:]
+	}
+
+	var err error
+	bound.Value, err = parseScore(token)
+
+	return bound, err
+}
+
+func toArguments(tokens []string) []Argument {
+	arguments := make([]Argument, 0, len(tokens))
+	for _, token := range tokens {
+		arguments = append(arguments, Argument(token))
+	}
+
+	return arguments
+}
@@ -1,17 +1,90 @@
 package compute
 
+import "time"
+
 type Command struct {
-	Type      CommandType
-	Arguments Arguments
+	Type CommandType
+	// Keys are picked by the key range of the spec
+	Keys []Argument
+	// Args are built by Parse of the spec, their type belongs to the command
+	Args any
+}
+
+// Key is the first key of the command, empty for commands without keys.
+func (c Command) Key() Argument {
+	if len(c.Keys) == 0 {
+		return ""
+	}
+
+	return c.Keys[0]
 }
 
 type CommandType string
 
 const (
-	Get     CommandType = "GET"
-	Set     CommandType = "SET"
-	Del     CommandType = "DEL"
-	Unknown CommandType = "unknown"
+	Get           CommandType = "GET"
+	Set           CommandType = "SET"
+	Del           CommandType = "DEL"
+	Save          CommandType = "SAVE"
+	BgSave        CommandType = "BGSAVE"
+	Expire        CommandType = "EXPIRE"
+	PExpire       CommandType = "PEXPIRE"
+	TTL           CommandType = "TTL"
+	PTTL          CommandType = "PTTL"
+	Persist       CommandType = "PERSIST"
+	Range         CommandType = "RANGE"
+	Prefix        CommandType = "PREFIX"
+	Scan          CommandType = "SCAN"
+	Keys          CommandType = "KEYS"
+	Multi         CommandType = "MULTI"
+	Exec          CommandType = "EXEC"
+	Discard       CommandType = "DISCARD"
+	Watch         CommandType = "WATCH"
+	Unwatch       CommandType = "UNWATCH"
+	Begin         CommandType = "BEGIN"
+	Commit        CommandType = "COMMIT"
+	Rollback      CommandType = "ROLLBACK"
+	Incr          CommandType = "INCR"
+	Decr          CommandType = "DECR"
+	IncrBy        CommandType = "INCRBY"
+	DecrBy        CommandType = "DECRBY"
+	IncrByFloat   CommandType = "INCRBYFLOAT"
+	LPush         CommandType = "LPUSH"
+	RPush         CommandType = "RPUSH"
+	LPop          CommandType = "LPOP"
+	RPop          CommandType = "RPOP"
+	LRange        CommandType = "LRANGE"
+	LLen          CommandType = "LLEN"
+	LIndex        CommandType = "LINDEX"
+	LTrim         CommandType = "LTRIM"
+	BLPop         CommandType = "BLPOP"
+	BRPop         CommandType = "BRPOP"
+	HSet          CommandType = "HSET"
+	HGet          CommandType = "HGET"
+	HMGet         CommandType = "HMGET"
+	HGetAll       CommandType = "HGETALL"
+	HDel          CommandType = "HDEL"
+	HExists       CommandType = "HEXISTS"
+	HLen          CommandType = "HLEN"
+	HKeys         CommandType = "HKEYS"
+	HVals         CommandType = "HVALS"
+	HIncrBy       CommandType = "HINCRBY"
+	SAdd          CommandType = "SADD"
+	SRem          CommandType = "SREM"
+	SMembers      CommandType = "SMEMBERS"
+	SIsMember     CommandType = "SISMEMBER"
+	SCard         CommandType = "SCARD"
+	SInter        CommandType = "SINTER"
+	SUnion        CommandType = "SUNION"
+	SDiff         CommandType = "SDIFF"
+	ZAdd          CommandType = "ZADD"
+	ZRem          CommandType = "ZREM"
+	ZScore        CommandType = "ZSCORE"
+	ZRank         CommandType = "ZRANK"
+	ZRange        CommandType = "ZRANGE"
+	ZRangeByScore CommandType = "ZRANGEBYSCORE"
+	ZIncrBy       CommandType = "ZINCRBY"
+	Unknown       CommandType = "unknown"
 )
 
 func (c CommandType) IsGet() bool {
@@ -26,9 +99,177 @@ func (c CommandType) IsDel() bool {
 	return c == Del
 }
 
-type Arguments struct {
-	Key   Argument
+func (c CommandType) IsRange() bool {
+	return c == Range
+}
+
+func (c CommandType) IsPrefix() bool {
+	return c == Prefix
+}
+
+func (c CommandType) IsMulti() bool {
+	return c == Multi
+}
+
+func (c CommandType) IsExec() bool {
+	return c == Exec
+}
+
+func (c CommandType) IsDiscard() bool {
+	return c == Discard
+}
+
+func (c CommandType) IsWatch() bool {
+	return c == Watch
+}
+
+func (c CommandType) IsUnwatch() bool {
+	return c == Unwatch
+}
+
+func (c CommandType) IsBegin() bool {
+	return c == Begin
+}
+
+func (c CommandType) IsCommit() bool {
+	return c == Commit
+}
+
+func (c CommandType) IsRollback() bool {
+	return c == Rollback
+}
+
+// IsPush reports whether the command pushes to a list.
+func (c CommandType) IsPush() bool {
+	return c == LPush || c == RPush
+}
+
+// IsBlockingPop reports whether the command waits for data in empty lists.
+func (c CommandType) IsBlockingPop() bool {
+	return c == BLPop || c == BRPop
+}
+
+// IsTransaction reports whether the command controls a transaction instead
+// of accessing data.
+func (c CommandType) IsTransaction() bool {
+	return c.IsMulti() || c.IsExec() || c.IsDiscard() || c.IsWatch() || c.IsUnwatch() ||
+		c.IsBegin() || c.IsCommit() || c.IsRollback()
+}
+
+// SetArgs are parsed by SET, a zero TTL keeps the key forever.
+type SetArgs struct {
 	Value Argument
+	TTL   time.Duration
+}
+
+// ExpireArgs are parsed by EXPIRE and PEXPIRE.
+type ExpireArgs struct {
+	TTL time.Duration
+}
+
+// RangeArgs are parsed by RANGE, a zero Limit means all entries.
+type RangeArgs struct {
+	Start Argument
+	End   Argument
+	Limit int
+}
+
+// PrefixArgs are parsed by PREFIX, a zero Limit means all entries.
+type PrefixArgs struct {
+	Prefix Argument
+	Limit  int
+}
+
+// ScanArgs are parsed by SCAN, Count is a hint of the page size.
+type ScanArgs struct {
+	Cursor  Argument
+	Pattern Argument
+	Count   int
+}
+
+// PatternArgs are parsed by KEYS.
+type PatternArgs struct {
+	Pattern Argument
+}
+
+// IncrementArgs are the signed delta of INCR, DECR, INCRBY and DECRBY.
+type IncrementArgs struct {
+	Increment int64
+}
+
+// FloatIncrementArgs are parsed by INCRBYFLOAT.
+type FloatIncrementArgs struct {
+	Increment float64
+}
+
+// ValuesArgs list values after the key: values of LPUSH and RPUSH, field
+// value pairs of HSET and members of set and sorted set commands.
+type ValuesArgs struct {
+	Values []Argument
+}
+
+// IndexArgs are parsed by LINDEX.
+type IndexArgs struct {
+	Index int
+}
+
+// IndexRangeArgs are inclusive indexes of LRANGE and LTRIM.
+type IndexRangeArgs struct {
+	Start int
+	Stop  int
+}
+
+// BlockingPopArgs are parsed by BLPOP and BRPOP, zero blocks forever.
+type BlockingPopArgs struct {
+	Timeout time.Duration
+}
+
+// FieldsArgs list the fields of HGET, HMGET, HDEL and HEXISTS.
+type FieldsArgs struct {
+	Fields []Argument
+}
+
+// HashIncrementArgs are parsed by HINCRBY.
+type HashIncrementArgs struct {
+	Field     Argument
+	Increment int64
+}
+
+// ScoredMembersArgs are parsed by ZADD, Scores are in the same order as
+// Members.
+type ScoredMembersArgs struct {
+	Members []Argument
+	Scores  []float64
+}
+
+// ScoreIncrementArgs are parsed by ZINCRBY.
+type ScoreIncrementArgs struct {
+	Member    Argument
+	Increment float64
+}
+
+// RankRangeArgs are parsed by ZRANGE.
+type RankRangeArgs struct {
+	Start      int
+	Stop       int
+	WithScores bool
+}
+
+// ScoreRangeArgs are parsed by ZRANGEBYSCORE, Offset and Limit come from
+// its LIMIT option and a negative Limit means all members.
+type ScoreRangeArgs struct {
+	Min        ScoreBound
+	Max        ScoreBound
+	Offset     int
+	Limit      int
+	WithScores bool
+}
+
+// ScoreBound is a score limit, "(" makes it exclusive and "-inf", "+inf"
+// make the range open.
+type ScoreBound struct {
+	Value     float64
+	Exclusive bool
 }
 
 type Argument string
@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 )
 
 type Compute struct {
-	logger *slog.Logger
+	logger   *slog.Logger
+	registry *Registry
 }
 
 func NewCompute(logger *slog.Logger) (*Compute, error) {
@@ -16,11 +16,29 @@ func NewCompute(logger *slog.Logger) (*Compute, error) {
 		return nil, errInvalidLogger
 	}
 
+	registry := NewRegistry()
+	for _, spec := range builtins {
+		if err := registry.Register(spec); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Compute{
-		logger: logger,
+		logger:   logger,
+		registry: registry,
 	}, nil
 }
 
+// Register adds a command to the parser.
+func (c Compute) Register(spec Spec) error {
+	return c.registry.Register(spec)
+}
+
+// Lookup finds the spec of a parsed command.
+func (c Compute) Lookup(commandType CommandType) (Spec, bool) {
+	return c.registry.Lookup(string(commandType))
+}
+
 const minTokensNum = 2
 
 func (c Compute) Parse(ctx context.Context, query string) (*Command, error) {
@@ -29,58 +47,39 @@ func (c Compute) Parse(ctx context.Context, query string) (*Command, error) {
 		slog.String("method", "Parse"),
 	}
 
-	tokens := strings.Split(query, " ")
-
-	if len(tokens) < minTokensNum {
-		err := errNotEnoughArguments
-		c.logger.InfoContext(ctx, err.Error(), logAttrs...)
+	tokens, err := tokenize(query)
+	if err != nil {
+		c.logger.InfoContext(ctx, fmt.Errorf("tokenize query: %w", err).Error(), logAttrs...)
 		return nil, err
 	}
+	if len(tokens) == 0 {
+		c.logger.InfoContext(ctx, errUnknownCommandType.Error(), logAttrs...)
+		return nil, errUnknownCommandType
+	}
 
-	commandType, err := c.getCommandType(tokens[0])
-	if err != nil {
-		c.logger.InfoContext(ctx, fmt.Errorf("get command type: %w", err).Error(), logAttrs...)
-		return nil, err
+	spec, ok := c.registry.Lookup(tokens[0])
+	if !ok {
+		c.logger.InfoContext(ctx, fmt.Errorf("get command type: %w", errUnknownCommandType).Error(), logAttrs...)
+		return nil, errUnknownCommandType
 	}
 
-	arguments, err := c.getArguments(tokens)
+	err = spec.checkArity(len(tokens))
 	if err != nil {
 		c.logger.InfoContext(ctx, err.Error(), logAttrs...)
 		return nil, err
 	}
 
-	return &Command{
-		Type:      commandType,
-		Arguments: arguments,
-	}, nil
-}
-
-func (c Compute) getCommandType(command string) (CommandType, error) {
-	switch command {
-	case "GET":
-		return Get, nil
-	case "SET":
-		return Set, nil
-	case "DEL":
-		return Del, nil
-	default:
-		return Unknown, errUnknownCommandType
-	}
-}
-
-func (c Compute) getArguments(tokens []string) (Arguments, error) {
-	if len(tokens) < minTokensNum {
-		return Arguments{}, errNotEnoughArguments
-	}
-
-	keyArg := tokens[1]
-	arguments := Arguments{
-		Key: Argument(keyArg),
+	command := &Command{
+		Type: spec.Name,
+		Keys: spec.Keys.pick(tokens),
 	}
-	if len(tokens) > minTokensNum {
-		valArg := tokens[2]
-		arguments.Value = Argument(valArg)
+	if spec.Parse != nil {
+		command.Args, err = spec.Parse(tokens)
+		if err != nil {
+			c.logger.InfoContext(ctx, err.Error(), logAttrs...)
+			return nil, err
+		}
 	}
 
-	return arguments, nil
+	return command, nil
 }
@@ -4,93 +4,45 @@ import (
 	"bytes"
 	"context"
 	"log/slog"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGetCommandType(t *testing.T) {
-	tests := []struct {
-		name     string
-		command  string
-		expected CommandType
-	}{
-		{name: "should be get command type", command: "GET", expected: Get},
-		{name: "should be set command type", command: "SET", expected: Set},
-		{name: "should be del command type", command: "DEL", expected: Del},
-	}
-
-	buf := new(bytes.Buffer)
-	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			commandType, _ := compute.getCommandType(tt.command)
-			assert.Equal(t, tt.expected, commandType)
-		})
-	}
-}
-
-func TestGetArgumentsSuccess(t *testing.T) {
-	tests := []struct {
-		name     string
-		tokens   []string
-		expected Arguments
-	}{
-		{name: "should be only key argument", tokens: []string{"GET", "test"}, expected: Arguments{Key: "test"}},
-		{name: "should be key and value argument", tokens: []string{"GET", "test", "true"}, expected: Arguments{Key: "test", Value: "true"}},
-	}
+func TestParseGetCommand(t *testing.T) {
+	ctx := context.Background()
 
 	buf := new(bytes.Buffer)
 	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			actual, err := compute.getArguments(tt.tokens)
-
-			assert.Nil(t, err)
-			assert.Equal(t, tt.expected, actual)
-		})
+	expected := &Command{
+		Type: Get,
+		Keys: []Argument{"test"},
 	}
-}
 
-func TestGetArgumentsNotEnoghtArguments(t *testing.T) {
-	tests := []struct {
-		name     string
-		tokens   []string
-		expected error
-	}{
-		{name: "1 argument error", tokens: []string{"GET"}, expected: errNotEnoughArguments},
-		{name: "0 argument error", tokens: []string{}, expected: errNotEnoughArguments},
-		{name: "2 argument without error", tokens: []string{"GET", "test"}, expected: nil},
-	}
+	commandStr := "GET test"
 
-	buf := new(bytes.Buffer)
-	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+	actual, err := compute.Parse(ctx, commandStr)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := compute.getArguments(tt.tokens)
-			assert.Equal(t, tt.expected, err)
-		})
-	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
 }
 
-func TestParseGetCommand(t *testing.T) {
+func TestParseSetCommand(t *testing.T) {
 	ctx := context.Background()
 
 	buf := new(bytes.Buffer)
 	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
 
-	commandType, _ := compute.getCommandType("GET")
 	expected := &Command{
-		Type: commandType,
-		Arguments: Arguments{
-			Key: Argument("test"),
-		},
+		Type: Set,
+		Keys: []Argument{"test"},
+		Args: SetArgs{Value: "true"},
 	}
 
-	commandStr := "GET test"
+	commandStr := "SET test true"
 
 	actual, err := compute.Parse(ctx, commandStr)
 
@@ -98,27 +50,40 @@ func TestParseGetCommand(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
-func TestParseSetCommand(t *testing.T) {
+func TestParseQuotedArguments(t *testing.T) {
 	ctx := context.Background()
 
 	buf := new(bytes.Buffer)
 	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
 
-	commandType, _ := compute.getCommandType("SET")
-	expected := &Command{
-		Type: commandType,
-		Arguments: Arguments{
-			Key:   Argument("test"),
-			Value: Argument("true"),
-		},
-	}
+	actual, err := compute.Parse(ctx, `SET "my key" "hello\x00 world" EX 10`)
+	assert.NoError(t, err)
+	assert.Equal(t, Argument("my key"), actual.Key())
+	assert.Equal(t, SetArgs{Value: "hello\x00 world", TTL: 10 * time.Second}, actual.Args)
 
-	commandStr := "SET test true"
+	_, err = compute.Parse(ctx, `SET key "value`)
+	assert.ErrorIs(t, err, errSyntax)
 
-	actual, err := compute.Parse(ctx, commandStr)
+	_, err = compute.Parse(ctx, "")
+	assert.ErrorIs(t, err, errUnknownCommandType)
+}
 
-	assert.Nil(t, err)
-	assert.Equal(t, expected, actual)
+func TestParseCaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	actual, err := compute.Parse(ctx, "set Key Value ex 10")
+	assert.NoError(t, err)
+	assert.Equal(t, Set, actual.Type)
+	assert.Equal(t, Argument("Key"), actual.Key())
+	assert.Equal(t, SetArgs{Value: "Value", TTL: 10 * time.Second}, actual.Args)
+
+	actual, err = compute.Parse(ctx, "zRangeByScore board -inf +inf withscores limit 0 1")
+	assert.NoError(t, err)
+	assert.Equal(t, ZRangeByScore, actual.Type)
+	assert.True(t, actual.Args.(ScoreRangeArgs).WithScores)
 }
 
 func TestParseDelCommand(t *testing.T) {
@@ -127,15 +92,12 @@ func TestParseDelCommand(t *testing.T) {
 	buf := new(bytes.Buffer)
 	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
 
-	commandType, _ := compute.getCommandType("DEL")
 	expected := &Command{
-		Type: commandType,
-		Arguments: Arguments{
-			Key: Argument("test"),
-		},
+		Type: Del,
+		Keys: []Argument{"test", "other"},
 	}
 
-	commandStr := "DEL test"
+	commandStr := "DEL test other"
 
 	actual, err := compute.Parse(ctx, commandStr)
 
@@ -176,3 +138,453 @@ func TestParseUnknownCommandType(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, expected, err)
 }
+
+func TestParseSaveCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	actual, err := compute.Parse(ctx, "SAVE")
+	assert.Nil(t, err)
+	assert.Equal(t, &Command{Type: Save}, actual)
+
+	actual, err = compute.Parse(ctx, "BGSAVE")
+	assert.Nil(t, err)
+	assert.Equal(t, &Command{Type: BgSave}, actual)
+
+	actual, err = compute.Parse(ctx, "SAVE now")
+	assert.Nil(t, actual)
+	assert.Equal(t, errTooManyArguments, err)
+}
+
+func TestParseExpirationCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+	}{
+		{
+			name:     "set with seconds",
+			command:  "SET key value EX 10",
+			expected: &Command{Type: Set, Keys: []Argument{"key"}, Args: SetArgs{Value: "value", TTL: 10 * time.Second}},
+		},
+		{
+			name:     "set with milliseconds",
+			command:  "SET key value PX 1500",
+			expected: &Command{Type: Set, Keys: []Argument{"key"}, Args: SetArgs{Value: "value", TTL: 1500 * time.Millisecond}},
+		},
+		{
+			name:     "expire",
+			command:  "EXPIRE key 20",
+			expected: &Command{Type: Expire, Keys: []Argument{"key"}, Args: ExpireArgs{TTL: 20 * time.Second}},
+		},
+		{
+			name:     "pexpire",
+			command:  "PEXPIRE key 1500",
+			expected: &Command{Type: PExpire, Keys: []Argument{"key"}, Args: ExpireArgs{TTL: 1500 * time.Millisecond}},
+		},
+		{
+			name:     "expire with negative timeout",
+			command:  "EXPIRE key -1",
+			expected: &Command{Type: Expire, Keys: []Argument{"key"}, Args: ExpireArgs{TTL: -time.Second}},
+		},
+		{name: "ttl", command: "TTL key", expected: &Command{Type: TTL, Keys: []Argument{"key"}}},
+		{name: "pttl", command: "PTTL key", expected: &Command{Type: PTTL, Keys: []Argument{"key"}}},
+		{name: "persist", command: "PERSIST key", expected: &Command{Type: Persist, Keys: []Argument{"key"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseExpirationErrors(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected error
+	}{
+		{name: "unknown set option", command: "SET key value XX 10", expected: errSyntax},
+		{name: "set option without value", command: "SET key value EX", expected: errSyntax},
+		{name: "zero seconds", command: "SET key value EX 0", expected: errInvalidExpireTime},
+		{name: "not a number", command: "SET key value PX soon", expected: errInvalidExpireTime},
+		{name: "expire without timeout", command: "EXPIRE key", expected: errWrongArgumentsNumber},
+		{name: "expire with invalid timeout", command: "EXPIRE key ten", expected: errInvalidExpireTime},
+		{name: "ttl with extra argument", command: "TTL key value", expected: errWrongArgumentsNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Nil(t, actual)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestParseTransactionCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	for _, commandType := range []CommandType{Multi, Exec, Discard, Unwatch, Commit, Rollback} {
+		actual, err := compute.Parse(ctx, string(commandType))
+		assert.NoError(t, err)
+		assert.Equal(t, &Command{Type: commandType}, actual)
+		assert.True(t, actual.Type.IsTransaction())
+
+		_, err = compute.Parse(ctx, string(commandType)+" key")
+		assert.Equal(t, errTooManyArguments, err)
+	}
+
+	actual, err := compute.Parse(ctx, "WATCH a b")
+	assert.NoError(t, err)
+	assert.Equal(t, &Command{Type: Watch, Keys: []Argument{"a", "b"}}, actual)
+
+	_, err = compute.Parse(ctx, "WATCH")
+	assert.Equal(t, errNotEnoughArguments, err)
+
+	actual, err = compute.Parse(ctx, "BEGIN READONLY")
+	assert.NoError(t, err)
+	assert.Equal(t, &Command{Type: Begin}, actual)
+
+	_, err = compute.Parse(ctx, "BEGIN")
+	assert.Equal(t, errNotEnoughArguments, err)
+	_, err = compute.Parse(ctx, "BEGIN WRITE")
+	assert.Equal(t, errSyntax, err)
+}
+
+func TestParseCounterCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+		err      error
+	}{
+		{
+			name:     "incr",
+			command:  "INCR hits",
+			expected: &Command{Type: Incr, Keys: []Argument{"hits"}, Args: IncrementArgs{Increment: 1}},
+		},
+		{
+			name:     "decr",
+			command:  "DECR hits",
+			expected: &Command{Type: Decr, Keys: []Argument{"hits"}, Args: IncrementArgs{Increment: -1}},
+		},
+		{
+			name:     "incrby",
+			command:  "INCRBY hits 10",
+			expected: &Command{Type: IncrBy, Keys: []Argument{"hits"}, Args: IncrementArgs{Increment: 10}},
+		},
+		{
+			name:     "decrby",
+			command:  "DECRBY hits 10",
+			expected: &Command{Type: DecrBy, Keys: []Argument{"hits"}, Args: IncrementArgs{Increment: -10}},
+		},
+		{
+			name:     "incrbyfloat",
+			command:  "INCRBYFLOAT price 0.5",
+			expected: &Command{Type: IncrByFloat, Keys: []Argument{"price"}, Args: FloatIncrementArgs{Increment: 0.5}},
+		},
+		{name: "incr with increment", command: "INCR hits 1", err: errWrongArgumentsNumber},
+		{name: "incrby without increment", command: "INCRBY hits", err: errWrongArgumentsNumber},
+		{name: "invalid increment", command: "INCRBY hits 1.5", err: errInvalidIncrement},
+		{name: "decrby min int", command: "DECRBY hits -9223372036854775808", err: errInvalidIncrement},
+		{name: "invalid float", command: "INCRBYFLOAT price abc", err: errInvalidFloat},
+		{name: "infinite float", command: "INCRBYFLOAT price inf", err: errInvalidFloat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseListCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+		err      error
+	}{
+		{
+			name:    "lpush",
+			command: "LPUSH list a b",
+			expected: &Command{Type: LPush, Keys: []Argument{"list"}, Args: ValuesArgs{
+				Values: []Argument{"a", "b"},
+			}},
+		},
+		{
+			name:     "rpop",
+			command:  "RPOP list",
+			expected: &Command{Type: RPop, Keys: []Argument{"list"}},
+		},
+		{
+			name:     "lindex",
+			command:  "LINDEX list -1",
+			expected: &Command{Type: LIndex, Keys: []Argument{"list"}, Args: IndexArgs{Index: -1}},
+		},
+		{
+			name:     "lrange",
+			command:  "LRANGE list 0 -1",
+			expected: &Command{Type: LRange, Keys: []Argument{"list"}, Args: IndexRangeArgs{Start: 0, Stop: -1}},
+		},
+		{
+			name:    "blpop",
+			command: "BLPOP first second 1.5",
+			expected: &Command{Type: BLPop, Keys: []Argument{"first", "second"}, Args: BlockingPopArgs{
+				Timeout: 1500 * time.Millisecond,
+			}},
+		},
+		{name: "push without values", command: "RPUSH list", err: errWrongArgumentsNumber},
+		{name: "lpop with count", command: "LPOP list 1", err: errWrongArgumentsNumber},
+		{name: "invalid index", command: "LTRIM list a 1", err: errInvalidIndex},
+		{name: "blpop without keys", command: "BLPOP 0", err: errWrongArgumentsNumber},
+		{name: "blpop without timeout", command: "BLPOP list", err: errWrongArgumentsNumber},
+		{name: "negative timeout", command: "BRPOP list -1", err: errInvalidTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseHashCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+		err      error
+	}{
+		{
+			name:    "hset",
+			command: "HSET user name alice age 30",
+			expected: &Command{Type: HSet, Keys: []Argument{"user"}, Args: ValuesArgs{
+				Values: []Argument{"name", "alice", "age", "30"},
+			}},
+		},
+		{
+			name:     "hget",
+			command:  "HGET user name",
+			expected: &Command{Type: HGet, Keys: []Argument{"user"}, Args: FieldsArgs{Fields: []Argument{"name"}}},
+		},
+		{
+			name:    "hmget",
+			command: "HMGET user name age",
+			expected: &Command{Type: HMGet, Keys: []Argument{"user"}, Args: FieldsArgs{
+				Fields: []Argument{"name", "age"},
+			}},
+		},
+		{
+			name:    "hincrby",
+			command: "HINCRBY user age -1",
+			expected: &Command{Type: HIncrBy, Keys: []Argument{"user"}, Args: HashIncrementArgs{
+				Field: "age", Increment: -1,
+			}},
+		},
+		{
+			name:     "hgetall",
+			command:  "HGETALL user",
+			expected: &Command{Type: HGetAll, Keys: []Argument{"user"}},
+		},
+		{name: "hset without value", command: "HSET user name alice age", err: errWrongArgumentsNumber},
+		{name: "hget without field", command: "HGET user", err: errWrongArgumentsNumber},
+		{name: "hdel without fields", command: "HDEL user", err: errWrongArgumentsNumber},
+		{name: "hkeys with field", command: "HKEYS user name", err: errWrongArgumentsNumber},
+		{name: "invalid increment", command: "HINCRBY user age x", err: errInvalidIncrement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseSetCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+		err      error
+	}{
+		{
+			name:     "sadd",
+			command:  "SADD tags go db",
+			expected: &Command{Type: SAdd, Keys: []Argument{"tags"}, Args: ValuesArgs{Values: []Argument{"go", "db"}}},
+		},
+		{
+			name:     "sismember",
+			command:  "SISMEMBER tags go",
+			expected: &Command{Type: SIsMember, Keys: []Argument{"tags"}, Args: ValuesArgs{Values: []Argument{"go"}}},
+		},
+		{
+			name:     "sinter",
+			command:  "SINTER a b",
+			expected: &Command{Type: SInter, Keys: []Argument{"a", "b"}},
+		},
+		{
+			name:    "zadd",
+			command: "ZADD board 10 alice -inf bob",
+			expected: &Command{Type: ZAdd, Keys: []Argument{"board"}, Args: ScoredMembersArgs{
+				Members: []Argument{"alice", "bob"}, Scores: []float64{10, math.Inf(-1)},
+			}},
+		},
+		{
+			name:    "zincrby",
+			command: "ZINCRBY board 1.5 alice",
+			expected: &Command{Type: ZIncrBy, Keys: []Argument{"board"}, Args: ScoreIncrementArgs{
+				Member: "alice", Increment: 1.5,
+			}},
+		},
+		{
+			name:    "zrange",
+			command: "ZRANGE board 0 -1 WITHSCORES",
+			expected: &Command{Type: ZRange, Keys: []Argument{"board"}, Args: RankRangeArgs{
+				Start: 0, Stop: -1, WithScores: true,
+			}},
+		},
+		{
+			name:    "zrangebyscore",
+			command: "ZRANGEBYSCORE board (10 +inf LIMIT 5 -1 WITHSCORES",
+			expected: &Command{Type: ZRangeByScore, Keys: []Argument{"board"}, Args: ScoreRangeArgs{
+				Min: ScoreBound{Value: 10, Exclusive: true}, Max: ScoreBound{Value: math.Inf(1)},
+				Offset: 5, Limit: -1, WithScores: true,
+			}},
+		},
+		{
+			name:    "zrangebyscore with zero count",
+			command: "ZRANGEBYSCORE board 0 10 LIMIT 2 0",
+			expected: &Command{Type: ZRangeByScore, Keys: []Argument{"board"}, Args: ScoreRangeArgs{
+				Min: ScoreBound{Value: 0}, Max: ScoreBound{Value: 10}, Offset: 2,
+			}},
+		},
+		{name: "sadd without members", command: "SADD tags", err: errWrongArgumentsNumber},
+		{name: "zadd without member", command: "ZADD board 10", err: errWrongArgumentsNumber},
+		{name: "invalid score", command: "ZADD board abc alice", err: errInvalidScore},
+		{name: "nan score", command: "ZADD board nan alice", err: errInvalidScore},
+		{name: "zrange unknown option", command: "ZRANGE board 0 1 SCORES", err: errSyntax},
+		{name: "invalid bound", command: "ZRANGEBYSCORE board (a 10", err: errInvalidScore},
+		{name: "incomplete limit", command: "ZRANGEBYSCORE board 0 10 LIMIT 1", err: errSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseScanCommands(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	tests := []struct {
+		name     string
+		command  string
+		expected *Command
+		err      error
+	}{
+		{
+			name:     "range",
+			command:  "RANGE a b",
+			expected: &Command{Type: Range, Args: RangeArgs{Start: "a", End: "b"}},
+		},
+		{
+			name:     "range with limit",
+			command:  "RANGE a b LIMIT 10",
+			expected: &Command{Type: Range, Args: RangeArgs{Start: "a", End: "b", Limit: 10}},
+		},
+		{
+			name:     "prefix",
+			command:  "PREFIX user:",
+			expected: &Command{Type: Prefix, Args: PrefixArgs{Prefix: "user:"}},
+		},
+		{
+			name:     "prefix with limit",
+			command:  "PREFIX user: LIMIT 5",
+			expected: &Command{Type: Prefix, Args: PrefixArgs{Prefix: "user:", Limit: 5}},
+		},
+		{name: "range without end", command: "RANGE a", err: errWrongArgumentsNumber},
+		{name: "range with unknown option", command: "RANGE a b COUNT 10", err: errSyntax},
+		{name: "prefix without limit value", command: "PREFIX user: LIMIT", err: errWrongArgumentsNumber},
+		{name: "zero limit", command: "PREFIX user: LIMIT 0", err: errInvalidLimit},
+		{name: "invalid limit", command: "RANGE a b LIMIT ten", err: errInvalidLimit},
+		{
+			name:     "scan",
+			command:  "SCAN 0",
+			expected: &Command{Type: Scan, Args: ScanArgs{Cursor: "0", Count: 10}},
+		},
+		{
+			name:     "scan with options",
+			command:  "SCAN 17 COUNT 100 MATCH user:*",
+			expected: &Command{Type: Scan, Args: ScanArgs{Cursor: "17", Pattern: "user:*", Count: 100}},
+		},
+		{
+			name:     "keys",
+			command:  "KEYS *",
+			expected: &Command{Type: Keys, Args: PatternArgs{Pattern: "*"}},
+		},
+		{name: "scan without option value", command: "SCAN 0 MATCH", err: errWrongArgumentsNumber},
+		{name: "scan with unknown option", command: "SCAN 0 LIMIT 10", err: errSyntax},
+		{name: "scan with invalid count", command: "SCAN 0 COUNT 0", err: errInvalidCount},
+		{name: "keys with extra argument", command: "KEYS * 1", err: errWrongArgumentsNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := compute.Parse(ctx, tt.command)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -1,9 +1,26 @@
 package compute
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
 
 var (
-	errInvalidLogger      = errors.New("invalid logger")
-	errNotEnoughArguments = errors.New("not enought arguments")
-	errUnknownCommandType = errors.New("unknown command type")
+	errInvalidLogger        = errors.New("invalid logger")
+	errNotEnoughArguments   = ports.NewClientError(ports.CodeWrongArguments, "not enought arguments")
+	errUnknownCommandType   = ports.NewClientError(ports.CodeUnknownCommand, "unknown command type")
+	errTooManyArguments     = ports.NewClientError(ports.CodeWrongArguments, "too many arguments")
+	errWrongArgumentsNumber = ports.NewClientError(ports.CodeWrongArguments, "wrong number of arguments")
+	errSyntax               = ports.NewClientError(ports.CodeSyntax, "syntax error")
+	errInvalidExpireTime    = ports.NewClientError(ports.CodeInvalidArgument, "invalid expire time")
+	errInvalidLimit         = ports.NewClientError(ports.CodeInvalidArgument, "invalid limit")
+	errInvalidCount         = ports.NewClientError(ports.CodeInvalidArgument, "invalid count")
+	errInvalidIncrement     = ports.NewClientError(ports.CodeNotInteger, "increment is not an integer or out of range")
+	errInvalidFloat         = ports.NewClientError(ports.CodeNotFloat, "increment is not a valid float")
+	errInvalidIndex         = ports.NewClientError(ports.CodeNotInteger, "value is not an integer or out of range")
+	errInvalidTimeout       = ports.NewClientError(ports.CodeNotFloat, "timeout is not a float or out of range")
+	errInvalidScore         = ports.NewClientError(ports.CodeNotFloat, "score is not a valid float")
+	errInvalidSpec          = errors.New("command needs a name and an arity")
+	errDuplicateCommand     = errors.New("command is already registered")
 )
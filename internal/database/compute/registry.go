@@ -0,0 +1,114 @@
+package compute
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Spec declares a command: its name, aliases, arity and how its arguments
+// are parsed.
+type Spec struct {
+	Name    CommandType
+	Aliases []string
+	// Arity is the number of tokens with the command name, a negative
+	// arity -N means at least N tokens like in redis
+	Arity int
+	// Write marks commands which may modify their keys
+	Write bool
+	// Keys tells which tokens are keys, the zero range means no keys
+	Keys KeyRange
+	// Parse builds the arguments of the command after the arity check from
+	// all tokens, the name included. Nil means the command has only keys.
+	Parse func(tokens []string) (any, error)
+}
+
+// KeyRange holds token positions of the first and the last key, a negative
+// Last counts from the end like in redis.
+type KeyRange struct {
+	First int
+	Last  int
+}
+
+// pick returns the keys among the tokens.
+func (k KeyRange) pick(tokens []string) []Argument {
+	if k.First <= 0 || k.First >= len(tokens) {
+		return nil
+	}
+
+	last := k.Last
+	if last < 0 {
+		last += len(tokens)
+	}
+	last = min(last, len(tokens)-1)
+	if last < k.First {
+		return nil
+	}
+
+	return toArguments(tokens[k.First : last+1])
+}
+
+// checkArity validates the number of tokens with the command name.
+func (s Spec) checkArity(tokens int) error {
+	switch {
+	case s.Arity == 1 && tokens > 1:
+		return errTooManyArguments
+	case s.Arity != 1 && tokens < minTokensNum:
+		return errNotEnoughArguments
+	case s.Arity > 0 && tokens != s.Arity, s.Arity < 0 && tokens < -s.Arity:
+		return errWrongArgumentsNumber
+	default:
+		return nil
+	}
+}
+
+// Registry maps command names and aliases to their specs, names are case
+// insensitive.
+type Registry struct {
+	mu    *sync.RWMutex
+	specs map[string]Spec
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		mu:    &sync.RWMutex{},
+		specs: make(map[string]Spec),
+	}
+}
+
+// Register adds the command, names and aliases have to be unique.
+func (r *Registry) Register(spec Spec) error {
+	if spec.Name == "" || spec.Arity == 0 {
+		return fmt.Errorf("%w: %q", errInvalidSpec, spec.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := append([]string{string(spec.Name)}, spec.Aliases...)
+	for i, name := range names {
+		names[i] = strings.ToUpper(name)
+	}
+
+	for _, name := range names {
+		if _, ok := r.specs[name]; ok {
+			return fmt.Errorf("%w: %q", errDuplicateCommand, name)
+		}
+	}
+
+	for _, name := range names {
+		r.specs[name] = spec
+	}
+
+	return nil
+}
+
+// Lookup finds the spec by the command name or one of its aliases.
+func (r *Registry) Lookup(name string) (Spec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	spec, ok := r.specs[strings.ToUpper(name)]
+
+	return spec, ok
+}
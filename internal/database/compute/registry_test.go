@@ -0,0 +1,93 @@
+package compute
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterCommand(t *testing.T) {
+	ctx := context.Background()
+
+	buf := new(bytes.Buffer)
+	compute, _ := NewCompute(slog.New(slog.NewTextHandler(buf, nil)))
+
+	echo := Spec{
+		Name:    "ECHO",
+		Aliases: []string{"SAY"},
+		Arity:   -2,
+		Parse: func(tokens []string) (any, error) {
+			return strings.Join(tokens[1:], " "), nil
+		},
+	}
+	assert.NoError(t, compute.Register(echo))
+
+	actual, err := compute.Parse(ctx, "SAY hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, &Command{Type: "ECHO", Args: "hello world"}, actual)
+
+	_, err = compute.Parse(ctx, "ECHO")
+	assert.Equal(t, errNotEnoughArguments, err)
+
+	spec, ok := compute.Lookup("ECHO")
+	assert.True(t, ok)
+	assert.False(t, spec.Write)
+
+	assert.ErrorIs(t, compute.Register(Spec{Name: "GETX", Aliases: []string{"SAY"}, Arity: 2}), errDuplicateCommand)
+	assert.ErrorIs(t, compute.Register(Spec{Name: Get, Arity: 2}), errDuplicateCommand)
+	assert.ErrorIs(t, compute.Register(Spec{Name: "NOARITY"}), errInvalidSpec)
+
+	// a failed registration does not register any of the names
+	_, ok = compute.Lookup("GETX")
+	assert.False(t, ok)
+}
+
+func TestCheckArity(t *testing.T) {
+	tests := []struct {
+		name     string
+		arity    int
+		tokens   int
+		expected error
+	}{
+		{name: "no arguments", arity: 1, tokens: 1, expected: nil},
+		{name: "unexpected argument", arity: 1, tokens: 2, expected: errTooManyArguments},
+		{name: "missing key", arity: 2, tokens: 1, expected: errNotEnoughArguments},
+		{name: "exact arity", arity: 3, tokens: 3, expected: nil},
+		{name: "wrong exact arity", arity: 3, tokens: 4, expected: errWrongArgumentsNumber},
+		{name: "minimal arity", arity: -3, tokens: 5, expected: nil},
+		{name: "below minimal arity", arity: -3, tokens: 2, expected: errWrongArgumentsNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Spec{Name: "CMD", Arity: tt.arity}.checkArity(tt.tokens))
+		})
+	}
+}
+
+func TestKeyRange(t *testing.T) {
+	tokens := []string{"CMD", "a", "b", "c"}
+
+	tests := []struct {
+		name     string
+		keys     KeyRange
+		expected []Argument
+	}{
+		{name: "no keys", keys: KeyRange{}, expected: nil},
+		{name: "single key", keys: KeyRange{First: 1, Last: 1}, expected: []Argument{"a"}},
+		{name: "every key", keys: KeyRange{First: 1, Last: -1}, expected: []Argument{"a", "b", "c"}},
+		{name: "keys before the last token", keys: KeyRange{First: 1, Last: -2}, expected: []Argument{"a", "b"}},
+		{name: "last key out of tokens", keys: KeyRange{First: 2, Last: 10}, expected: []Argument{"b", "c"}},
+		{name: "first key out of tokens", keys: KeyRange{First: 4, Last: -1}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.keys.pick(tokens))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package compute
+
+import "strings"
+
+// tokenize splits a query into arguments like redis-cli does. Tokens are
+// separated by whitespace, "double quoted" tokens support \n, \r, \t, \b,
+// \a, \xHH and escaped quotes and backslashes, 'single quoted' tokens only
+// support \'. A closing quote has to be followed by whitespace.
+func tokenize(query string) ([]string, error) {
+	var tokens []string
+
+	i := 0
+	for {
+		for i < len(query) && isSpace(query[i]) {
+			i++
+		}
+		if i == len(query) {
+			return tokens, nil
+		}
+
+		var token strings.Builder
+		var err error
+		switch query[i] {
+		case '"':
+			i, err = readDoubleQuoted(query, i, &token)
+		case '\'':
+			i, err = readSingleQuoted(query, i, &token)
+		default:
+			for i < len(query) && !isSpace(query[i]) {
+				token.WriteByte(query[i])
+				i++
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token.String())
+	}
+}
+
+// readDoubleQuoted reads the token starting with the quote at start and
+// returns the position after it.
+func readDoubleQuoted(query string, start int, token *strings.Builder) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '"':
+			return closeQuote(query, i)
+		case '\\':
+			if i+1 == len(query) {
+				return 0, unbalancedQuotes(start)
+			}
+
+			i++
+			if query[i] == 'x' && i+2 < len(query) && isHex(query[i+1]) && isHex(query[i+2]) {
+				token.WriteByte(unhex(query[i+1])<<4 | unhex(query[i+2]))
+				i += 2
+				continue
+			}
+
+			token.WriteByte(unescape(query[i]))
+		default:
+			token.WriteByte(query[i])
+		}
+	}
+
+	return 0, unbalancedQuotes(start)
+}
+
+func readSingleQuoted(query string, start int, token *strings.Builder) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case query[i] == '\'':
+			return closeQuote(query, i)
+		case query[i] == '\\' && i+1 < len(query) && query[i+1] == '\'':
+			token.WriteByte('\'')
+			i++
+		default:
+			token.WriteByte(query[i])
+		}
+	}
+
+	return 0, unbalancedQuotes(start)
+}
+
+func closeQuote(query string, i int) (int, error) {
+	if i+1 < len(query) && !isSpace(query[i+1]) {
+		return 0, errSyntax.Detail("closing quote at position %d must be followed by a space", i)
+	}
+
+	return i + 1, nil
+}
+
+func unbalancedQuotes(start int) error {
+	return errSyntax.Detail("unbalanced quotes at position %d", start)
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return c
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	default:
+		return c - 'a' + 10
+	}
+}
@@ -0,0 +1,59 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "should split by spaces", query: "SET key value", expected: []string{"SET", "key", "value"}},
+		{name: "should skip repeated spaces", query: "  SET \t key   value ", expected: []string{"SET", "key", "value"}},
+		{name: "should be empty", query: "   ", expected: nil},
+		{name: "should keep spaces in double quotes", query: `SET key "hello world"`, expected: []string{"SET", "key", "hello world"}},
+		{name: "should keep spaces in single quotes", query: `SET key 'hello world'`, expected: []string{"SET", "key", "hello world"}},
+		{name: "should keep empty quoted token", query: `SET "" ''`, expected: []string{"SET", "", ""}},
+		{name: "should unescape double quotes", query: `SET k "a\"b\\c\n\t"`, expected: []string{"SET", "k", "a\"b\\c\n\t"}},
+		{name: "should decode hex bytes", query: `SET k "\x00\xffz\x4A"`, expected: []string{"SET", "k", "\x00\xffzJ"}},
+		{name: "should keep invalid hex escape", query: `SET k "\xZZ"`, expected: []string{"SET", "k", "xZZ"}},
+		{name: "should only unescape quote in single quotes", query: `SET k 'it\'s \n'`, expected: []string{"SET", "k", `it's \n`}},
+		{name: "should keep quotes inside unquoted token", query: `SET k a"b'c`, expected: []string{"SET", "k", `a"b'c`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tokenize(tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "should reject unclosed double quote", query: `SET k "value`, expected: "syntax error: unbalanced quotes at position 6"},
+		{name: "should reject unclosed single quote", query: `SET 'k value`, expected: "syntax error: unbalanced quotes at position 4"},
+		{name: "should reject trailing backslash", query: `SET k "value\`, expected: "syntax error: unbalanced quotes at position 6"},
+		{name: "should reject text after closing quote", query: `SET "k"v value`, expected: "syntax error: closing quote at position 6 must be followed by a space"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tokenize(tt.query)
+
+			assert.Nil(t, actual)
+			assert.ErrorIs(t, err, errSyntax)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -2,23 +2,80 @@ package database
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"kdb/internal/database/compute"
-	"kdb/internal/ports"
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/ports"
 	"log/slog"
+	"maps"
+	"sync"
+	"time"
 )
 
 type Database struct {
 	compute *compute.Compute
 	storage StorageLayer
 	logger  *slog.Logger
+	// commands share the lock, EXEC takes it exclusively so a transaction
+	// is not interleaved with other commands
+	mu      *sync.RWMutex
+	watches *watches
+	waiters *waiters
+	// handlers of registered commands
+	handlers map[compute.CommandType]Handler
+	changes  map[compute.CommandType]Changes
 }
 
+const (
+	responseOK               = "OK"
+	responseBackgroundSaving = "Background saving started"
+	responseQueued           = "QUEUED"
+)
+
 type StorageLayer interface {
-	Get(ctx context.Context, key string) (string, error)
+	Get(ctx context.Context, key string) (string, bool, error)
 	Set(ctx context.Context, key, value string) error
-	Del(ctx context.Context, key string) error
+	Del(ctx context.Context, keys ...string) (int, error)
+	Save(ctx context.Context) error
+	BackgroundSave(ctx context.Context) error
+	SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) error
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	TTL(ctx context.Context, key string) (time.Duration, bool, error)
+	Persist(ctx context.Context, key string) (bool, error)
+	Range(ctx context.Context, start, end string, limit int) (engine.Iterator, error)
+	Prefix(ctx context.Context, prefix string, limit int) (engine.Iterator, error)
+	Scan(ctx context.Context, cursor, pattern string, count int) ([]string, string, error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	BeginRead(ctx context.Context) (*storage.ReadTx, error)
+	IncrBy(ctx context.Context, key string, delta int64) (int64, error)
+	IncrByFloat(ctx context.Context, key string, delta float64) (string, error)
+	Push(ctx context.Context, key string, side engine.Side, values ...string) (int, error)
+	Pop(ctx context.Context, key string, side engine.Side) (string, bool, error)
+	ListRange(ctx context.Context, key string, start, stop int) ([]string, error)
+	ListLen(ctx context.Context, key string) (int, error)
+	ListIndex(ctx context.Context, key string, index int) (string, bool, error)
+	ListTrim(ctx context.Context, key string, start, stop int) error
+	HSet(ctx context.Context, key string, pairs ...string) (int, error)
+	HGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error)
+	HExists(ctx context.Context, key, field string) (bool, error)
+	HGetAll(ctx context.Context, key string) ([]string, error)
+	HDel(ctx context.Context, key string, fields ...string) (int, error)
+	HLen(ctx context.Context, key string) (int, error)
+	HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error)
+	SAdd(ctx context.Context, key string, members ...string) (int, error)
+	SRem(ctx context.Context, key string, members ...string) (int, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SIsMember(ctx context.Context, key, member string) (bool, error)
+	SCard(ctx context.Context, key string) (int, error)
+	SCombine(ctx context.Context, op engine.SetOp, keys ...string) ([]string, error)
+	ZAdd(ctx context.Context, key string, members ...engine.ScoredMember) (int, error)
+	ZRem(ctx context.Context, key string, members ...string) (int, error)
+	ZScore(ctx context.Context, key, member string) (float64, bool, error)
+	ZRank(ctx context.Context, key, member string) (int, bool, error)
+	ZRange(ctx context.Context, key string, start, stop int) ([]engine.ScoredMember, error)
+	ZRangeByScore(ctx context.Context, key string, min, max engine.ScoreBound, offset, count int) ([]engine.ScoredMember, error)
+	ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error)
 }
 
 func NewDatabase(compute *compute.Compute, storage StorageLayer, logger *slog.Logger) (*Database, error) {
@@ -35,12 +92,36 @@ func NewDatabase(compute *compute.Compute, storage StorageLayer, logger *slog.Lo
 	}
 
 	return &Database{
-		compute: compute,
-		storage: storage,
-		logger:  logger,
+		compute:  compute,
+		storage:  storage,
+		logger:   logger,
+		mu:       &sync.RWMutex{},
+		watches:  newWatches(),
+		waiters:  newWaiters(),
+		handlers: maps.Clone(builtinHandlers),
+		changes:  maps.Clone(builtinChanges),
 	}, nil
 }
 
+// Register adds a command to the parser and the dispatch table. Commands
+// have to be registered before the database serves clients.
+func (d Database) Register(command Command) error {
+	if command.Handler == nil {
+		return fmt.Errorf("%w: %q", errInvalidHandler, command.Name)
+	}
+
+	err := d.compute.Register(command.Spec)
+	if err != nil {
+		return fmt.Errorf("trying to register command: %w", err)
+	}
+	d.handlers[command.Name] = command.Handler
+	if command.Changes != nil {
+		d.changes[command.Name] = command.Changes
+	}
+
+	return nil
+}
+
 func (d Database) Execute(ctx context.Context, commandStr string) (*ports.Result, error) {
 	logAttrs := []any{
 		slog.String("component", "database"),
@@ -54,7 +135,45 @@ func (d Database) Execute(ctx context.Context, commandStr string) (*ports.Result
 		return nil, err
 	}
 
-	return d.executeCommand(ctx, command)
+	if command.Type.IsTransaction() {
+		d.logger.ErrorContext(ctx, errSessionRequired.Error(), logAttrs...)
+		return nil, errSessionRequired
+	}
+
+	if command.Type.IsBlockingPop() {
+		return d.blockingPop(ctx, command)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.execute(ctx, command)
+}
+
+// execute runs the command, notifies sessions watching the modified keys and
+// clients blocked on pushed lists. The caller holds the lock.
+func (d Database) execute(ctx context.Context, command *compute.Command) (*ports.Result, error) {
+	result, err := d.executeCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	if spec, _ := d.compute.Lookup(command.Type); !spec.Write {
+		return result, nil
+	}
+
+	keys := command.Keys
+	if changes, ok := d.changes[command.Type]; ok {
+		keys = changes(command, result)
+	}
+	for _, key := range keys {
+		d.watches.touch(string(key))
+	}
+
+	if command.Type.IsPush() {
+		d.waiters.notify(string(command.Key()))
+	}
+
+	return result, nil
 }
 
 func (d Database) executeCommand(ctx context.Context, command *compute.Command) (*ports.Result, error) {
@@ -64,23 +183,13 @@ func (d Database) executeCommand(ctx context.Context, command *compute.Command)
 		slog.Any("command", command),
 	}
 
-	var res string
-	var err error
-
-	switch {
-	case command.Type.IsGet():
-		res, err = d.storage.Get(ctx, string(command.Arguments.Key))
-		logAttrs = append(logAttrs, slog.String("storage method", "get"))
-	case command.Type.IsSet():
-		err = d.storage.Set(ctx, string(command.Arguments.Key), string(command.Arguments.Value))
-		logAttrs = append(logAttrs, slog.String("storage method", "set"))
-	case command.Type.IsDel():
-		err = d.storage.Del(ctx, string(command.Arguments.Key))
-		logAttrs = append(logAttrs, slog.String("storage method", "del"))
-	default:
-		err = errors.Join(errUnknownCommand)
+	handler, ok := d.handlers[command.Type]
+	if !ok {
+		d.logger.ErrorContext(ctx, errUnknownCommand.Error(), logAttrs...)
+		return nil, errUnknownCommand
 	}
 
+	result, err := handler(ctx, d.storage, command)
 	if err != nil {
 		wErr := fmt.Errorf("storage call: %w", err)
 		d.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
@@ -88,7 +197,5 @@ func (d Database) executeCommand(ctx context.Context, command *compute.Command)
 		return nil, wErr
 	}
 
-	return &ports.Result{
-		Msg: res,
-	}, nil
+	return result, nil
 }
@@ -6,11 +6,15 @@ import (
 	"errors"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
-	"kdb/internal/database/compute"
-	"kdb/internal/database/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/ports"
 )
 
 func TestNewDatabaseEmptyCompute(t *testing.T) {
@@ -76,6 +80,422 @@ func TestInvalidCommand(t *testing.T) {
 	assert.ErrorContains(t, err, expectedErr.Error())
 }
 
+func TestSaveCommands(t *testing.T) {
+	ctx := context.Background()
+
+	compute := getMockedCompute(t)
+	storage := mocks.NewStorageLayer(t)
+	logger := getMockedLogger()
+
+	db, err := NewDatabase(compute, storage, logger)
+	assert.NoError(t, err)
+
+	storage.EXPECT().Save(ctx).Return(nil)
+	storage.EXPECT().BackgroundSave(ctx).Return(nil)
+
+	result, err := db.Execute(ctx, "SAVE")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Simple(responseOK), result)
+
+	result, err = db.Execute(ctx, "BGSAVE")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Simple(responseBackgroundSaving), result)
+}
+
+func TestExpirationCommands(t *testing.T) {
+	ctx := context.Background()
+
+	compute := getMockedCompute(t)
+	storage := mocks.NewStorageLayer(t)
+	logger := getMockedLogger()
+
+	db, err := NewDatabase(compute, storage, logger)
+	assert.NoError(t, err)
+
+	storage.EXPECT().SetWithTTL(ctx, "key", "value", 10*time.Second).Return(nil)
+	storage.EXPECT().Expire(ctx, "key", 5*time.Second).Return(true, nil)
+	storage.EXPECT().TTL(ctx, "key").Return(4600*time.Millisecond, true, nil)
+	storage.EXPECT().TTL(ctx, "missing").Return(0, false, nil)
+	storage.EXPECT().TTL(ctx, "persistent").Return(-1, true, nil)
+	storage.EXPECT().Persist(ctx, "key").Return(false, nil)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+	}{
+		{command: "SET key value EX 10", expected: ports.Simple(responseOK)},
+		{command: "EXPIRE key 5", expected: ports.Integer(1)},
+		{command: "TTL key", expected: ports.Integer(5)},
+		{command: "PTTL key", expected: ports.Integer(4600)},
+		{command: "TTL missing", expected: ports.Integer(-2)},
+		{command: "TTL persistent", expected: ports.Integer(-1)},
+		{command: "PERSIST key", expected: ports.Integer(0)},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.command)
+	}
+}
+
+func TestScanCommands(t *testing.T) {
+	ctx := context.Background()
+
+	compute := getMockedCompute(t)
+	storage := mocks.NewStorageLayer(t)
+	logger := getMockedLogger()
+
+	db, err := NewDatabase(compute, storage, logger)
+	assert.NoError(t, err)
+
+	ordered := engine.NewOrderedEngine()
+	assert.NoError(t, ordered.Set(ctx, "user:1", "alice smith"))
+	assert.NoError(t, ordered.Set(ctx, "user:2", "bob"))
+
+	it, err := ordered.Range(ctx, "user:", "user;")
+	assert.NoError(t, err)
+	storage.EXPECT().Range(ctx, "user:", "user;", 10).Return(it, nil)
+
+	result, err := db.Execute(ctx, "RANGE user: user; LIMIT 10")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"user:1", "alice smith", "user:2", "bob"}, collectRows(result))
+	assert.NoError(t, result.Rows.Close())
+
+	storage.EXPECT().Prefix(ctx, "user:", 0).Return(nil, errors.New("not supported"))
+	_, err = db.Execute(ctx, "PREFIX user:")
+	assert.Error(t, err)
+
+	storage.EXPECT().Scan(ctx, "0", "user:*", 10).Return([]string{"user:1", "user:2"}, "42", nil)
+	result, err = db.Execute(ctx, "SCAN 0 MATCH user:*")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Bulk("42"), ports.BulkArray([]string{"user:1", "user:2"})), result)
+
+	storage.EXPECT().Keys(ctx, "*").Return([]string{"user:1"}, nil)
+	result, err = db.Execute(ctx, "KEYS *")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, collectRows(result))
+}
+
+// collectRows lists bulk strings of an array, streamed or not.
+func collectRows(result *ports.Result) []string {
+	var collected []string
+	if result.Rows != nil {
+		for result.Rows.Next() {
+			collected = append(collected, result.Rows.Row())
+		}
+
+		return collected
+	}
+
+	for _, elem := range result.Elems {
+		collected = append(collected, elem.Str)
+	}
+
+	return collected
+}
+
+func TestSetOutOfMemory(t *testing.T) {
+	ctx := context.Background()
+
+	engine := engine.NewEngine(engine.WithMaxMemory(100, engine.NoEviction))
+	storage, err := storage.NewStorage(engine, getMockedLogger())
+	assert.NoError(t, err)
+
+	db, err := NewDatabase(getMockedCompute(t), storage, getMockedLogger())
+	assert.NoError(t, err)
+
+	_, err = db.Execute(ctx, "SET key value")
+	assert.NoError(t, err)
+
+	_, err = db.Execute(ctx, "SET other value")
+	assert.ErrorIs(t, err, ports.ErrOutOfMemory)
+}
+
+func TestNilAndDel(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+	}{
+		{command: "SET a 1", expected: ports.Simple(responseOK)},
+		{command: `SET b ""`, expected: ports.Simple(responseOK)},
+		{command: "GET b", expected: ports.Bulk("")},
+		{command: "GET missing", expected: ports.Nil()},
+		{command: "DEL a b missing", expected: ports.Integer(2)},
+		{command: "DEL a", expected: ports.Integer(0)},
+		{command: "GET b", expected: ports.Nil()},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.command)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	_, err := db.Execute(ctx, "SET text value")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		command  string
+		expected ports.Code
+	}{
+		{command: "GETT key", expected: ports.CodeUnknownCommand},
+		{command: "GET", expected: ports.CodeWrongArguments},
+		{command: `SET key "value`, expected: ports.CodeSyntax},
+		{command: "EXPIRE key soon", expected: ports.CodeInvalidArgument},
+		{command: "LPUSH text a", expected: ports.CodeWrongType},
+		{command: "INCR text", expected: ports.CodeNotInteger},
+		{command: "MULTI", expected: ports.CodeTransaction},
+		{command: "SCAN abc", expected: ports.CodeInvalidArgument},
+		{command: "SAVE", expected: ports.CodeNotSupported},
+	}
+
+	for _, tt := range tests {
+		_, err := db.Execute(ctx, tt.command)
+		assert.Equal(t, tt.expected, ports.CodeOf(err), tt.command)
+	}
+}
+
+func TestCounterCommands(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+	}{
+		{command: "INCR hits", expected: ports.Integer(1)},
+		{command: "INCRBY hits 10", expected: ports.Integer(11)},
+		{command: "DECR hits", expected: ports.Integer(10)},
+		{command: "DECRBY hits 20", expected: ports.Integer(-10)},
+		{command: "INCRBYFLOAT price 1.5", expected: ports.Bulk("1.5")},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.command)
+	}
+
+	_, err := db.Execute(ctx, "INCR price")
+	assert.ErrorIs(t, err, ports.ErrNotInteger)
+}
+
+func TestListCommands(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+	}{
+		{command: "RPUSH list b c", expected: ports.Integer(2)},
+		{command: "LPUSH list a", expected: ports.Integer(3)},
+		{command: "LLEN list", expected: ports.Integer(3)},
+		{command: "LINDEX list -1", expected: ports.Bulk("c")},
+		{command: "LINDEX list 5", expected: ports.Nil()},
+		{command: "LTRIM list 0 1", expected: ports.Simple(responseOK)},
+		{command: "RPOP list", expected: ports.Bulk("b")},
+		{command: "LPOP list", expected: ports.Bulk("a")},
+		{command: "LPOP list", expected: ports.Nil()},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.command)
+	}
+
+	_, err := db.Execute(ctx, "RPUSH list x y z")
+	assert.NoError(t, err)
+
+	result, err := db.Execute(ctx, "LRANGE list 1 -1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"y", "z"}, collectRows(result))
+
+	_, err = db.Execute(ctx, "GET list")
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+
+	_, err = db.Execute(ctx, "SET text value")
+	assert.NoError(t, err)
+	_, err = db.Execute(ctx, "LPUSH text a")
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+}
+
+func TestHashCommands(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+	}{
+		{command: "HSET user name alice age 30", expected: ports.Integer(2)},
+		{command: "HSET user city paris age 31", expected: ports.Integer(1)},
+		{command: "HGET user name", expected: ports.Bulk("alice")},
+		{command: "HGET user email", expected: ports.Nil()},
+		{command: "HGET missing name", expected: ports.Nil()},
+		{command: `HSET profile bio ""`, expected: ports.Integer(1)},
+		{command: "HGET profile bio", expected: ports.Bulk("")},
+		{command: "HEXISTS user city", expected: ports.Integer(1)},
+		{command: "HINCRBY user age 2", expected: ports.Integer(33)},
+		{command: "HLEN user", expected: ports.Integer(3)},
+		{command: "HDEL user city email", expected: ports.Integer(1)},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, result, tt.command)
+	}
+
+	rowsTests := []struct {
+		command  string
+		expected []string
+	}{
+		{command: "HGETALL user", expected: []string{"age", "33", "name", "alice"}},
+		{command: "HKEYS user", expected: []string{"age", "name"}},
+		{command: "HVALS user", expected: []string{"33", "alice"}},
+		{command: "HGETALL missing", expected: nil},
+	}
+
+	for _, tt := range rowsTests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, collectRows(result), tt.command)
+	}
+
+	// missing fields are nil elements, unlike fields holding ""
+	result, err := db.Execute(ctx, "HMGET user name email age")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Bulk("alice"), ports.Nil(), ports.Bulk("33")), result)
+
+	result, err = db.Execute(ctx, "HMGET profile bio missing")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Bulk(""), ports.Nil()), result)
+
+	_, err = db.Execute(ctx, "HINCRBY user name 1")
+	assert.ErrorIs(t, err, ports.ErrNotInteger)
+	_, err = db.Execute(ctx, "LPUSH user a")
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+}
+
+func TestSetCommands(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	tests := []struct {
+		command  string
+		expected *ports.Result
+		rows     []string
+	}{
+		{command: "SADD a x y z", expected: ports.Integer(3)},
+		{command: "SADD b y z w", expected: ports.Integer(3)},
+		{command: "SREM a z missing", expected: ports.Integer(1)},
+		{command: "SISMEMBER a x", expected: ports.Integer(1)},
+		{command: "SCARD a", expected: ports.Integer(2)},
+		{command: "SMEMBERS a", rows: []string{"x", "y"}},
+		{command: "SINTER a b", rows: []string{"y"}},
+		{command: "SUNION a b", rows: []string{"w", "x", "y", "z"}},
+		{command: "SDIFF b a", rows: []string{"w", "z"}},
+		{command: "ZADD board 10 alice 20 bob 15 carol", expected: ports.Integer(3)},
+		{command: "ZINCRBY board 2.5 alice", expected: ports.Bulk("12.5")},
+		{command: "ZSCORE board bob", expected: ports.Bulk("20")},
+		{command: "ZSCORE board missing", expected: ports.Nil()},
+		{command: "ZRANK board carol", expected: ports.Integer(1)},
+		{command: "ZRANGE board 0 -1", rows: []string{"alice", "carol", "bob"}},
+		{command: "ZRANGE board -1 -1 WITHSCORES", rows: []string{"bob", "20"}},
+		{command: "ZRANGEBYSCORE board (12.5 +inf WITHSCORES", rows: []string{"carol", "15", "bob", "20"}},
+		{command: "ZRANGEBYSCORE board -inf +inf LIMIT 1 1", rows: []string{"carol"}},
+		{command: "ZRANGEBYSCORE board -inf +inf LIMIT 0 0", expected: ports.BulkArray(nil)},
+		{command: "ZRANGEBYSCORE board -inf +inf LIMIT 1 -1", rows: []string{"carol", "bob"}},
+		{command: "ZREM board alice", expected: ports.Integer(1)},
+		{command: "ZADD board +inf dave", expected: ports.Integer(1)},
+		{command: "ZSCORE board dave", expected: ports.Bulk("inf")},
+	}
+
+	for _, tt := range tests {
+		result, err := db.Execute(ctx, tt.command)
+		assert.NoError(t, err, tt.command)
+		if tt.rows != nil {
+			assert.Equal(t, tt.rows, collectRows(result), tt.command)
+		} else {
+			assert.Equal(t, tt.expected, result, tt.command)
+		}
+	}
+
+	_, err := db.Execute(ctx, "SADD board x")
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+}
+
+func TestRegisterCommand(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	type getSetArgs struct {
+		value string
+	}
+
+	getSet := Command{
+		Spec: compute.Spec{
+			Name:    "GETSET",
+			Aliases: []string{"SWAP"},
+			Arity:   3,
+			Write:   true,
+			Keys:    compute.KeyRange{First: 1, Last: 1},
+			Parse: func(tokens []string) (any, error) {
+				return getSetArgs{value: tokens[2]}, nil
+			},
+		},
+		Handler: WithArgs(func(ctx context.Context, storage StorageLayer, command *compute.Command, args getSetArgs) (*ports.Result, error) {
+			old, _, err := storage.Get(ctx, string(command.Key()))
+			if err != nil {
+				return nil, err
+			}
+
+			return ports.Bulk(old), storage.Set(ctx, string(command.Key()), args.value)
+		}),
+	}
+	assert.NoError(t, db.Register(getSet))
+	assert.Error(t, db.Register(getSet))
+	assert.ErrorIs(t, db.Register(Command{Spec: compute.Spec{Name: "NOOP", Arity: 1}}), errInvalidHandler)
+
+	// the handler expects arguments the spec does not parse
+	assert.NoError(t, db.Register(Command{Spec: compute.Spec{Name: "BROKEN", Arity: 1}, Handler: getSet.Handler}))
+	_, err := db.Execute(ctx, "BROKEN")
+	assert.ErrorIs(t, err, errInvalidArguments)
+
+	_, err = db.Execute(ctx, "SET key a")
+	assert.NoError(t, err)
+
+	session := db.NewSession()
+	defer session.Close()
+	execute(t, session, "WATCH key", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "GET key", ports.Simple(responseQueued))
+
+	result, err := db.Execute(ctx, "SWAP key b")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.Bulk("a"), result)
+
+	_, err = db.Execute(ctx, "GETSET key")
+	assert.ErrorContains(t, err, "wrong number of arguments")
+
+	// the command is a write, so it touched the watched key
+	result, err = session.Execute(ctx, "EXEC")
+	assert.NoError(t, err)
+	assert.Equal(t, ports.NilArray(), result)
+}
+
 func getMockedCompute(t *testing.T) *compute.Compute {
 	compute, err := compute.NewCompute(getMockedLogger())
 	assert.NoError(t, err)
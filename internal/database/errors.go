@@ -1,11 +1,29 @@
 package database
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
 
 var (
 	errInvalidLogger  = errors.New("invalid logger")
 	errInvalidCompute = errors.New("invalid compute")
 	errInvalidStorage = errors.New("invalid storage")
-	errUnknownCommand = errors.New("unknown command")
-	errComputeParse   = errors.New("compute parse")
+	errUnknownCommand = ports.NewClientError(ports.CodeUnknownCommand, "unknown command")
+	errInvalidHandler = errors.New("command needs a handler")
+	// errInvalidArguments means the handler does not match Parse of the spec
+	errInvalidArguments = errors.New("unexpected command arguments")
+	errComputeParse     = errors.New("compute parse")
+
+	errSessionRequired     = ports.NewClientError(ports.CodeTransaction, "transactions require a session")
+	errNestedMulti         = ports.NewClientError(ports.CodeTransaction, "MULTI calls can not be nested")
+	errExecWithoutMulti    = ports.NewClientError(ports.CodeTransaction, "EXEC without MULTI")
+	errDiscardWithoutMulti = ports.NewClientError(ports.CodeTransaction, "DISCARD without MULTI")
+	errWatchInsideMulti    = ports.NewClientError(ports.CodeTransaction, "WATCH inside MULTI is not allowed")
+	errExecAborted         = ports.NewClientError(ports.CodeExecAborted, "transaction discarded because of previous errors")
+
+	errTransactionInProgress = ports.NewClientError(ports.CodeTransaction, "transaction is already in progress")
+	errNoTransaction         = ports.NewClientError(ports.CodeTransaction, "no transaction is in progress")
+	errReadOnlyTransaction   = ports.NewClientError(ports.CodeReadOnly, "command is not allowed in a read-only transaction")
 )
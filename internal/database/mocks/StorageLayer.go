@@ -5,7 +5,13 @@ package mocks
 import (
 	context "context"
 
+	engine "github.com/cat-go-dev/kdb/internal/database/storage/engine"
+
 	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/cat-go-dev/kdb/internal/database/storage"
+
+	time "time"
 )
 
 // StorageLayer is an autogenerated mock type for the StorageLayer type
@@ -21,17 +27,17 @@ func (_m *StorageLayer) EXPECT() *StorageLayer_Expecter {
 	return &StorageLayer_Expecter{mock: &_m.Mock}
 }
 
-// Del provides a mock function with given fields: ctx, key
-func (_m *StorageLayer) Del(ctx context.Context, key string) error {
-	ret := _m.Called(ctx, key)
+// BackgroundSave provides a mock function with given fields: ctx
+func (_m *StorageLayer) BackgroundSave(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Del")
+		panic("no return value specified for BackgroundSave")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, key)
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -39,6 +45,127 @@ func (_m *StorageLayer) Del(ctx context.Context, key string) error {
 	return r0
 }
 
+// StorageLayer_BackgroundSave_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BackgroundSave'
+type StorageLayer_BackgroundSave_Call struct {
+	*mock.Call
+}
+
+// BackgroundSave is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *StorageLayer_Expecter) BackgroundSave(ctx interface{}) *StorageLayer_BackgroundSave_Call {
+	return &StorageLayer_BackgroundSave_Call{Call: _e.mock.On("BackgroundSave", ctx)}
+}
+
+func (_c *StorageLayer_BackgroundSave_Call) Run(run func(ctx context.Context)) *StorageLayer_BackgroundSave_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_BackgroundSave_Call) Return(_a0 error) *StorageLayer_BackgroundSave_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *StorageLayer_BackgroundSave_Call) RunAndReturn(run func(context.Context) error) *StorageLayer_BackgroundSave_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginRead provides a mock function with given fields: ctx
+func (_m *StorageLayer) BeginRead(ctx context.Context) (*storage.ReadTx, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BeginRead")
+	}
+
+	var r0 *storage.ReadTx
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*storage.ReadTx, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *storage.ReadTx); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.ReadTx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_BeginRead_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginRead'
+type StorageLayer_BeginRead_Call struct {
+	*mock.Call
+}
+
+// BeginRead is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *StorageLayer_Expecter) BeginRead(ctx interface{}) *StorageLayer_BeginRead_Call {
+	return &StorageLayer_BeginRead_Call{Call: _e.mock.On("BeginRead", ctx)}
+}
+
+func (_c *StorageLayer_BeginRead_Call) Run(run func(ctx context.Context)) *StorageLayer_BeginRead_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_BeginRead_Call) Return(_a0 *storage.ReadTx, _a1 error) *StorageLayer_BeginRead_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_BeginRead_Call) RunAndReturn(run func(context.Context) (*storage.ReadTx, error)) *StorageLayer_BeginRead_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Del provides a mock function with given fields: ctx, keys
+func (_m *StorageLayer) Del(ctx context.Context, keys ...string) (int, error) {
+	_va := make([]interface{}, len(keys))
+	for _i := range keys {
+		_va[_i] = keys[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Del")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) (int, error)); ok {
+		return rf(ctx, keys...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) int); ok {
+		r0 = rf(ctx, keys...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) error); ok {
+		r1 = rf(ctx, keys...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StorageLayer_Del_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Del'
 type StorageLayer_Del_Call struct {
 	*mock.Call
@@ -46,30 +173,95 @@ type StorageLayer_Del_Call struct {
 
 // Del is a helper method to define mock.On call
 //   - ctx context.Context
+//   - keys ...string
+func (_e *StorageLayer_Expecter) Del(ctx interface{}, keys ...interface{}) *StorageLayer_Del_Call {
+	return &StorageLayer_Del_Call{Call: _e.mock.On("Del",
+		append([]interface{}{ctx}, keys...)...)}
+}
+
+func (_c *StorageLayer_Del_Call) Run(run func(ctx context.Context, keys ...string)) *StorageLayer_Del_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Del_Call) Return(_a0 int, _a1 error) *StorageLayer_Del_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Del_Call) RunAndReturn(run func(context.Context, ...string) (int, error)) *StorageLayer_Del_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Expire provides a mock function with given fields: ctx, key, ttl
+func (_m *StorageLayer) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Expire")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) bool); ok {
+		r0 = rf(ctx, key, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, key, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Expire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Expire'
+type StorageLayer_Expire_Call struct {
+	*mock.Call
+}
+
+// Expire is a helper method to define mock.On call
+//   - ctx context.Context
 //   - key string
-func (_e *StorageLayer_Expecter) Del(ctx interface{}, key interface{}) *StorageLayer_Del_Call {
-	return &StorageLayer_Del_Call{Call: _e.mock.On("Del", ctx, key)}
+//   - ttl time.Duration
+func (_e *StorageLayer_Expecter) Expire(ctx interface{}, key interface{}, ttl interface{}) *StorageLayer_Expire_Call {
+	return &StorageLayer_Expire_Call{Call: _e.mock.On("Expire", ctx, key, ttl)}
 }
 
-func (_c *StorageLayer_Del_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_Del_Call {
+func (_c *StorageLayer_Expire_Call) Run(run func(ctx context.Context, key string, ttl time.Duration)) *StorageLayer_Expire_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
 	})
 	return _c
 }
 
-func (_c *StorageLayer_Del_Call) Return(_a0 error) *StorageLayer_Del_Call {
-	_c.Call.Return(_a0)
+func (_c *StorageLayer_Expire_Call) Return(_a0 bool, _a1 error) *StorageLayer_Expire_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *StorageLayer_Del_Call) RunAndReturn(run func(context.Context, string) error) *StorageLayer_Del_Call {
+func (_c *StorageLayer_Expire_Call) RunAndReturn(run func(context.Context, string, time.Duration) (bool, error)) *StorageLayer_Expire_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Get provides a mock function with given fields: ctx, key
-func (_m *StorageLayer) Get(ctx context.Context, key string) (string, error) {
+func (_m *StorageLayer) Get(ctx context.Context, key string) (string, bool, error) {
 	ret := _m.Called(ctx, key)
 
 	if len(ret) == 0 {
@@ -77,8 +269,9 @@ func (_m *StorageLayer) Get(ctx context.Context, key string) (string, error) {
 	}
 
 	var r0 string
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, bool, error)); ok {
 		return rf(ctx, key)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
@@ -87,13 +280,19 @@ func (_m *StorageLayer) Get(ctx context.Context, key string) (string, error) {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string) bool); ok {
 		r1 = rf(ctx, key)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(bool)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // StorageLayer_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
@@ -115,60 +314,2319 @@ func (_c *StorageLayer_Get_Call) Run(run func(ctx context.Context, key string))
 	return _c
 }
 
-func (_c *StorageLayer_Get_Call) Return(_a0 string, _a1 error) *StorageLayer_Get_Call {
+func (_c *StorageLayer_Get_Call) Return(_a0 string, _a1 bool, _a2 error) *StorageLayer_Get_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_Get_Call) RunAndReturn(run func(context.Context, string) (string, bool, error)) *StorageLayer_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HDel provides a mock function with given fields: ctx, key, fields
+func (_m *StorageLayer) HDel(ctx context.Context, key string, fields ...string) (int, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HDel")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (int, error)); ok {
+		return rf(ctx, key, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int); ok {
+		r0 = rf(ctx, key, fields...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, key, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_HDel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HDel'
+type StorageLayer_HDel_Call struct {
+	*mock.Call
+}
+
+// HDel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - fields ...string
+func (_e *StorageLayer_Expecter) HDel(ctx interface{}, key interface{}, fields ...interface{}) *StorageLayer_HDel_Call {
+	return &StorageLayer_HDel_Call{Call: _e.mock.On("HDel",
+		append([]interface{}{ctx, key}, fields...)...)}
+}
+
+func (_c *StorageLayer_HDel_Call) Run(run func(ctx context.Context, key string, fields ...string)) *StorageLayer_HDel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HDel_Call) Return(_a0 int, _a1 error) *StorageLayer_HDel_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *StorageLayer_Get_Call) RunAndReturn(run func(context.Context, string) (string, error)) *StorageLayer_Get_Call {
+func (_c *StorageLayer_HDel_Call) RunAndReturn(run func(context.Context, string, ...string) (int, error)) *StorageLayer_HDel_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Set provides a mock function with given fields: ctx, key, value
-func (_m *StorageLayer) Set(ctx context.Context, key string, value string) error {
-	ret := _m.Called(ctx, key, value)
+// HExists provides a mock function with given fields: ctx, key, field
+func (_m *StorageLayer) HExists(ctx context.Context, key string, field string) (bool, error) {
+	ret := _m.Called(ctx, key, field)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Set")
+		panic("no return value specified for HExists")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, key, value)
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, key, field)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, key, field)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, key, field)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// StorageLayer_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
-type StorageLayer_Set_Call struct {
+// StorageLayer_HExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HExists'
+type StorageLayer_HExists_Call struct {
 	*mock.Call
 }
 
-// Set is a helper method to define mock.On call
+// HExists is a helper method to define mock.On call
 //   - ctx context.Context
 //   - key string
-//   - value string
-func (_e *StorageLayer_Expecter) Set(ctx interface{}, key interface{}, value interface{}) *StorageLayer_Set_Call {
-	return &StorageLayer_Set_Call{Call: _e.mock.On("Set", ctx, key, value)}
+//   - field string
+func (_e *StorageLayer_Expecter) HExists(ctx interface{}, key interface{}, field interface{}) *StorageLayer_HExists_Call {
+	return &StorageLayer_HExists_Call{Call: _e.mock.On("HExists", ctx, key, field)}
 }
 
-func (_c *StorageLayer_Set_Call) Run(run func(ctx context.Context, key string, value string)) *StorageLayer_Set_Call {
+func (_c *StorageLayer_HExists_Call) Run(run func(ctx context.Context, key string, field string)) *StorageLayer_HExists_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *StorageLayer_Set_Call) Return(_a0 error) *StorageLayer_Set_Call {
-	_c.Call.Return(_a0)
+func (_c *StorageLayer_HExists_Call) Return(_a0 bool, _a1 error) *StorageLayer_HExists_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *StorageLayer_Set_Call) RunAndReturn(run func(context.Context, string, string) error) *StorageLayer_Set_Call {
+func (_c *StorageLayer_HExists_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *StorageLayer_HExists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HGet provides a mock function with given fields: ctx, key, fields
+func (_m *StorageLayer) HGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HGet")
+	}
+
+	var r0 []string
+	var r1 []bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) ([]string, []bool, error)); ok {
+		return rf(ctx, key, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) []string); ok {
+		r0 = rf(ctx, key, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) []bool); ok {
+		r1 = rf(ctx, key, fields...)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]bool)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ...string) error); ok {
+		r2 = rf(ctx, key, fields...)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_HGet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HGet'
+type StorageLayer_HGet_Call struct {
+	*mock.Call
+}
+
+// HGet is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - fields ...string
+func (_e *StorageLayer_Expecter) HGet(ctx interface{}, key interface{}, fields ...interface{}) *StorageLayer_HGet_Call {
+	return &StorageLayer_HGet_Call{Call: _e.mock.On("HGet",
+		append([]interface{}{ctx, key}, fields...)...)}
+}
+
+func (_c *StorageLayer_HGet_Call) Run(run func(ctx context.Context, key string, fields ...string)) *StorageLayer_HGet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HGet_Call) Return(_a0 []string, _a1 []bool, _a2 error) *StorageLayer_HGet_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_HGet_Call) RunAndReturn(run func(context.Context, string, ...string) ([]string, []bool, error)) *StorageLayer_HGet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HGetAll provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) HGetAll(ctx context.Context, key string) ([]string, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HGetAll")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_HGetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HGetAll'
+type StorageLayer_HGetAll_Call struct {
+	*mock.Call
+}
+
+// HGetAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) HGetAll(ctx interface{}, key interface{}) *StorageLayer_HGetAll_Call {
+	return &StorageLayer_HGetAll_Call{Call: _e.mock.On("HGetAll", ctx, key)}
+}
+
+func (_c *StorageLayer_HGetAll_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_HGetAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HGetAll_Call) Return(_a0 []string, _a1 error) *StorageLayer_HGetAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_HGetAll_Call) RunAndReturn(run func(context.Context, string) ([]string, error)) *StorageLayer_HGetAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HIncrBy provides a mock function with given fields: ctx, key, field, delta
+func (_m *StorageLayer) HIncrBy(ctx context.Context, key string, field string, delta int64) (int64, error) {
+	ret := _m.Called(ctx, key, field, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HIncrBy")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) (int64, error)); ok {
+		return rf(ctx, key, field, delta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) int64); ok {
+		r0 = rf(ctx, key, field, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = rf(ctx, key, field, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_HIncrBy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HIncrBy'
+type StorageLayer_HIncrBy_Call struct {
+	*mock.Call
+}
+
+// HIncrBy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - field string
+//   - delta int64
+func (_e *StorageLayer_Expecter) HIncrBy(ctx interface{}, key interface{}, field interface{}, delta interface{}) *StorageLayer_HIncrBy_Call {
+	return &StorageLayer_HIncrBy_Call{Call: _e.mock.On("HIncrBy", ctx, key, field, delta)}
+}
+
+func (_c *StorageLayer_HIncrBy_Call) Run(run func(ctx context.Context, key string, field string, delta int64)) *StorageLayer_HIncrBy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HIncrBy_Call) Return(_a0 int64, _a1 error) *StorageLayer_HIncrBy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_HIncrBy_Call) RunAndReturn(run func(context.Context, string, string, int64) (int64, error)) *StorageLayer_HIncrBy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HLen provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) HLen(ctx context.Context, key string) (int, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HLen")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_HLen_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HLen'
+type StorageLayer_HLen_Call struct {
+	*mock.Call
+}
+
+// HLen is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) HLen(ctx interface{}, key interface{}) *StorageLayer_HLen_Call {
+	return &StorageLayer_HLen_Call{Call: _e.mock.On("HLen", ctx, key)}
+}
+
+func (_c *StorageLayer_HLen_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_HLen_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HLen_Call) Return(_a0 int, _a1 error) *StorageLayer_HLen_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_HLen_Call) RunAndReturn(run func(context.Context, string) (int, error)) *StorageLayer_HLen_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HSet provides a mock function with given fields: ctx, key, pairs
+func (_m *StorageLayer) HSet(ctx context.Context, key string, pairs ...string) (int, error) {
+	_va := make([]interface{}, len(pairs))
+	for _i := range pairs {
+		_va[_i] = pairs[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HSet")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (int, error)); ok {
+		return rf(ctx, key, pairs...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int); ok {
+		r0 = rf(ctx, key, pairs...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, key, pairs...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_HSet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HSet'
+type StorageLayer_HSet_Call struct {
+	*mock.Call
+}
+
+// HSet is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - pairs ...string
+func (_e *StorageLayer_Expecter) HSet(ctx interface{}, key interface{}, pairs ...interface{}) *StorageLayer_HSet_Call {
+	return &StorageLayer_HSet_Call{Call: _e.mock.On("HSet",
+		append([]interface{}{ctx, key}, pairs...)...)}
+}
+
+func (_c *StorageLayer_HSet_Call) Run(run func(ctx context.Context, key string, pairs ...string)) *StorageLayer_HSet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_HSet_Call) Return(_a0 int, _a1 error) *StorageLayer_HSet_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_HSet_Call) RunAndReturn(run func(context.Context, string, ...string) (int, error)) *StorageLayer_HSet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrBy provides a mock function with given fields: ctx, key, delta
+func (_m *StorageLayer) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
+	ret := _m.Called(ctx, key, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrBy")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (int64, error)); ok {
+		return rf(ctx, key, delta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, key, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, key, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_IncrBy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrBy'
+type StorageLayer_IncrBy_Call struct {
+	*mock.Call
+}
+
+// IncrBy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - delta int64
+func (_e *StorageLayer_Expecter) IncrBy(ctx interface{}, key interface{}, delta interface{}) *StorageLayer_IncrBy_Call {
+	return &StorageLayer_IncrBy_Call{Call: _e.mock.On("IncrBy", ctx, key, delta)}
+}
+
+func (_c *StorageLayer_IncrBy_Call) Run(run func(ctx context.Context, key string, delta int64)) *StorageLayer_IncrBy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_IncrBy_Call) Return(_a0 int64, _a1 error) *StorageLayer_IncrBy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_IncrBy_Call) RunAndReturn(run func(context.Context, string, int64) (int64, error)) *StorageLayer_IncrBy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrByFloat provides a mock function with given fields: ctx, key, delta
+func (_m *StorageLayer) IncrByFloat(ctx context.Context, key string, delta float64) (string, error) {
+	ret := _m.Called(ctx, key, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrByFloat")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) (string, error)); ok {
+		return rf(ctx, key, delta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) string); ok {
+		r0 = rf(ctx, key, delta)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) error); ok {
+		r1 = rf(ctx, key, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_IncrByFloat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrByFloat'
+type StorageLayer_IncrByFloat_Call struct {
+	*mock.Call
+}
+
+// IncrByFloat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - delta float64
+func (_e *StorageLayer_Expecter) IncrByFloat(ctx interface{}, key interface{}, delta interface{}) *StorageLayer_IncrByFloat_Call {
+	return &StorageLayer_IncrByFloat_Call{Call: _e.mock.On("IncrByFloat", ctx, key, delta)}
+}
+
+func (_c *StorageLayer_IncrByFloat_Call) Run(run func(ctx context.Context, key string, delta float64)) *StorageLayer_IncrByFloat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(float64))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_IncrByFloat_Call) Return(_a0 string, _a1 error) *StorageLayer_IncrByFloat_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_IncrByFloat_Call) RunAndReturn(run func(context.Context, string, float64) (string, error)) *StorageLayer_IncrByFloat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Keys provides a mock function with given fields: ctx, pattern
+func (_m *StorageLayer) Keys(ctx context.Context, pattern string) ([]string, error) {
+	ret := _m.Called(ctx, pattern)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Keys")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, pattern)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, pattern)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Keys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Keys'
+type StorageLayer_Keys_Call struct {
+	*mock.Call
+}
+
+// Keys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pattern string
+func (_e *StorageLayer_Expecter) Keys(ctx interface{}, pattern interface{}) *StorageLayer_Keys_Call {
+	return &StorageLayer_Keys_Call{Call: _e.mock.On("Keys", ctx, pattern)}
+}
+
+func (_c *StorageLayer_Keys_Call) Run(run func(ctx context.Context, pattern string)) *StorageLayer_Keys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Keys_Call) Return(_a0 []string, _a1 error) *StorageLayer_Keys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Keys_Call) RunAndReturn(run func(context.Context, string) ([]string, error)) *StorageLayer_Keys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListIndex provides a mock function with given fields: ctx, key, index
+func (_m *StorageLayer) ListIndex(ctx context.Context, key string, index int) (string, bool, error) {
+	ret := _m.Called(ctx, key, index)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIndex")
+	}
+
+	var r0 string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (string, bool, error)); ok {
+		return rf(ctx, key, index)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) string); ok {
+		r0 = rf(ctx, key, index)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) bool); ok {
+		r1 = rf(ctx, key, index)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int) error); ok {
+		r2 = rf(ctx, key, index)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_ListIndex_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListIndex'
+type StorageLayer_ListIndex_Call struct {
+	*mock.Call
+}
+
+// ListIndex is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - index int
+func (_e *StorageLayer_Expecter) ListIndex(ctx interface{}, key interface{}, index interface{}) *StorageLayer_ListIndex_Call {
+	return &StorageLayer_ListIndex_Call{Call: _e.mock.On("ListIndex", ctx, key, index)}
+}
+
+func (_c *StorageLayer_ListIndex_Call) Run(run func(ctx context.Context, key string, index int)) *StorageLayer_ListIndex_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ListIndex_Call) Return(_a0 string, _a1 bool, _a2 error) *StorageLayer_ListIndex_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_ListIndex_Call) RunAndReturn(run func(context.Context, string, int) (string, bool, error)) *StorageLayer_ListIndex_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListLen provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) ListLen(ctx context.Context, key string) (int, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLen")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ListLen_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListLen'
+type StorageLayer_ListLen_Call struct {
+	*mock.Call
+}
+
+// ListLen is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) ListLen(ctx interface{}, key interface{}) *StorageLayer_ListLen_Call {
+	return &StorageLayer_ListLen_Call{Call: _e.mock.On("ListLen", ctx, key)}
+}
+
+func (_c *StorageLayer_ListLen_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_ListLen_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ListLen_Call) Return(_a0 int, _a1 error) *StorageLayer_ListLen_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ListLen_Call) RunAndReturn(run func(context.Context, string) (int, error)) *StorageLayer_ListLen_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRange provides a mock function with given fields: ctx, key, start, stop
+func (_m *StorageLayer) ListRange(ctx context.Context, key string, start int, stop int) ([]string, error) {
+	ret := _m.Called(ctx, key, start, stop)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRange")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]string, error)); ok {
+		return rf(ctx, key, start, stop)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []string); ok {
+		r0 = rf(ctx, key, start, stop)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, key, start, stop)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ListRange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRange'
+type StorageLayer_ListRange_Call struct {
+	*mock.Call
+}
+
+// ListRange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - start int
+//   - stop int
+func (_e *StorageLayer_Expecter) ListRange(ctx interface{}, key interface{}, start interface{}, stop interface{}) *StorageLayer_ListRange_Call {
+	return &StorageLayer_ListRange_Call{Call: _e.mock.On("ListRange", ctx, key, start, stop)}
+}
+
+func (_c *StorageLayer_ListRange_Call) Run(run func(ctx context.Context, key string, start int, stop int)) *StorageLayer_ListRange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ListRange_Call) Return(_a0 []string, _a1 error) *StorageLayer_ListRange_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ListRange_Call) RunAndReturn(run func(context.Context, string, int, int) ([]string, error)) *StorageLayer_ListRange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTrim provides a mock function with given fields: ctx, key, start, stop
+func (_m *StorageLayer) ListTrim(ctx context.Context, key string, start int, stop int) error {
+	ret := _m.Called(ctx, key, start, stop)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTrim")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) error); ok {
+		r0 = rf(ctx, key, start, stop)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorageLayer_ListTrim_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTrim'
+type StorageLayer_ListTrim_Call struct {
+	*mock.Call
+}
+
+// ListTrim is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - start int
+//   - stop int
+func (_e *StorageLayer_Expecter) ListTrim(ctx interface{}, key interface{}, start interface{}, stop interface{}) *StorageLayer_ListTrim_Call {
+	return &StorageLayer_ListTrim_Call{Call: _e.mock.On("ListTrim", ctx, key, start, stop)}
+}
+
+func (_c *StorageLayer_ListTrim_Call) Run(run func(ctx context.Context, key string, start int, stop int)) *StorageLayer_ListTrim_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ListTrim_Call) Return(_a0 error) *StorageLayer_ListTrim_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *StorageLayer_ListTrim_Call) RunAndReturn(run func(context.Context, string, int, int) error) *StorageLayer_ListTrim_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Persist provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) Persist(ctx context.Context, key string) (bool, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Persist")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Persist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Persist'
+type StorageLayer_Persist_Call struct {
+	*mock.Call
+}
+
+// Persist is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) Persist(ctx interface{}, key interface{}) *StorageLayer_Persist_Call {
+	return &StorageLayer_Persist_Call{Call: _e.mock.On("Persist", ctx, key)}
+}
+
+func (_c *StorageLayer_Persist_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_Persist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Persist_Call) Return(_a0 bool, _a1 error) *StorageLayer_Persist_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Persist_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *StorageLayer_Persist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pop provides a mock function with given fields: ctx, key, side
+func (_m *StorageLayer) Pop(ctx context.Context, key string, side engine.Side) (string, bool, error) {
+	ret := _m.Called(ctx, key, side)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pop")
+	}
+
+	var r0 string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.Side) (string, bool, error)); ok {
+		return rf(ctx, key, side)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.Side) string); ok {
+		r0 = rf(ctx, key, side)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, engine.Side) bool); ok {
+		r1 = rf(ctx, key, side)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, engine.Side) error); ok {
+		r2 = rf(ctx, key, side)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_Pop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pop'
+type StorageLayer_Pop_Call struct {
+	*mock.Call
+}
+
+// Pop is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - side engine.Side
+func (_e *StorageLayer_Expecter) Pop(ctx interface{}, key interface{}, side interface{}) *StorageLayer_Pop_Call {
+	return &StorageLayer_Pop_Call{Call: _e.mock.On("Pop", ctx, key, side)}
+}
+
+func (_c *StorageLayer_Pop_Call) Run(run func(ctx context.Context, key string, side engine.Side)) *StorageLayer_Pop_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(engine.Side))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Pop_Call) Return(_a0 string, _a1 bool, _a2 error) *StorageLayer_Pop_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_Pop_Call) RunAndReturn(run func(context.Context, string, engine.Side) (string, bool, error)) *StorageLayer_Pop_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Prefix provides a mock function with given fields: ctx, prefix, limit
+func (_m *StorageLayer) Prefix(ctx context.Context, prefix string, limit int) (engine.Iterator, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Prefix")
+	}
+
+	var r0 engine.Iterator
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (engine.Iterator, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) engine.Iterator); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(engine.Iterator)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Prefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Prefix'
+type StorageLayer_Prefix_Call struct {
+	*mock.Call
+}
+
+// Prefix is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *StorageLayer_Expecter) Prefix(ctx interface{}, prefix interface{}, limit interface{}) *StorageLayer_Prefix_Call {
+	return &StorageLayer_Prefix_Call{Call: _e.mock.On("Prefix", ctx, prefix, limit)}
+}
+
+func (_c *StorageLayer_Prefix_Call) Run(run func(ctx context.Context, prefix string, limit int)) *StorageLayer_Prefix_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Prefix_Call) Return(_a0 engine.Iterator, _a1 error) *StorageLayer_Prefix_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Prefix_Call) RunAndReturn(run func(context.Context, string, int) (engine.Iterator, error)) *StorageLayer_Prefix_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Push provides a mock function with given fields: ctx, key, side, values
+func (_m *StorageLayer) Push(ctx context.Context, key string, side engine.Side, values ...string) (int, error) {
+	_va := make([]interface{}, len(values))
+	for _i := range values {
+		_va[_i] = values[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key, side)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Push")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.Side, ...string) (int, error)); ok {
+		return rf(ctx, key, side, values...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.Side, ...string) int); ok {
+		r0 = rf(ctx, key, side, values...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, engine.Side, ...string) error); ok {
+		r1 = rf(ctx, key, side, values...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Push_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Push'
+type StorageLayer_Push_Call struct {
+	*mock.Call
+}
+
+// Push is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - side engine.Side
+//   - values ...string
+func (_e *StorageLayer_Expecter) Push(ctx interface{}, key interface{}, side interface{}, values ...interface{}) *StorageLayer_Push_Call {
+	return &StorageLayer_Push_Call{Call: _e.mock.On("Push",
+		append([]interface{}{ctx, key, side}, values...)...)}
+}
+
+func (_c *StorageLayer_Push_Call) Run(run func(ctx context.Context, key string, side engine.Side, values ...string)) *StorageLayer_Push_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].(engine.Side), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Push_Call) Return(_a0 int, _a1 error) *StorageLayer_Push_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Push_Call) RunAndReturn(run func(context.Context, string, engine.Side, ...string) (int, error)) *StorageLayer_Push_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Range provides a mock function with given fields: ctx, start, end, limit
+func (_m *StorageLayer) Range(ctx context.Context, start string, end string, limit int) (engine.Iterator, error) {
+	ret := _m.Called(ctx, start, end, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Range")
+	}
+
+	var r0 engine.Iterator
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (engine.Iterator, error)); ok {
+		return rf(ctx, start, end, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) engine.Iterator); ok {
+		r0 = rf(ctx, start, end, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(engine.Iterator)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, start, end, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_Range_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Range'
+type StorageLayer_Range_Call struct {
+	*mock.Call
+}
+
+// Range is a helper method to define mock.On call
+//   - ctx context.Context
+//   - start string
+//   - end string
+//   - limit int
+func (_e *StorageLayer_Expecter) Range(ctx interface{}, start interface{}, end interface{}, limit interface{}) *StorageLayer_Range_Call {
+	return &StorageLayer_Range_Call{Call: _e.mock.On("Range", ctx, start, end, limit)}
+}
+
+func (_c *StorageLayer_Range_Call) Run(run func(ctx context.Context, start string, end string, limit int)) *StorageLayer_Range_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Range_Call) Return(_a0 engine.Iterator, _a1 error) *StorageLayer_Range_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_Range_Call) RunAndReturn(run func(context.Context, string, string, int) (engine.Iterator, error)) *StorageLayer_Range_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SAdd provides a mock function with given fields: ctx, key, members
+func (_m *StorageLayer) SAdd(ctx context.Context, key string, members ...string) (int, error) {
+	_va := make([]interface{}, len(members))
+	for _i := range members {
+		_va[_i] = members[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SAdd")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (int, error)); ok {
+		return rf(ctx, key, members...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int); ok {
+		r0 = rf(ctx, key, members...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, key, members...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SAdd_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SAdd'
+type StorageLayer_SAdd_Call struct {
+	*mock.Call
+}
+
+// SAdd is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - members ...string
+func (_e *StorageLayer_Expecter) SAdd(ctx interface{}, key interface{}, members ...interface{}) *StorageLayer_SAdd_Call {
+	return &StorageLayer_SAdd_Call{Call: _e.mock.On("SAdd",
+		append([]interface{}{ctx, key}, members...)...)}
+}
+
+func (_c *StorageLayer_SAdd_Call) Run(run func(ctx context.Context, key string, members ...string)) *StorageLayer_SAdd_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SAdd_Call) Return(_a0 int, _a1 error) *StorageLayer_SAdd_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SAdd_Call) RunAndReturn(run func(context.Context, string, ...string) (int, error)) *StorageLayer_SAdd_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SCard provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) SCard(ctx context.Context, key string) (int, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SCard")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SCard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SCard'
+type StorageLayer_SCard_Call struct {
+	*mock.Call
+}
+
+// SCard is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) SCard(ctx interface{}, key interface{}) *StorageLayer_SCard_Call {
+	return &StorageLayer_SCard_Call{Call: _e.mock.On("SCard", ctx, key)}
+}
+
+func (_c *StorageLayer_SCard_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_SCard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SCard_Call) Return(_a0 int, _a1 error) *StorageLayer_SCard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SCard_Call) RunAndReturn(run func(context.Context, string) (int, error)) *StorageLayer_SCard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SCombine provides a mock function with given fields: ctx, op, keys
+func (_m *StorageLayer) SCombine(ctx context.Context, op engine.SetOp, keys ...string) ([]string, error) {
+	_va := make([]interface{}, len(keys))
+	for _i := range keys {
+		_va[_i] = keys[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, op)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SCombine")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, engine.SetOp, ...string) ([]string, error)); ok {
+		return rf(ctx, op, keys...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, engine.SetOp, ...string) []string); ok {
+		r0 = rf(ctx, op, keys...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, engine.SetOp, ...string) error); ok {
+		r1 = rf(ctx, op, keys...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SCombine_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SCombine'
+type StorageLayer_SCombine_Call struct {
+	*mock.Call
+}
+
+// SCombine is a helper method to define mock.On call
+//   - ctx context.Context
+//   - op engine.SetOp
+//   - keys ...string
+func (_e *StorageLayer_Expecter) SCombine(ctx interface{}, op interface{}, keys ...interface{}) *StorageLayer_SCombine_Call {
+	return &StorageLayer_SCombine_Call{Call: _e.mock.On("SCombine",
+		append([]interface{}{ctx, op}, keys...)...)}
+}
+
+func (_c *StorageLayer_SCombine_Call) Run(run func(ctx context.Context, op engine.SetOp, keys ...string)) *StorageLayer_SCombine_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(engine.SetOp), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SCombine_Call) Return(_a0 []string, _a1 error) *StorageLayer_SCombine_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SCombine_Call) RunAndReturn(run func(context.Context, engine.SetOp, ...string) ([]string, error)) *StorageLayer_SCombine_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SIsMember provides a mock function with given fields: ctx, key, member
+func (_m *StorageLayer) SIsMember(ctx context.Context, key string, member string) (bool, error) {
+	ret := _m.Called(ctx, key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SIsMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, key, member)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, key, member)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, key, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SIsMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SIsMember'
+type StorageLayer_SIsMember_Call struct {
+	*mock.Call
+}
+
+// SIsMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - member string
+func (_e *StorageLayer_Expecter) SIsMember(ctx interface{}, key interface{}, member interface{}) *StorageLayer_SIsMember_Call {
+	return &StorageLayer_SIsMember_Call{Call: _e.mock.On("SIsMember", ctx, key, member)}
+}
+
+func (_c *StorageLayer_SIsMember_Call) Run(run func(ctx context.Context, key string, member string)) *StorageLayer_SIsMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SIsMember_Call) Return(_a0 bool, _a1 error) *StorageLayer_SIsMember_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SIsMember_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *StorageLayer_SIsMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SMembers provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) SMembers(ctx context.Context, key string) ([]string, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SMembers")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SMembers'
+type StorageLayer_SMembers_Call struct {
+	*mock.Call
+}
+
+// SMembers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) SMembers(ctx interface{}, key interface{}) *StorageLayer_SMembers_Call {
+	return &StorageLayer_SMembers_Call{Call: _e.mock.On("SMembers", ctx, key)}
+}
+
+func (_c *StorageLayer_SMembers_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_SMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SMembers_Call) Return(_a0 []string, _a1 error) *StorageLayer_SMembers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SMembers_Call) RunAndReturn(run func(context.Context, string) ([]string, error)) *StorageLayer_SMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SRem provides a mock function with given fields: ctx, key, members
+func (_m *StorageLayer) SRem(ctx context.Context, key string, members ...string) (int, error) {
+	_va := make([]interface{}, len(members))
+	for _i := range members {
+		_va[_i] = members[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SRem")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (int, error)); ok {
+		return rf(ctx, key, members...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int); ok {
+		r0 = rf(ctx, key, members...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, key, members...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_SRem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SRem'
+type StorageLayer_SRem_Call struct {
+	*mock.Call
+}
+
+// SRem is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - members ...string
+func (_e *StorageLayer_Expecter) SRem(ctx interface{}, key interface{}, members ...interface{}) *StorageLayer_SRem_Call {
+	return &StorageLayer_SRem_Call{Call: _e.mock.On("SRem",
+		append([]interface{}{ctx, key}, members...)...)}
+}
+
+func (_c *StorageLayer_SRem_Call) Run(run func(ctx context.Context, key string, members ...string)) *StorageLayer_SRem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SRem_Call) Return(_a0 int, _a1 error) *StorageLayer_SRem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_SRem_Call) RunAndReturn(run func(context.Context, string, ...string) (int, error)) *StorageLayer_SRem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx
+func (_m *StorageLayer) Save(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorageLayer_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type StorageLayer_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *StorageLayer_Expecter) Save(ctx interface{}) *StorageLayer_Save_Call {
+	return &StorageLayer_Save_Call{Call: _e.mock.On("Save", ctx)}
+}
+
+func (_c *StorageLayer_Save_Call) Run(run func(ctx context.Context)) *StorageLayer_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Save_Call) Return(_a0 error) *StorageLayer_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *StorageLayer_Save_Call) RunAndReturn(run func(context.Context) error) *StorageLayer_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Scan provides a mock function with given fields: ctx, cursor, pattern, count
+func (_m *StorageLayer) Scan(ctx context.Context, cursor string, pattern string, count int) ([]string, string, error) {
+	ret := _m.Called(ctx, cursor, pattern, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scan")
+	}
+
+	var r0 []string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]string, string, error)); ok {
+		return rf(ctx, cursor, pattern, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []string); ok {
+		r0 = rf(ctx, cursor, pattern, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) string); ok {
+		r1 = rf(ctx, cursor, pattern, count)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int) error); ok {
+		r2 = rf(ctx, cursor, pattern, count)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_Scan_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Scan'
+type StorageLayer_Scan_Call struct {
+	*mock.Call
+}
+
+// Scan is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cursor string
+//   - pattern string
+//   - count int
+func (_e *StorageLayer_Expecter) Scan(ctx interface{}, cursor interface{}, pattern interface{}, count interface{}) *StorageLayer_Scan_Call {
+	return &StorageLayer_Scan_Call{Call: _e.mock.On("Scan", ctx, cursor, pattern, count)}
+}
+
+func (_c *StorageLayer_Scan_Call) Run(run func(ctx context.Context, cursor string, pattern string, count int)) *StorageLayer_Scan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Scan_Call) Return(_a0 []string, _a1 string, _a2 error) *StorageLayer_Scan_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_Scan_Call) RunAndReturn(run func(context.Context, string, string, int) ([]string, string, error)) *StorageLayer_Scan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Set provides a mock function with given fields: ctx, key, value
+func (_m *StorageLayer) Set(ctx context.Context, key string, value string) error {
+	ret := _m.Called(ctx, key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, key, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorageLayer_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type StorageLayer_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - value string
+func (_e *StorageLayer_Expecter) Set(ctx interface{}, key interface{}, value interface{}) *StorageLayer_Set_Call {
+	return &StorageLayer_Set_Call{Call: _e.mock.On("Set", ctx, key, value)}
+}
+
+func (_c *StorageLayer_Set_Call) Run(run func(ctx context.Context, key string, value string)) *StorageLayer_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_Set_Call) Return(_a0 error) *StorageLayer_Set_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *StorageLayer_Set_Call) RunAndReturn(run func(context.Context, string, string) error) *StorageLayer_Set_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetWithTTL provides a mock function with given fields: ctx, key, value, ttl
+func (_m *StorageLayer) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	ret := _m.Called(ctx, key, value, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWithTTL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) error); ok {
+		r0 = rf(ctx, key, value, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorageLayer_SetWithTTL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWithTTL'
+type StorageLayer_SetWithTTL_Call struct {
+	*mock.Call
+}
+
+// SetWithTTL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - value string
+//   - ttl time.Duration
+func (_e *StorageLayer_Expecter) SetWithTTL(ctx interface{}, key interface{}, value interface{}, ttl interface{}) *StorageLayer_SetWithTTL_Call {
+	return &StorageLayer_SetWithTTL_Call{Call: _e.mock.On("SetWithTTL", ctx, key, value, ttl)}
+}
+
+func (_c *StorageLayer_SetWithTTL_Call) Run(run func(ctx context.Context, key string, value string, ttl time.Duration)) *StorageLayer_SetWithTTL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_SetWithTTL_Call) Return(_a0 error) *StorageLayer_SetWithTTL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *StorageLayer_SetWithTTL_Call) RunAndReturn(run func(context.Context, string, string, time.Duration) error) *StorageLayer_SetWithTTL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TTL provides a mock function with given fields: ctx, key
+func (_m *StorageLayer) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TTL")
+	}
+
+	var r0 time.Duration
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (time.Duration, bool, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) time.Duration); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) bool); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_TTL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TTL'
+type StorageLayer_TTL_Call struct {
+	*mock.Call
+}
+
+// TTL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *StorageLayer_Expecter) TTL(ctx interface{}, key interface{}) *StorageLayer_TTL_Call {
+	return &StorageLayer_TTL_Call{Call: _e.mock.On("TTL", ctx, key)}
+}
+
+func (_c *StorageLayer_TTL_Call) Run(run func(ctx context.Context, key string)) *StorageLayer_TTL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_TTL_Call) Return(_a0 time.Duration, _a1 bool, _a2 error) *StorageLayer_TTL_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_TTL_Call) RunAndReturn(run func(context.Context, string) (time.Duration, bool, error)) *StorageLayer_TTL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZAdd provides a mock function with given fields: ctx, key, members
+func (_m *StorageLayer) ZAdd(ctx context.Context, key string, members ...engine.ScoredMember) (int, error) {
+	_va := make([]interface{}, len(members))
+	for _i := range members {
+		_va[_i] = members[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZAdd")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...engine.ScoredMember) (int, error)); ok {
+		return rf(ctx, key, members...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...engine.ScoredMember) int); ok {
+		r0 = rf(ctx, key, members...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...engine.ScoredMember) error); ok {
+		r1 = rf(ctx, key, members...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ZAdd_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZAdd'
+type StorageLayer_ZAdd_Call struct {
+	*mock.Call
+}
+
+// ZAdd is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - members ...engine.ScoredMember
+func (_e *StorageLayer_Expecter) ZAdd(ctx interface{}, key interface{}, members ...interface{}) *StorageLayer_ZAdd_Call {
+	return &StorageLayer_ZAdd_Call{Call: _e.mock.On("ZAdd",
+		append([]interface{}{ctx, key}, members...)...)}
+}
+
+func (_c *StorageLayer_ZAdd_Call) Run(run func(ctx context.Context, key string, members ...engine.ScoredMember)) *StorageLayer_ZAdd_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]engine.ScoredMember, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(engine.ScoredMember)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZAdd_Call) Return(_a0 int, _a1 error) *StorageLayer_ZAdd_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ZAdd_Call) RunAndReturn(run func(context.Context, string, ...engine.ScoredMember) (int, error)) *StorageLayer_ZAdd_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZIncrBy provides a mock function with given fields: ctx, key, member, delta
+func (_m *StorageLayer) ZIncrBy(ctx context.Context, key string, member string, delta float64) (float64, error) {
+	ret := _m.Called(ctx, key, member, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZIncrBy")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, float64) (float64, error)); ok {
+		return rf(ctx, key, member, delta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, float64) float64); ok {
+		r0 = rf(ctx, key, member, delta)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, float64) error); ok {
+		r1 = rf(ctx, key, member, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ZIncrBy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZIncrBy'
+type StorageLayer_ZIncrBy_Call struct {
+	*mock.Call
+}
+
+// ZIncrBy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - member string
+//   - delta float64
+func (_e *StorageLayer_Expecter) ZIncrBy(ctx interface{}, key interface{}, member interface{}, delta interface{}) *StorageLayer_ZIncrBy_Call {
+	return &StorageLayer_ZIncrBy_Call{Call: _e.mock.On("ZIncrBy", ctx, key, member, delta)}
+}
+
+func (_c *StorageLayer_ZIncrBy_Call) Run(run func(ctx context.Context, key string, member string, delta float64)) *StorageLayer_ZIncrBy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(float64))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZIncrBy_Call) Return(_a0 float64, _a1 error) *StorageLayer_ZIncrBy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ZIncrBy_Call) RunAndReturn(run func(context.Context, string, string, float64) (float64, error)) *StorageLayer_ZIncrBy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZRange provides a mock function with given fields: ctx, key, start, stop
+func (_m *StorageLayer) ZRange(ctx context.Context, key string, start int, stop int) ([]engine.ScoredMember, error) {
+	ret := _m.Called(ctx, key, start, stop)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRange")
+	}
+
+	var r0 []engine.ScoredMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]engine.ScoredMember, error)); ok {
+		return rf(ctx, key, start, stop)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []engine.ScoredMember); ok {
+		r0 = rf(ctx, key, start, stop)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]engine.ScoredMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, key, start, stop)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ZRange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZRange'
+type StorageLayer_ZRange_Call struct {
+	*mock.Call
+}
+
+// ZRange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - start int
+//   - stop int
+func (_e *StorageLayer_Expecter) ZRange(ctx interface{}, key interface{}, start interface{}, stop interface{}) *StorageLayer_ZRange_Call {
+	return &StorageLayer_ZRange_Call{Call: _e.mock.On("ZRange", ctx, key, start, stop)}
+}
+
+func (_c *StorageLayer_ZRange_Call) Run(run func(ctx context.Context, key string, start int, stop int)) *StorageLayer_ZRange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZRange_Call) Return(_a0 []engine.ScoredMember, _a1 error) *StorageLayer_ZRange_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ZRange_Call) RunAndReturn(run func(context.Context, string, int, int) ([]engine.ScoredMember, error)) *StorageLayer_ZRange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZRangeByScore provides a mock function with given fields: ctx, key, min, max, offset, count
+func (_m *StorageLayer) ZRangeByScore(ctx context.Context, key string, min engine.ScoreBound, max engine.ScoreBound, offset int, count int) ([]engine.ScoredMember, error) {
+	ret := _m.Called(ctx, key, min, max, offset, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRangeByScore")
+	}
+
+	var r0 []engine.ScoredMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.ScoreBound, engine.ScoreBound, int, int) ([]engine.ScoredMember, error)); ok {
+		return rf(ctx, key, min, max, offset, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, engine.ScoreBound, engine.ScoreBound, int, int) []engine.ScoredMember); ok {
+		r0 = rf(ctx, key, min, max, offset, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]engine.ScoredMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, engine.ScoreBound, engine.ScoreBound, int, int) error); ok {
+		r1 = rf(ctx, key, min, max, offset, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ZRangeByScore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZRangeByScore'
+type StorageLayer_ZRangeByScore_Call struct {
+	*mock.Call
+}
+
+// ZRangeByScore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - min engine.ScoreBound
+//   - max engine.ScoreBound
+//   - offset int
+//   - count int
+func (_e *StorageLayer_Expecter) ZRangeByScore(ctx interface{}, key interface{}, min interface{}, max interface{}, offset interface{}, count interface{}) *StorageLayer_ZRangeByScore_Call {
+	return &StorageLayer_ZRangeByScore_Call{Call: _e.mock.On("ZRangeByScore", ctx, key, min, max, offset, count)}
+}
+
+func (_c *StorageLayer_ZRangeByScore_Call) Run(run func(ctx context.Context, key string, min engine.ScoreBound, max engine.ScoreBound, offset int, count int)) *StorageLayer_ZRangeByScore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(engine.ScoreBound), args[3].(engine.ScoreBound), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZRangeByScore_Call) Return(_a0 []engine.ScoredMember, _a1 error) *StorageLayer_ZRangeByScore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ZRangeByScore_Call) RunAndReturn(run func(context.Context, string, engine.ScoreBound, engine.ScoreBound, int, int) ([]engine.ScoredMember, error)) *StorageLayer_ZRangeByScore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZRank provides a mock function with given fields: ctx, key, member
+func (_m *StorageLayer) ZRank(ctx context.Context, key string, member string) (int, bool, error) {
+	ret := _m.Called(ctx, key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRank")
+	}
+
+	var r0 int
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int, bool, error)); ok {
+		return rf(ctx, key, member)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, key, member)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) bool); ok {
+		r1 = rf(ctx, key, member)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, key, member)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_ZRank_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZRank'
+type StorageLayer_ZRank_Call struct {
+	*mock.Call
+}
+
+// ZRank is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - member string
+func (_e *StorageLayer_Expecter) ZRank(ctx interface{}, key interface{}, member interface{}) *StorageLayer_ZRank_Call {
+	return &StorageLayer_ZRank_Call{Call: _e.mock.On("ZRank", ctx, key, member)}
+}
+
+func (_c *StorageLayer_ZRank_Call) Run(run func(ctx context.Context, key string, member string)) *StorageLayer_ZRank_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZRank_Call) Return(_a0 int, _a1 bool, _a2 error) *StorageLayer_ZRank_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_ZRank_Call) RunAndReturn(run func(context.Context, string, string) (int, bool, error)) *StorageLayer_ZRank_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZRem provides a mock function with given fields: ctx, key, members
+func (_m *StorageLayer) ZRem(ctx context.Context, key string, members ...string) (int, error) {
+	_va := make([]interface{}, len(members))
+	for _i := range members {
+		_va[_i] = members[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, key)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRem")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (int, error)); ok {
+		return rf(ctx, key, members...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int); ok {
+		r0 = rf(ctx, key, members...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, key, members...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorageLayer_ZRem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZRem'
+type StorageLayer_ZRem_Call struct {
+	*mock.Call
+}
+
+// ZRem is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - members ...string
+func (_e *StorageLayer_Expecter) ZRem(ctx interface{}, key interface{}, members ...interface{}) *StorageLayer_ZRem_Call {
+	return &StorageLayer_ZRem_Call{Call: _e.mock.On("ZRem",
+		append([]interface{}{ctx, key}, members...)...)}
+}
+
+func (_c *StorageLayer_ZRem_Call) Run(run func(ctx context.Context, key string, members ...string)) *StorageLayer_ZRem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZRem_Call) Return(_a0 int, _a1 error) *StorageLayer_ZRem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *StorageLayer_ZRem_Call) RunAndReturn(run func(context.Context, string, ...string) (int, error)) *StorageLayer_ZRem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ZScore provides a mock function with given fields: ctx, key, member
+func (_m *StorageLayer) ZScore(ctx context.Context, key string, member string) (float64, bool, error) {
+	ret := _m.Called(ctx, key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZScore")
+	}
+
+	var r0 float64
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (float64, bool, error)); ok {
+		return rf(ctx, key, member)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) float64); ok {
+		r0 = rf(ctx, key, member)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) bool); ok {
+		r1 = rf(ctx, key, member)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, key, member)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// StorageLayer_ZScore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ZScore'
+type StorageLayer_ZScore_Call struct {
+	*mock.Call
+}
+
+// ZScore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - member string
+func (_e *StorageLayer_Expecter) ZScore(ctx interface{}, key interface{}, member interface{}) *StorageLayer_ZScore_Call {
+	return &StorageLayer_ZScore_Call{Call: _e.mock.On("ZScore", ctx, key, member)}
+}
+
+func (_c *StorageLayer_ZScore_Call) Run(run func(ctx context.Context, key string, member string)) *StorageLayer_ZScore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *StorageLayer_ZScore_Call) Return(_a0 float64, _a1 bool, _a2 error) *StorageLayer_ZScore_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *StorageLayer_ZScore_Call) RunAndReturn(run func(context.Context, string, string) (float64, bool, error)) *StorageLayer_ZScore_Call {
 	_c.Call.Return(run)
 	return _c
 }
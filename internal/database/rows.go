@@ -0,0 +1,34 @@
+package database
+
+import "github.com/cat-go-dev/kdb/internal/database/storage/engine"
+
+// entryRows streams entries as flat key value pairs, every entry takes two
+// rows so keys and values may contain any bytes.
+type entryRows struct {
+	engine.Iterator
+	// atKey is set while the row is the key of the current entry
+	atKey bool
+}
+
+func newEntryRows(it engine.Iterator) *entryRows {
+	return &entryRows{Iterator: it}
+}
+
+func (r *entryRows) Next() bool {
+	if r.atKey {
+		r.atKey = false
+		return true
+	}
+
+	r.atKey = r.Iterator.Next()
+
+	return r.atKey
+}
+
+func (r *entryRows) Row() string {
+	if r.atKey {
+		return r.Key()
+	}
+
+	return r.Value()
+}
@@ -0,0 +1,282 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Session keeps the transaction state of a single connection.
+type Session struct {
+	db Database
+	// commands queued after MULTI
+	queue []*compute.Command
+	multi bool
+	// a command failed to queue, so EXEC discards the transaction
+	aborted bool
+	watched map[string]struct{}
+	// set when a watched key is modified
+	dirty *atomic.Bool
+	// opened by BEGIN READONLY, reads are served from its snapshot
+	readTx *storage.ReadTx
+}
+
+func (d Database) NewSession() ports.Session {
+	return &Session{
+		db:      d,
+		watched: make(map[string]struct{}),
+		dirty:   &atomic.Bool{},
+	}
+}
+
+func (s *Session) Execute(ctx context.Context, commandStr string) (*ports.Result, error) {
+	logAttrs := []any{
+		slog.String("component", "session"),
+		slog.String("method", "Execute"),
+	}
+
+	command, err := s.db.compute.Parse(ctx, commandStr)
+	if err != nil {
+		if s.multi {
+			s.aborted = true
+		}
+
+		wErr := fmt.Errorf("%s: %w", errComputeParse, err)
+		s.db.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, err
+	}
+
+	switch {
+	case command.Type.IsMulti():
+		if s.multi {
+			s.db.logger.ErrorContext(ctx, errNestedMulti.Error(), logAttrs...)
+			return nil, errNestedMulti
+		}
+		if s.readTx != nil {
+			s.db.logger.ErrorContext(ctx, errTransactionInProgress.Error(), logAttrs...)
+			return nil, errTransactionInProgress
+		}
+		s.multi = true
+
+		return ports.Simple(responseOK), nil
+	case command.Type.IsBegin():
+		if s.multi || s.readTx != nil {
+			s.db.logger.ErrorContext(ctx, errTransactionInProgress.Error(), logAttrs...)
+			return nil, errTransactionInProgress
+		}
+
+		s.readTx, err = s.db.storage.BeginRead(ctx)
+		if err != nil {
+			wErr := fmt.Errorf("storage call: %w", err)
+			s.db.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return nil, wErr
+		}
+
+		return ports.Simple(responseOK), nil
+	case command.Type.IsCommit(), command.Type.IsRollback():
+		if s.readTx == nil {
+			s.db.logger.ErrorContext(ctx, errNoTransaction.Error(), logAttrs...)
+			return nil, errNoTransaction
+		}
+		s.closeReadTx()
+
+		return ports.Simple(responseOK), nil
+	case command.Type.IsExec():
+		return s.exec(ctx)
+	case command.Type.IsDiscard():
+		if !s.multi {
+			s.db.logger.ErrorContext(ctx, errDiscardWithoutMulti.Error(), logAttrs...)
+			return nil, errDiscardWithoutMulti
+		}
+		s.reset()
+
+		return ports.Simple(responseOK), nil
+	case command.Type.IsWatch():
+		if s.multi {
+			s.db.logger.ErrorContext(ctx, errWatchInsideMulti.Error(), logAttrs...)
+			return nil, errWatchInsideMulti
+		}
+		for _, key := range command.Keys {
+			s.watch(string(key))
+		}
+
+		return ports.Simple(responseOK), nil
+	case command.Type.IsUnwatch():
+		s.unwatch()
+
+		return ports.Simple(responseOK), nil
+	case s.multi:
+		s.queue = append(s.queue, command)
+
+		return ports.Simple(responseQueued), nil
+	case s.readTx != nil:
+		return s.read(ctx, command)
+	case command.Type.IsBlockingPop():
+		// inside MULTI it is queued above and does not wait
+		return s.db.blockingPop(ctx, command)
+	}
+
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.db.execute(ctx, command)
+}
+
+// Close releases watched keys and the read transaction.
+func (s *Session) Close() {
+	s.reset()
+	s.closeReadTx()
+}
+
+// read serves reads from the snapshot of the read transaction, writes are
+// rejected. Readers do not take the database lock, so they neither wait for
+// writers nor block them.
+func (s *Session) read(ctx context.Context, command *compute.Command) (*ports.Result, error) {
+	logAttrs := []any{
+		slog.String("component", "session"),
+		slog.String("method", "read"),
+		slog.Any("command", command),
+	}
+
+	var result *ports.Result
+	var err error
+
+	switch {
+	case command.Type.IsGet():
+		var value string
+		var ok bool
+		value, ok, err = s.readTx.Get(ctx, string(command.Key()))
+		result = ports.Nil()
+		if ok {
+			result = ports.Bulk(value)
+		}
+	case command.Type.IsRange():
+		args, _ := command.Args.(compute.RangeArgs)
+		var it engine.Iterator
+		it, err = s.readTx.Range(ctx, string(args.Start), string(args.End), args.Limit)
+		if err == nil {
+			result = ports.Stream(newEntryRows(it))
+		}
+	case command.Type.IsPrefix():
+		args, _ := command.Args.(compute.PrefixArgs)
+		var it engine.Iterator
+		it, err = s.readTx.Prefix(ctx, string(args.Prefix), args.Limit)
+		if err == nil {
+			result = ports.Stream(newEntryRows(it))
+		}
+	default:
+		err = errReadOnlyTransaction
+	}
+
+	if err != nil {
+		wErr := fmt.Errorf("read transaction: %w", err)
+		s.db.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return result, nil
+}
+
+func (s *Session) closeReadTx() {
+	if s.readTx == nil {
+		return
+	}
+
+	s.readTx.Close()
+	s.readTx = nil
+}
+
+// exec runs queued commands holding the database lock exclusively. A
+// modified watched key aborts the transaction with a nil reply, errors of
+// single commands are returned as error elements and do not stop the rest.
+func (s *Session) exec(ctx context.Context) (*ports.Result, error) {
+	logAttrs := []any{
+		slog.String("component", "session"),
+		slog.String("method", "exec"),
+	}
+
+	if !s.multi {
+		s.db.logger.ErrorContext(ctx, errExecWithoutMulti.Error(), logAttrs...)
+		return nil, errExecWithoutMulti
+	}
+
+	queue, aborted := s.queue, s.aborted
+	defer s.reset()
+
+	if aborted {
+		s.db.logger.ErrorContext(ctx, errExecAborted.Error(), logAttrs...)
+		return nil, errExecAborted
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.dirty.Load() {
+		s.db.logger.InfoContext(ctx, "transaction aborted, watched key is modified", logAttrs...)
+		return ports.NilArray(), nil
+	}
+
+	replies := make([]*ports.Result, 0, len(queue))
+	for _, command := range queue {
+		result, err := s.db.execute(ctx, command)
+		if err == nil {
+			result, err = readRows(result)
+		}
+		if err != nil {
+			s.db.logger.ErrorContext(ctx, fmt.Errorf("queued command: %w", err).Error(), logAttrs...)
+			replies = append(replies, ports.Error(err))
+			continue
+		}
+		replies = append(replies, result)
+	}
+
+	return ports.Array(replies...), nil
+}
+
+func (s *Session) watch(key string) {
+	if _, ok := s.watched[key]; ok {
+		return
+	}
+
+	s.watched[key] = struct{}{}
+	s.db.watches.add(key, s)
+}
+
+func (s *Session) unwatch() {
+	for key := range s.watched {
+		s.db.watches.remove(key, s)
+	}
+
+	clear(s.watched)
+	s.dirty.Store(false)
+}
+
+// reset leaves the transaction, EXEC and DISCARD also unwatch all keys.
+func (s *Session) reset() {
+	s.queue = nil
+	s.multi = false
+	s.aborted = false
+	s.unwatch()
+}
+
+// readRows reads streamed rows of a queued command while the lock is
+// held, the reply of EXEC is sent after it is released.
+func readRows(result *ports.Result) (*ports.Result, error) {
+	if result.Rows == nil {
+		return result, nil
+	}
+	defer result.Rows.Close()
+
+	var rows []string
+	for result.Rows.Next() {
+		rows = append(rows, result.Rows.Row())
+	}
+
+	return ports.BulkArray(rows), result.Rows.Err()
+}
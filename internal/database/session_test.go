@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/mvcc"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	session := db.NewSession()
+	defer session.Close()
+
+	execute(t, session, "SET a 1", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET a 2", ports.Simple(responseQueued))
+	execute(t, session, "GET a", ports.Simple(responseQueued))
+	execute(t, session, "DEL b", ports.Simple(responseQueued))
+
+	// queued commands are not applied before EXEC
+	result, err := db.Execute(ctx, "GET a")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Bulk("1"), result)
+
+	result, err = session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Simple(responseOK), ports.Bulk("2"), ports.Integer(0)), result)
+
+	_, err = session.Execute(ctx, "EXEC")
+	assert.ErrorIs(t, err, errExecWithoutMulti)
+}
+
+func TestTransactionDiscard(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	session := db.NewSession()
+	defer session.Close()
+
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	_, err := session.Execute(ctx, "MULTI")
+	assert.ErrorIs(t, err, errNestedMulti)
+	execute(t, session, "SET a 1", ports.Simple(responseQueued))
+	execute(t, session, "DISCARD", ports.Simple(responseOK))
+
+	execute(t, session, "GET a", ports.Nil())
+
+	_, err = session.Execute(ctx, "DISCARD")
+	assert.ErrorIs(t, err, errDiscardWithoutMulti)
+}
+
+func TestTransactionAbortedByInvalidCommand(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	session := db.NewSession()
+	defer session.Close()
+
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET a 1", ports.Simple(responseQueued))
+	_, err := session.Execute(ctx, "SET")
+	assert.Error(t, err)
+
+	_, err = session.Execute(ctx, "EXEC")
+	assert.ErrorIs(t, err, errExecAborted)
+	execute(t, session, "GET a", ports.Nil())
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	session := db.NewSession()
+	defer session.Close()
+
+	execute(t, session, "WATCH a b", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	_, err := session.Execute(ctx, "WATCH c")
+	assert.ErrorIs(t, err, errWatchInsideMulti)
+	execute(t, session, "SET a 1", ports.Simple(responseQueued))
+
+	// another connection modifies a watched key
+	other := db.NewSession()
+	defer other.Close()
+	execute(t, other, "SET b 2", ports.Simple(responseOK))
+
+	result, err := session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.NilArray(), result)
+	execute(t, session, "GET a", ports.Nil())
+
+	// EXEC unwatches keys, so the next transaction succeeds
+	execute(t, other, "SET b 3", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET a 1", ports.Simple(responseQueued))
+	result, err = session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Simple(responseOK)), result)
+
+	execute(t, session, "WATCH a", ports.Simple(responseOK))
+	execute(t, other, "DEL a", ports.Integer(1))
+	execute(t, session, "UNWATCH", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET a 2", ports.Simple(responseQueued))
+	result, err = session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Simple(responseOK)), result)
+}
+
+func TestWatchIgnoresNoopWrites(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	session := db.NewSession()
+	defer session.Close()
+
+	other := db.NewSession()
+	defer other.Close()
+
+	execute(t, session, "WATCH list a", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET b 1", ports.Simple(responseQueued))
+
+	// writes which modify nothing do not abort the transaction
+	execute(t, other, "BLPOP list 0.01", ports.NilArray())
+	execute(t, other, "RPOP list", ports.Nil())
+	execute(t, other, "DEL a", ports.Integer(0))
+
+	result, err := session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.Array(ports.Simple(responseOK)), result)
+
+	execute(t, other, "RPUSH list x", ports.Integer(1))
+	execute(t, session, "WATCH list", ports.Simple(responseOK))
+	execute(t, session, "MULTI", ports.Simple(responseOK))
+	execute(t, session, "SET b 2", ports.Simple(responseQueued))
+	execute(t, other, "BLPOP missing list 0.01", ports.BulkArray([]string{"list", "x"}))
+
+	result, err = session.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	assert.Equal(t, ports.NilArray(), result)
+}
+
+func TestTransactionWithoutSession(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Execute(context.Background(), "MULTI")
+	assert.ErrorIs(t, err, errSessionRequired)
+}
+
+func newTestDatabase(t *testing.T) *Database {
+	storage, err := storage.NewStorage(engine.NewEngine(), getMockedLogger())
+	require.NoError(t, err)
+
+	db, err := NewDatabase(getMockedCompute(t), storage, getMockedLogger())
+	require.NoError(t, err)
+
+	return db
+}
+
+func execute(t *testing.T, session ports.Session, command string, expected *ports.Result) {
+	t.Helper()
+
+	result, err := session.Execute(context.Background(), command)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestReadTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := storage.NewStorage(mvcc.NewMVCC(), getMockedLogger())
+	require.NoError(t, err)
+	db, err := NewDatabase(getMockedCompute(t), storage, getMockedLogger())
+	require.NoError(t, err)
+
+	session := db.NewSession()
+	defer session.Close()
+	writer := db.NewSession()
+	defer writer.Close()
+
+	execute(t, writer, "SET user:1 alice", ports.Simple(responseOK))
+	execute(t, session, "BEGIN READONLY", ports.Simple(responseOK))
+	_, err = session.Execute(ctx, "MULTI")
+	assert.ErrorIs(t, err, errTransactionInProgress)
+
+	execute(t, writer, "SET user:1 bob", ports.Simple(responseOK))
+	execute(t, writer, "SET user:2 carol", ports.Simple(responseOK))
+
+	execute(t, session, "GET user:1", ports.Bulk("alice"))
+	result, err := session.Execute(ctx, "PREFIX user:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "alice"}, collectRows(result))
+
+	_, err = session.Execute(ctx, "SET user:1 dave")
+	assert.ErrorIs(t, err, errReadOnlyTransaction)
+
+	execute(t, session, "COMMIT", ports.Simple(responseOK))
+	execute(t, session, "GET user:1", ports.Bulk("bob"))
+
+	_, err = session.Execute(ctx, "ROLLBACK")
+	assert.ErrorIs(t, err, errNoTransaction)
+}
+
+func TestReadTransactionNotSupported(t *testing.T) {
+	session := newTestDatabase(t).NewSession()
+	defer session.Close()
+
+	_, err := session.Execute(context.Background(), "BEGIN READONLY")
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// IncrBy adds delta to the integer stored at the key and returns the new
+// value, a missing key is created at zero. The WAL keeps the increment, so
+// the replay repeats the same computation, increments already kept by a
+// durable engine are not replayed.
+func (s Storage) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "IncrBy"),
+		slog.String("key", key),
+		slog.Int64("delta", delta),
+	}
+
+	updater, ok := s.engine.(engine.Updater)
+	if !ok {
+		s.logger.ErrorContext(ctx, errUpdateNotSupported.Error(), logAttrs...)
+		return 0, errUpdateNotSupported
+	}
+
+	var result int64
+	err := s.write(ctx, wal.OpIncrBy, []string{key, strconv.FormatInt(delta, 10)}, func() error {
+		var err error
+		result, err = incrBy(ctx, updater, key, delta)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("incr in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return result, nil
+}
+
+// IncrByFloat adds delta to the number stored at the key and returns the
+// new value formatted the way it is stored.
+func (s Storage) IncrByFloat(ctx context.Context, key string, delta float64) (string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "IncrByFloat"),
+		slog.String("key", key),
+		slog.Float64("delta", delta),
+	}
+
+	updater, ok := s.engine.(engine.Updater)
+	if !ok {
+		s.logger.ErrorContext(ctx, errUpdateNotSupported.Error(), logAttrs...)
+		return "", errUpdateNotSupported
+	}
+
+	var result string
+	err := s.write(ctx, wal.OpIncrByFloat, []string{key, formatFloat(delta)}, func() error {
+		var err error
+		result, err = incrByFloat(ctx, updater, key, delta)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("incr by float in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return "", wErr
+	}
+
+	return result, nil
+}
+
+func incrBy(ctx context.Context, updater engine.Updater, key string, delta int64) (int64, error) {
+	var result int64
+	_, err := updater.Update(ctx, key, incrementInteger(delta, &result))
+
+	return result, err
+}
+
+// incrementInteger returns an update adding delta to a stored integer, the
+// new value is also kept in result.
+func incrementInteger(delta int64, result *int64) engine.UpdateFunc {
+	return func(value string, exists bool) (string, error) {
+		var current int64
+		if exists {
+			var err error
+			current, err = strconv.ParseInt(value, 10, 64)
+			// values like "+1" or "01" are not stored integers
+			if err != nil || strconv.FormatInt(current, 10) != value {
+				return "", ports.ErrNotInteger
+			}
+		}
+
+		if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+			return "", ports.ErrOverflow
+		}
+
+		*result = current + delta
+
+		return strconv.FormatInt(*result, 10), nil
+	}
+}
+
+func incrByFloat(ctx context.Context, updater engine.Updater, key string, delta float64) (string, error) {
+	return updater.Update(ctx, key, func(value string, exists bool) (string, error) {
+		var current float64
+		if exists {
+			var err error
+			current, err = strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+				return "", ports.ErrNotFloat
+			}
+		}
+
+		result := current + delta
+		if math.IsNaN(result) || math.IsInf(result, 0) {
+			return "", ports.ErrOverflow
+		}
+
+		return formatFloat(result), nil
+	})
+}
+
+// formatFloat keeps the shortest representation without an exponent, so
+// integral results can be incremented by INCR later.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
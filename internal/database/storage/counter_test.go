@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/lsm"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestIncrBy(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	value, err := storage.IncrBy(ctx, "counter", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	value, err = storage.IncrBy(ctx, "counter", -10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-9), value)
+
+	require.NoError(t, storage.Set(ctx, "text", "abc"))
+	_, err = storage.IncrBy(ctx, "text", 1)
+	assert.ErrorIs(t, err, ports.ErrNotInteger)
+
+	require.NoError(t, storage.Set(ctx, "big", strconv.FormatInt(math.MaxInt64, 10)))
+	_, err = storage.IncrBy(ctx, "big", 1)
+	assert.ErrorIs(t, err, ports.ErrOverflow)
+
+	stored, _, err := storage.Get(ctx, "big")
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(math.MaxInt64, 10), stored)
+
+	float, err := storage.IncrByFloat(ctx, "counter", 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, "-8.5", float)
+
+	_, err = storage.IncrByFloat(ctx, "text", 1)
+	assert.ErrorIs(t, err, ports.ErrNotFloat)
+
+	_, err = storage.IncrBy(ctx, "counter", 1)
+	assert.ErrorIs(t, err, ports.ErrNotInteger)
+}
+
+func TestIncrByConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewShardedEngine(4), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	const workers, increments = 8, 100
+
+	wg := &sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range increments {
+				_, err := storage.IncrBy(ctx, "counter", 1)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _, err := storage.Get(ctx, "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(workers*increments), value)
+}
+
+func TestRecoverReplaysIncrements(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	_, err = st.IncrBy(ctx, "counter", 5)
+	require.NoError(t, err)
+	require.NoError(t, st.Set(ctx, "text", "abc"))
+	_, err = st.IncrBy(ctx, "text", 1)
+	require.Error(t, err)
+	_, err = st.IncrByFloat(ctx, "counter", 1.25)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err = NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Recover(ctx))
+
+	value, _, err := st.Get(ctx, "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, "6.25", value)
+}
+
+func TestCountersSurviveLSMRestarts(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	for i := range 3 {
+		w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir + "/wal"})
+		require.NoError(t, err)
+
+		// a small memtable is flushed in the middle of the run
+		l, err := lsm.NewLSM(logger, &lsm.Opts{DataDir: dir + "/lsm", MemtableSize: 256})
+		require.NoError(t, err)
+
+		storage, err := NewStorage(l, logger, WithWAL(w))
+		require.NoError(t, err)
+		require.NoError(t, storage.Recover(ctx))
+
+		for j := range 10 {
+			require.NoError(t, storage.Set(ctx, "filler"+strconv.Itoa(j), "value"))
+		}
+
+		value, err := storage.IncrBy(ctx, "hits", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), value)
+
+		float, err := storage.IncrByFloat(ctx, "ratio", 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.FormatFloat(float64(i+1)/2, 'f', -1, 64), float)
+
+		require.NoError(t, l.Close())
+		require.NoError(t, w.Close())
+	}
+}
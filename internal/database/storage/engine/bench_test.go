@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// Run with different -cpu values to see how throughput scales, e.g.
+// go test -run=^$ -bench=. -cpu=1,2,4,8 ./internal/database/storage/engine
+
+type benchEngine interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+const benchKeys = 1 << 16
+
+func benchEngines() []struct {
+	name   string
+	engine func() benchEngine
+} {
+	return []struct {
+		name   string
+		engine func() benchEngine
+	}{
+		{name: "in_memory", engine: func() benchEngine { return NewEngine() }},
+		{name: "sharded_in_memory", engine: func() benchEngine { return NewShardedEngine(0) }},
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkEngines(b, 0)
+}
+
+func BenchmarkSet(b *testing.B) {
+	benchmarkEngines(b, 100)
+}
+
+func BenchmarkMixed(b *testing.B) {
+	benchmarkEngines(b, 10)
+}
+
+// benchmarkEngines runs parallel operations where writePercent of them are
+// writes and the rest are reads.
+func benchmarkEngines(b *testing.B, writePercent int) {
+	ctx := context.Background()
+
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+
+	for _, bb := range benchEngines() {
+		b.Run(bb.name, func(b *testing.B) {
+			engine := bb.engine()
+			for _, key := range keys {
+				_ = engine.Set(ctx, key, key)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := rand.Intn(benchKeys)
+				for pb.Next() {
+					key := keys[(i*7919)&(benchKeys-1)]
+					if i%100 < writePercent {
+						_ = engine.Set(ctx, key, key)
+					} else {
+						_, _, _ = engine.Get(ctx, key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/cat-go-dev/kdb/internal/utils"
+)
+
+const (
+	InMemoryType        = "in_memory"
+	ShardedInMemoryType = "sharded_in_memory"
+	OrderedType         = "ordered"
+)
+
+type InMemoryConfig struct {
+	MaxMemory      string `mapstructure:"max_memory"`
+	EvictionPolicy string `mapstructure:"eviction_policy"`
+}
+
+// OrderedConfig has no settings yet.
+type OrderedConfig struct{}
+
+type ShardedInMemoryConfig struct {
+	InMemoryConfig `mapstructure:",squash"`
+	Shards         int `mapstructure:"shards"`
+}
+
+func init() {
+	Register(InMemoryType, func(cfg InMemoryConfig, logger *slog.Logger) (Interface, error) {
+		opts, err := cfg.options()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewEngine(opts...), nil
+	})
+
+	Register(ShardedInMemoryType, func(cfg ShardedInMemoryConfig, logger *slog.Logger) (Interface, error) {
+		opts, err := cfg.options()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewShardedEngine(cfg.Shards, opts...), nil
+	})
+
+	Register(OrderedType, func(cfg OrderedConfig, logger *slog.Logger) (Interface, error) {
+		return NewOrderedEngine(), nil
+	})
+}
+
+func (cfg InMemoryConfig) options() ([]Option, error) {
+	// the policy is checked without a limit too, so typos are not ignored
+	policy, err := ParseEvictionPolicy(cfg.EvictionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxMemory == "" {
+		return nil, nil
+	}
+
+	maxMemory, err := utils.ParseSize(cfg.MaxMemory)
+	if err != nil {
+		return nil, fmt.Errorf("parsing max memory: %w", err)
+	}
+
+	return []Option{WithMaxMemory(maxMemory, policy)}, nil
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+type Entry struct {
+	Key  string
+	Kind Kind
+	// Value holds strings, Items holds the content of collections
+	Value string
+	Items []string
+	// ExpireAt is a deadline in unix nanoseconds, 0 for persistent keys
+	ExpireAt int64
+}
+
+// Dump returns a consistent copy of the whole keyspace.
+func (e *Engine) Dump(ctx context.Context) ([]Entry, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.dumpLocked(make([]Entry, 0, e.len()), time.Now().UnixNano()), nil
+}
+
+func (e *Engine) Restore(ctx context.Context, entry Entry) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// restored data was accepted before, so the limit is not enforced here
+	return e.restoreLocked(entry, time.Now().UnixNano())
+}
+
+// Dump returns a consistent copy of the whole keyspace, all shards are
+// locked for the time of copying.
+func (e *ShardedEngine) Dump(ctx context.Context) ([]Entry, error) {
+	size := 0
+	for _, shard := range e.shards {
+		shard.mu.RLock()
+		defer shard.mu.RUnlock()
+
+		size += shard.len()
+	}
+
+	now := time.Now().UnixNano()
+	entries := make([]Entry, 0, size)
+	for _, shard := range e.shards {
+		entries = shard.dumpLocked(entries, now)
+	}
+
+	return entries, nil
+}
+
+func (e *ShardedEngine) Restore(ctx context.Context, entry Entry) error {
+	shard := e.shardFor(entry.Key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	return shard.restoreLocked(entry, time.Now().UnixNano())
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpAndRestore(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	for _, item := range getTestData() {
+		assert.NoError(t, engine.Set(ctx, item, item))
+	}
+
+	entries, err := engine.Dump(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(getTestData()))
+
+	restored := NewEngine()
+	for _, entry := range entries {
+		assert.NoError(t, restored.Restore(ctx, entry))
+	}
+
+	restoredEntries, err := restored.Dump(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, entries, restoredEntries)
+	assert.Equal(t, engine.UsedMemory(), restored.UsedMemory())
+}
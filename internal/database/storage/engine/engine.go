@@ -2,49 +2,64 @@ package engine
 
 import (
 	"context"
-	"sync"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
 )
 
 type Engine struct {
-	mu *sync.Mutex
-	m  map[string]string
+	*shard
 }
 
-const defaultMapSize = 1000
+func NewEngine(opts ...Option) *Engine {
+	o := applyOptions(opts)
 
-func NewEngine() *Engine {
 	return &Engine{
-		mu: &sync.Mutex{},
-		m:  make(map[string]string, defaultMapSize),
+		shard: newShard(newMemory(o.maxMemory, o.evictionPolicy)),
 	}
 }
 
-func (e *Engine) Get(ctx context.Context, key string) (string, error) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+func (e *Engine) Get(ctx context.Context, key string) (string, bool, error) {
+	return e.get(key, time.Now().UnixNano())
+}
+
+func (e *Engine) Set(ctx context.Context, key, value string) error {
+	return e.set(key, value, 0, time.Now().UnixNano())
+}
 
-	value, exists := e.m[key]
-	if !exists {
-		return "", nil
+// Update replaces the value with the result of fn under the engine lock.
+func (e *Engine) Update(ctx context.Context, key string, fn UpdateFunc) (string, error) {
+	value, err := e.update(key, fn, time.Now().UnixNano())
+	if err != nil {
+		return "", err
 	}
 
 	return value, nil
 }
 
-func (e *Engine) Set(ctx context.Context, key, value string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	e.m[key] = value
+func (e *Engine) Del(ctx context.Context, key string) (bool, error) {
+	return e.del(key, time.Now().UnixNano()), nil
+}
 
-	return nil
+// UsedMemory returns the approximate number of bytes taken by the data.
+func (e *Engine) UsedMemory() int64 {
+	return e.mem.used.Load()
 }
 
-func (e *Engine) Del(ctx context.Context, key string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+// DeferEviction makes writes ignore the memory limit, keys are evicted by
+// the caller with Victims before the write instead. It lets the storage log
+// evictions, so replaying the log evicts the same keys.
+func (e *Engine) DeferEviction() {
+	e.mem.deferred.Store(true)
+}
 
-	delete(e.m, key)
+// Victims picks keys to evict before writing size bytes into the key, it
+// fails with ports.ErrOutOfMemory when the write does not fit anyway.
+func (e *Engine) Victims(ctx context.Context, key string, size int64) ([]string, error) {
+	victims, rest := e.victims(key, size, time.Now().UnixNano())
+	if e.mem.exceeds(rest) {
+		return nil, ports.ErrOutOfMemory
+	}
 
-	return nil
+	return victims, nil
 }
@@ -16,36 +16,45 @@ func TestEngine(t *testing.T) {
 	data := getTestData()
 
 	// test concurrent writing
+	wg := &sync.WaitGroup{}
 	for i := range data {
+		wg.Add(1)
 		go func(item string) {
+			defer wg.Done()
+
 			err := engine.Set(ctx, item, item)
 			assert.Nil(t, err)
 		}(data[i])
 	}
+	wg.Wait()
 
 	// test getting
 	for i := range data {
+		wg.Add(1)
 		go func(item string) {
-			i, err := engine.Get(ctx, item)
+			defer wg.Done()
+
+			i, _, err := engine.Get(ctx, item)
 			assert.Nil(t, err)
 			assert.Equal(t, item, i)
 		}(data[i])
 	}
+	wg.Wait()
 
 	// test deliting
-	wg := &sync.WaitGroup{}
 	for i := range data {
 		wg.Add(1)
 		go func(item string) {
 			defer wg.Done()
 
-			err := engine.Del(ctx, item)
+			removed, err := engine.Del(ctx, item)
 			assert.Nil(t, err)
+			assert.True(t, removed)
 		}(data[i])
 	}
 	wg.Wait()
 
-	assert.Equal(t, 0, len(engine.m))
+	assert.Equal(t, 0, engine.len())
 }
 
 func getTestData() []string {
@@ -0,0 +1,11 @@
+package engine
+
+import "errors"
+
+var (
+	errUnknownEvictionPolicy = errors.New("unknown eviction policy")
+	errUnknownEngine         = errors.New("unknown engine")
+	errUnknownKind           = errors.New("unknown value kind")
+	errUnsupportedKind       = errors.New("engine does not support values of kind")
+	errInvalidItems          = errors.New("invalid collection items")
+)
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+func (e *Engine) SetWithDeadline(ctx context.Context, key, value string, deadline time.Time) error {
+	return e.set(key, value, deadline.UnixNano(), time.Now().UnixNano())
+}
+
+func (e *Engine) ExpireAt(ctx context.Context, key string, deadline time.Time) (bool, error) {
+	return e.expire(key, deadline.UnixNano(), time.Now().UnixNano()), nil
+}
+
+// Deadline returns the expiration time of the key, zero time means the key
+// is persistent.
+func (e *Engine) Deadline(ctx context.Context, key string) (time.Time, bool, error) {
+	deadline, ok := e.deadline(key, time.Now().UnixNano())
+
+	return unixNanoToTime(deadline), ok, nil
+}
+
+func (e *Engine) Persist(ctx context.Context, key string) (bool, error) {
+	return e.persist(key, time.Now().UnixNano()), nil
+}
+
+func (e *Engine) DeleteExpired(ctx context.Context) int {
+	return e.deleteExpired(time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) SetWithDeadline(ctx context.Context, key, value string, deadline time.Time) error {
+	return e.set(key, value, deadline.UnixNano())
+}
+
+func (e *ShardedEngine) ExpireAt(ctx context.Context, key string, deadline time.Time) (bool, error) {
+	return e.shardFor(key).expire(key, deadline.UnixNano(), time.Now().UnixNano()), nil
+}
+
+func (e *ShardedEngine) Deadline(ctx context.Context, key string) (time.Time, bool, error) {
+	deadline, ok := e.shardFor(key).deadline(key, time.Now().UnixNano())
+
+	return unixNanoToTime(deadline), ok, nil
+}
+
+func (e *ShardedEngine) Persist(ctx context.Context, key string) (bool, error) {
+	return e.shardFor(key).persist(key, time.Now().UnixNano()), nil
+}
+
+func (e *ShardedEngine) DeleteExpired(ctx context.Context) int {
+	deleted := 0
+	for _, shard := range e.shards {
+		if ctx.Err() != nil {
+			break
+		}
+
+		deleted += shard.deleteExpired(time.Now().UnixNano())
+	}
+
+	return deleted
+}
+
+func unixNanoToTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
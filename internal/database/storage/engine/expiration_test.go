@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type expiringTestEngine interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+	SetWithDeadline(ctx context.Context, key, value string, deadline time.Time) error
+	ExpireAt(ctx context.Context, key string, deadline time.Time) (bool, error)
+	Deadline(ctx context.Context, key string) (time.Time, bool, error)
+	Persist(ctx context.Context, key string) (bool, error)
+	DeleteExpired(ctx context.Context) int
+}
+
+func expiringEngines() map[string]func() expiringTestEngine {
+	return map[string]func() expiringTestEngine{
+		"in_memory":         func() expiringTestEngine { return NewEngine() },
+		"sharded_in_memory": func() expiringTestEngine { return NewShardedEngine(4) },
+	}
+}
+
+func TestLazyExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newEngine := range expiringEngines() {
+		t.Run(name, func(t *testing.T) {
+			engine := newEngine()
+
+			assert.NoError(t, engine.SetWithDeadline(ctx, "expired", "value", time.Now().Add(-time.Second)))
+			assert.NoError(t, engine.SetWithDeadline(ctx, "alive", "value", time.Now().Add(time.Hour)))
+
+			value, _, err := engine.Get(ctx, "expired")
+			assert.NoError(t, err)
+			assert.Empty(t, value)
+
+			value, _, err = engine.Get(ctx, "alive")
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+
+			_, exists, err := engine.Deadline(ctx, "expired")
+			assert.NoError(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestExpireAtAndPersist(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newEngine := range expiringEngines() {
+		t.Run(name, func(t *testing.T) {
+			engine := newEngine()
+			deadline := time.Now().Add(time.Hour)
+
+			exists, err := engine.ExpireAt(ctx, "missing", deadline)
+			assert.NoError(t, err)
+			assert.False(t, exists)
+
+			assert.NoError(t, engine.Set(ctx, "key", "value"))
+
+			actual, exists, err := engine.Deadline(ctx, "key")
+			assert.NoError(t, err)
+			assert.True(t, exists)
+			assert.True(t, actual.IsZero())
+
+			exists, err = engine.ExpireAt(ctx, "key", deadline)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+
+			actual, _, err = engine.Deadline(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, deadline.UnixNano(), actual.UnixNano())
+
+			persisted, err := engine.Persist(ctx, "key")
+			assert.NoError(t, err)
+			assert.True(t, persisted)
+
+			persisted, err = engine.Persist(ctx, "key")
+			assert.NoError(t, err)
+			assert.False(t, persisted)
+
+			// deadline in the past removes the key right away
+			exists, err = engine.ExpireAt(ctx, "key", time.Now().Add(-time.Second))
+			assert.NoError(t, err)
+			assert.True(t, exists)
+
+			value, _, err := engine.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Empty(t, value)
+		})
+	}
+}
+
+func TestSetClearsExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newEngine := range expiringEngines() {
+		t.Run(name, func(t *testing.T) {
+			engine := newEngine()
+
+			assert.NoError(t, engine.SetWithDeadline(ctx, "key", "value", time.Now().Add(time.Hour)))
+			assert.NoError(t, engine.Set(ctx, "key", "other"))
+
+			deadline, exists, err := engine.Deadline(ctx, "key")
+			assert.NoError(t, err)
+			assert.True(t, exists)
+			assert.True(t, deadline.IsZero())
+		})
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newEngine := range expiringEngines() {
+		t.Run(name, func(t *testing.T) {
+			engine := newEngine()
+
+			past := time.Now().Add(-time.Second)
+			for i := range 100 {
+				assert.NoError(t, engine.SetWithDeadline(ctx, strconv.Itoa(i), "value", past))
+			}
+			assert.NoError(t, engine.SetWithDeadline(ctx, "alive", "value", time.Now().Add(time.Hour)))
+			assert.NoError(t, engine.Set(ctx, "persistent", "value"))
+
+			deleted := 0
+			for range 10 {
+				deleted += engine.DeleteExpired(ctx)
+			}
+			assert.Equal(t, 100, deleted)
+
+			value, _, err := engine.Get(ctx, "alive")
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		})
+	}
+}
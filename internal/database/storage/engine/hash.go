@@ -0,0 +1,288 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// fieldOverhead is the approximate memory taken by a hash field besides its
+// bytes: the map entry and two string headers.
+const fieldOverhead = 48
+
+// hash maps fields to values.
+type hash struct {
+	fields map[string]string
+	bytes  int64
+}
+
+func newHash() *hash {
+	return &hash{fields: make(map[string]string)}
+}
+
+func (h *hash) kind() Kind {
+	return KindHash
+}
+
+func (h *hash) len() int {
+	return len(h.fields)
+}
+
+func (h *hash) size() int64 {
+	return h.bytes + int64(len(h.fields))*fieldOverhead
+}
+
+// items returns field value pairs ordered by field.
+func (h *hash) items() []string {
+	fields := make([]string, 0, len(h.fields))
+	for field := range h.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	items := make([]string, 0, 2*len(fields))
+	for _, field := range fields {
+		items = append(items, field, h.fields[field])
+	}
+
+	return items
+}
+
+// set reports whether the field is new.
+func (h *hash) set(field, value string) bool {
+	old, exists := h.fields[field]
+	if exists {
+		h.bytes -= int64(len(old))
+	} else {
+		h.bytes += int64(len(field))
+	}
+
+	h.fields[field] = value
+	h.bytes += int64(len(value))
+
+	return !exists
+}
+
+func (h *hash) del(field string) bool {
+	value, exists := h.fields[field]
+	if !exists {
+		return false
+	}
+
+	delete(h.fields, field)
+	h.bytes -= int64(len(field) + len(value))
+
+	return true
+}
+
+// Hashes is implemented by engines which store hash values. Operations on a
+// key holding a value of another kind fail with ports.ErrWrongType.
+type Hashes interface {
+	// HashSet stores field value pairs and returns the number of new fields
+	HashSet(ctx context.Context, key string, pairs ...string) (int, error)
+	// HashGet returns values of the fields and whether each field exists
+	HashGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error)
+	HashExists(ctx context.Context, key, field string) (bool, error)
+	// HashGetAll returns field value pairs ordered by field
+	HashGetAll(ctx context.Context, key string) ([]string, error)
+	// HashDel returns the number of removed fields
+	HashDel(ctx context.Context, key string, fields ...string) (int, error)
+	HashLen(ctx context.Context, key string) (int, error)
+	// HashUpdate replaces the value of the field with the result of fn
+	// under the engine lock
+	HashUpdate(ctx context.Context, key, field string, fn UpdateFunc) (string, error)
+}
+
+func pairsSize(pairs []string) int64 {
+	var size int64
+	for _, s := range pairs {
+		size += int64(len(s))
+	}
+
+	return size + int64(len(pairs)/2)*fieldOverhead
+}
+
+func (s *shard) hashSet(key string, pairs []string, now int64) (int, error) {
+	if len(pairs)%2 != 0 {
+		return 0, errInvalidItems
+	}
+
+	var added int
+	err := s.collection(key, KindHash, pairsSize(pairs), func() object { return newHash() }, now, func(obj object) error {
+		h := obj.(*hash)
+		for i := 0; i < len(pairs); i += 2 {
+			if h.set(pairs[i], pairs[i+1]) {
+				added++
+			}
+		}
+		return nil
+	})
+
+	return added, err
+}
+
+func (s *shard) hashGet(key string, fields []string, now int64) ([]string, []bool, error) {
+	values := make([]string, len(fields))
+	exists := make([]bool, len(fields))
+	err := s.collection(key, KindHash, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		h := obj.(*hash)
+		for i, field := range fields {
+			values[i], exists[i] = h.fields[field]
+		}
+		return nil
+	})
+
+	return values, exists, err
+}
+
+func (s *shard) hashExists(key, field string, now int64) (bool, error) {
+	var exists bool
+	err := s.collection(key, KindHash, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			_, exists = obj.(*hash).fields[field]
+		}
+		return nil
+	})
+
+	return exists, err
+}
+
+func (s *shard) hashGetAll(key string, now int64) ([]string, error) {
+	var pairs []string
+	err := s.collection(key, KindHash, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			pairs = obj.items()
+		}
+		return nil
+	})
+
+	return pairs, err
+}
+
+func (s *shard) hashDel(key string, fields []string, now int64) (int, error) {
+	var removed int
+	err := s.collection(key, KindHash, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		h := obj.(*hash)
+		for _, field := range fields {
+			if h.del(field) {
+				removed++
+			}
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+func (s *shard) hashLen(key string, now int64) (int, error) {
+	var length int
+	err := s.collection(key, KindHash, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			length = obj.len()
+		}
+		return nil
+	})
+
+	return length, err
+}
+
+func (s *shard) hashUpdate(key, field string, fn UpdateFunc, now int64) (string, error) {
+	var value string
+	err := s.collection(key, KindHash, pairsSize([]string{field, ""}), func() object { return newHash() }, now, func(obj object) error {
+		h := obj.(*hash)
+		old, exists := h.fields[field]
+
+		var err error
+		value, err = fn(old, exists)
+		if err != nil {
+			return err
+		}
+
+		h.set(field, value)
+		return nil
+	})
+
+	return value, err
+}
+
+func (e *Engine) HashSet(ctx context.Context, key string, pairs ...string) (int, error) {
+	return e.hashSet(key, pairs, time.Now().UnixNano())
+}
+
+func (e *Engine) HashGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error) {
+	return e.hashGet(key, fields, time.Now().UnixNano())
+}
+
+func (e *Engine) HashExists(ctx context.Context, key, field string) (bool, error) {
+	return e.hashExists(key, field, time.Now().UnixNano())
+}
+
+func (e *Engine) HashGetAll(ctx context.Context, key string) ([]string, error) {
+	return e.hashGetAll(key, time.Now().UnixNano())
+}
+
+func (e *Engine) HashDel(ctx context.Context, key string, fields ...string) (int, error) {
+	return e.hashDel(key, fields, time.Now().UnixNano())
+}
+
+func (e *Engine) HashLen(ctx context.Context, key string) (int, error) {
+	return e.hashLen(key, time.Now().UnixNano())
+}
+
+func (e *Engine) HashUpdate(ctx context.Context, key, field string, fn UpdateFunc) (string, error) {
+	return e.hashUpdate(key, field, fn, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashSet(ctx context.Context, key string, pairs ...string) (int, error) {
+	target := e.shardFor(key)
+
+	var added int
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		added, err = target.hashSet(key, pairs, time.Now().UnixNano())
+		return pairsSize(pairs), err
+	})
+
+	return added, err
+}
+
+func (e *ShardedEngine) HashGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error) {
+	return e.shardFor(key).hashGet(key, fields, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashExists(ctx context.Context, key, field string) (bool, error) {
+	return e.shardFor(key).hashExists(key, field, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashGetAll(ctx context.Context, key string) ([]string, error) {
+	return e.shardFor(key).hashGetAll(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashDel(ctx context.Context, key string, fields ...string) (int, error) {
+	return e.shardFor(key).hashDel(key, fields, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashLen(ctx context.Context, key string) (int, error) {
+	return e.shardFor(key).hashLen(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) HashUpdate(ctx context.Context, key, field string, fn UpdateFunc) (string, error) {
+	target := e.shardFor(key)
+
+	var value string
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		value, err = target.hashUpdate(key, field, fn, time.Now().UnixNano())
+		return pairsSize([]string{field, value}), err
+	})
+
+	return value, err
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestEngineHashes(t *testing.T) {
+	ctx := context.Background()
+
+	engines := []struct {
+		name   string
+		hashes interface {
+			Hashes
+			Get(ctx context.Context, key string) (string, bool, error)
+			UsedMemory() int64
+		}
+	}{
+		{"engine", NewEngine()},
+		{"sharded", NewShardedEngine(4)},
+	}
+
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			added, err := e.hashes.HashSet(ctx, "user", "name", "alice", "age", "30")
+			require.NoError(t, err)
+			assert.Equal(t, 2, added)
+
+			added, err = e.hashes.HashSet(ctx, "user", "name", "bob", "city", "paris")
+			require.NoError(t, err)
+			assert.Equal(t, 1, added)
+
+			values, found, err := e.hashes.HashGet(ctx, "user", "name", "missing", "age")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bob", "", "30"}, values)
+			assert.Equal(t, []bool{true, false, true}, found)
+
+			exists, err := e.hashes.HashExists(ctx, "user", "city")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			pairs, err := e.hashes.HashGetAll(ctx, "user")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"age", "30", "city", "paris", "name", "bob"}, pairs)
+
+			value, err := e.hashes.HashUpdate(ctx, "user", "visits", func(value string, exists bool) (string, error) {
+				assert.False(t, exists)
+				return "1", nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "1", value)
+
+			failure := errors.New("failure")
+			_, err = e.hashes.HashUpdate(ctx, "other", "field", func(string, bool) (string, error) {
+				return "", failure
+			})
+			assert.ErrorIs(t, err, failure)
+
+			length, err := e.hashes.HashLen(ctx, "other")
+			require.NoError(t, err)
+			assert.Zero(t, length)
+
+			_, _, err = e.hashes.Get(ctx, "user")
+			assert.ErrorIs(t, err, ports.ErrWrongType)
+
+			removed, err := e.hashes.HashDel(ctx, "user", "age", "city", "name", "visits", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, 4, removed)
+
+			// the empty hash is removed with its key
+			assert.Zero(t, e.hashes.UsedMemory())
+
+			_, err = e.hashes.HashSet(ctx, "user", "odd")
+			assert.ErrorIs(t, err, errInvalidItems)
+		})
+	}
+}
+
+func TestDumpAndRestoreHashes(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	_, err := engine.HashSet(ctx, "user", "name", "alice", "age", "30")
+	require.NoError(t, err)
+
+	entries, err := engine.Dump(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Key: "user", Kind: KindHash, Items: []string{"age", "30", "name", "alice"}}}, entries)
+
+	restored := NewEngine()
+	require.NoError(t, restored.Restore(ctx, entries[0]))
+	assert.Equal(t, engine.UsedMemory(), restored.UsedMemory())
+
+	pairs, err := restored.HashGetAll(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, entries[0].Items, pairs)
+
+	err = restored.Restore(ctx, Entry{Key: "broken", Kind: KindHash, Items: []string{"field"}})
+	assert.ErrorIs(t, err, errInvalidItems)
+}
@@ -0,0 +1,20 @@
+package engine
+
+import "context"
+
+// Iterator walks entries in key order, Next has to be called before reading
+// the first entry. It must be closed after use.
+type Iterator interface {
+	Next() bool
+	Key() string
+	Value() string
+	Err() error
+	Close() error
+}
+
+// Ordered is implemented by engines which keep keys sorted.
+type Ordered interface {
+	// Range iterates over keys in [start, end), an empty end means no
+	// upper bound.
+	Range(ctx context.Context, start, end string) (Iterator, error)
+}
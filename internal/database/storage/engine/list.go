@@ -0,0 +1,305 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// Side is the end of a list.
+type Side uint8
+
+const (
+	Left Side = iota
+	Right
+)
+
+// elementOverhead is the approximate memory taken by a list element besides
+// its bytes.
+const elementOverhead = 16
+
+// list is a deque in a ring buffer, so pushes and pops on both ends and
+// access by index are O(1).
+type list struct {
+	buf   []string
+	head  int
+	count int
+	bytes int64
+}
+
+const minListCapacity = 8
+
+func newList() *list {
+	return &list{buf: make([]string, minListCapacity)}
+}
+
+func (l *list) kind() Kind {
+	return KindList
+}
+
+func (l *list) len() int {
+	return l.count
+}
+
+func (l *list) size() int64 {
+	return l.bytes + int64(l.count)*elementOverhead
+}
+
+func (l *list) items() []string {
+	return l.slice(0, l.count)
+}
+
+func (l *list) at(i int) string {
+	return l.buf[(l.head+i)%len(l.buf)]
+}
+
+func (l *list) push(side Side, values ...string) {
+	for _, value := range values {
+		if l.count == len(l.buf) {
+			l.resize(2 * len(l.buf))
+		}
+
+		if side == Left {
+			l.head = (l.head - 1 + len(l.buf)) % len(l.buf)
+			l.buf[l.head] = value
+		} else {
+			l.buf[(l.head+l.count)%len(l.buf)] = value
+		}
+
+		l.count++
+		l.bytes += int64(len(value))
+	}
+}
+
+func (l *list) pop(side Side) (string, bool) {
+	if l.count == 0 {
+		return "", false
+	}
+
+	var i int
+	if side == Left {
+		i = l.head
+		l.head = (l.head + 1) % len(l.buf)
+	} else {
+		i = (l.head + l.count - 1) % len(l.buf)
+	}
+
+	value := l.buf[i]
+	l.buf[i] = ""
+	l.count--
+	l.bytes -= int64(len(value))
+
+	if len(l.buf) > minListCapacity && l.count < len(l.buf)/4 {
+		l.resize(len(l.buf) / 2)
+	}
+
+	return value, true
+}
+
+// slice copies elements in [start, stop).
+func (l *list) slice(start, stop int) []string {
+	values := make([]string, 0, stop-start)
+	for i := start; i < stop; i++ {
+		values = append(values, l.at(i))
+	}
+
+	return values
+}
+
+// trim keeps elements in [start, stop) only.
+func (l *list) trim(start, stop int) {
+	kept := l.slice(start, stop)
+
+	l.buf = make([]string, max(minListCapacity, len(kept)))
+	l.head, l.count, l.bytes = 0, 0, 0
+	l.push(Right, kept...)
+}
+
+func (l *list) resize(capacity int) {
+	buf := make([]string, capacity)
+	for i := range l.count {
+		buf[i] = l.at(i)
+	}
+
+	l.buf = buf
+	l.head = 0
+}
+
+// listBounds converts redis style inclusive indexes, negative ones count
+// from the end, into a half-open range clamped to the list.
+func listBounds(start, stop, length int) (int, int) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	start = max(start, 0)
+	stop = min(stop+1, length)
+
+	if start >= stop {
+		return 0, 0
+	}
+
+	return start, stop
+}
+
+// Lists is implemented by engines which store list values. Operations on a
+// key holding a value of another kind fail with ports.ErrWrongType.
+type Lists interface {
+	// Push adds values to the side of the list and returns its new length
+	Push(ctx context.Context, key string, side Side, values ...string) (int, error)
+	Pop(ctx context.Context, key string, side Side) (string, bool, error)
+	// ListRange returns elements between inclusive indexes, negative
+	// indexes count from the end
+	ListRange(ctx context.Context, key string, start, stop int) ([]string, error)
+	ListLen(ctx context.Context, key string) (int, error)
+	ListIndex(ctx context.Context, key string, index int) (string, bool, error)
+	ListTrim(ctx context.Context, key string, start, stop int) error
+}
+
+func pushSize(values []string) int64 {
+	var size int64
+	for _, value := range values {
+		size += int64(len(value)) + elementOverhead
+	}
+
+	return size
+}
+
+func (s *shard) push(key string, side Side, values []string, now int64) (int, error) {
+	var length int
+	err := s.collection(key, KindList, pushSize(values), func() object { return newList() }, now, func(obj object) error {
+		l := obj.(*list)
+		l.push(side, values...)
+		length = l.len()
+		return nil
+	})
+
+	return length, err
+}
+
+func (s *shard) pop(key string, side Side, now int64) (string, bool, error) {
+	var value string
+	var ok bool
+	err := s.collection(key, KindList, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			value, ok = obj.(*list).pop(side)
+		}
+		return nil
+	})
+
+	return value, ok, err
+}
+
+func (s *shard) listRange(key string, start, stop int, now int64) ([]string, error) {
+	var values []string
+	err := s.collection(key, KindList, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			l := obj.(*list)
+			values = l.slice(listBounds(start, stop, l.len()))
+		}
+		return nil
+	})
+
+	return values, err
+}
+
+func (s *shard) listLen(key string, now int64) (int, error) {
+	var length int
+	err := s.collection(key, KindList, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			length = obj.len()
+		}
+		return nil
+	})
+
+	return length, err
+}
+
+func (s *shard) listIndex(key string, index int, now int64) (string, bool, error) {
+	var value string
+	var ok bool
+	err := s.collection(key, KindList, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		l := obj.(*list)
+		if index < 0 {
+			index += l.len()
+		}
+		if index >= 0 && index < l.len() {
+			value, ok = l.at(index), true
+		}
+		return nil
+	})
+
+	return value, ok, err
+}
+
+func (s *shard) listTrim(key string, start, stop int, now int64) error {
+	return s.collection(key, KindList, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			l := obj.(*list)
+			l.trim(listBounds(start, stop, l.len()))
+		}
+		return nil
+	})
+}
+
+func (e *Engine) Push(ctx context.Context, key string, side Side, values ...string) (int, error) {
+	return e.push(key, side, values, time.Now().UnixNano())
+}
+
+func (e *Engine) Pop(ctx context.Context, key string, side Side) (string, bool, error) {
+	return e.pop(key, side, time.Now().UnixNano())
+}
+
+func (e *Engine) ListRange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	return e.listRange(key, start, stop, time.Now().UnixNano())
+}
+
+func (e *Engine) ListLen(ctx context.Context, key string) (int, error) {
+	return e.listLen(key, time.Now().UnixNano())
+}
+
+func (e *Engine) ListIndex(ctx context.Context, key string, index int) (string, bool, error) {
+	return e.listIndex(key, index, time.Now().UnixNano())
+}
+
+func (e *Engine) ListTrim(ctx context.Context, key string, start, stop int) error {
+	return e.listTrim(key, start, stop, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) Push(ctx context.Context, key string, side Side, values ...string) (int, error) {
+	target := e.shardFor(key)
+
+	var length int
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		length, err = target.push(key, side, values, time.Now().UnixNano())
+		return pushSize(values), err
+	})
+
+	return length, err
+}
+
+func (e *ShardedEngine) Pop(ctx context.Context, key string, side Side) (string, bool, error) {
+	return e.shardFor(key).pop(key, side, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ListRange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	return e.shardFor(key).listRange(key, start, stop, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ListLen(ctx context.Context, key string) (int, error) {
+	return e.shardFor(key).listLen(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ListIndex(ctx context.Context, key string, index int) (string, bool, error) {
+	return e.shardFor(key).listIndex(key, index, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ListTrim(ctx context.Context, key string, start, stop int) error {
+	return e.shardFor(key).listTrim(key, start, stop, time.Now().UnixNano())
+}
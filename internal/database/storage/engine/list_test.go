@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestListBounds(t *testing.T) {
+	tests := []struct {
+		start, stop, length int
+		wantStart, wantStop int
+	}{
+		{0, -1, 5, 0, 5},
+		{1, 2, 5, 1, 3},
+		{-2, -1, 5, 3, 5},
+		{-10, 10, 5, 0, 5},
+		{3, 1, 5, 0, 0},
+		{5, 10, 5, 0, 0},
+		{0, -1, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		start, stop := listBounds(tt.start, tt.stop, tt.length)
+		assert.Equal(t, tt.wantStart, start, "start of %v", tt)
+		assert.Equal(t, tt.wantStop, stop, "stop of %v", tt)
+	}
+}
+
+func TestListRingBuffer(t *testing.T) {
+	l := newList()
+
+	for i := range 100 {
+		l.push(Right, strconv.Itoa(i))
+		l.push(Left, strconv.Itoa(-i-1))
+	}
+	assert.Equal(t, 200, l.len())
+	assert.Equal(t, "-100", l.at(0))
+	assert.Equal(t, "99", l.at(199))
+
+	for i := range 90 {
+		value, ok := l.pop(Left)
+		assert.True(t, ok)
+		assert.Equal(t, strconv.Itoa(i-100), value)
+	}
+	assert.Equal(t, []string{"-10", "-9"}, l.slice(0, 2))
+
+	l.trim(listBounds(-3, -1, l.len()))
+	assert.Equal(t, []string{"97", "98", "99"}, l.items())
+	assert.Equal(t, int64(3*2+3*elementOverhead), l.size())
+}
+
+func TestEngineLists(t *testing.T) {
+	ctx := context.Background()
+
+	engines := []struct {
+		name  string
+		lists interface {
+			Lists
+			Get(ctx context.Context, key string) (string, bool, error)
+			Set(ctx context.Context, key, value string) error
+		}
+	}{
+		{"engine", NewEngine()},
+		{"sharded", NewShardedEngine(4)},
+	}
+
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			length, err := e.lists.Push(ctx, "list", Right, "b", "c")
+			require.NoError(t, err)
+			assert.Equal(t, 2, length)
+
+			length, err = e.lists.Push(ctx, "list", Left, "a")
+			require.NoError(t, err)
+			assert.Equal(t, 3, length)
+
+			values, err := e.lists.ListRange(ctx, "list", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b", "c"}, values)
+
+			value, ok, err := e.lists.ListIndex(ctx, "list", -1)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "c", value)
+
+			_, ok, err = e.lists.ListIndex(ctx, "list", 3)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			_, _, err = e.lists.Get(ctx, "list")
+			assert.ErrorIs(t, err, ports.ErrWrongType)
+
+			require.NoError(t, e.lists.Set(ctx, "string", "value"))
+			_, err = e.lists.Push(ctx, "string", Left, "a")
+			assert.ErrorIs(t, err, ports.ErrWrongType)
+			_, err = e.lists.ListLen(ctx, "string")
+			assert.ErrorIs(t, err, ports.ErrWrongType)
+
+			require.NoError(t, e.lists.ListTrim(ctx, "list", 1, 1))
+			values, err = e.lists.ListRange(ctx, "list", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b"}, values)
+
+			value, ok, err = e.lists.Pop(ctx, "list", Right)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "b", value)
+
+			// the empty list is removed with its key
+			length, err = e.lists.ListLen(ctx, "list")
+			require.NoError(t, err)
+			assert.Zero(t, length)
+
+			_, ok, err = e.lists.Pop(ctx, "list", Left)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, e.lists.Set(ctx, "list", "value"))
+		})
+	}
+}
+
+func TestListMemoryAccounting(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	_, err := engine.Push(ctx, "list", Right, "a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("list")+itemOverhead)+3*(1+elementOverhead), engine.UsedMemory())
+
+	require.NoError(t, engine.ListTrim(ctx, "list", 5, 10))
+	assert.Zero(t, engine.UsedMemory())
+	assert.Zero(t, engine.len())
+
+	engine = NewEngine(WithMaxMemory(100, NoEviction))
+	_, err = engine.Push(ctx, "list", Right, "a")
+	require.NoError(t, err)
+	_, err = engine.Push(ctx, "list", Right, string(make([]byte, 100)))
+	assert.ErrorIs(t, err, ports.ErrOutOfMemory)
+}
+
+func TestDumpAndRestoreLists(t *testing.T) {
+	ctx := context.Background()
+	engine := NewShardedEngine(2)
+
+	_, err := engine.Push(ctx, "list", Right, "a", "b")
+	require.NoError(t, err)
+	require.NoError(t, engine.Set(ctx, "string", "value"))
+
+	entries, err := engine.Dump(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Entry{
+		{Key: "list", Kind: KindList, Items: []string{"a", "b"}},
+		{Key: "string", Value: "value"},
+	}, entries)
+
+	restored := NewShardedEngine(2)
+	for _, entry := range entries {
+		require.NoError(t, restored.Restore(ctx, entry))
+	}
+
+	values, err := restored.ListRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, values)
+	assert.Equal(t, engine.UsedMemory(), restored.UsedMemory())
+
+	err = NewOrderedEngine().Restore(ctx, Entry{Key: "list", Kind: KindList, Items: []string{"a"}})
+	assert.ErrorIs(t, err, errUnsupportedKind)
+}
@@ -0,0 +1,85 @@
+package lsm
+
+import "hash/fnv"
+
+// bloom is a per-table filter which lets lookups skip tables that surely
+// do not contain the key.
+type bloom struct {
+	bits   []byte
+	hashes uint8
+}
+
+const (
+	bloomBitsPerKey = 10
+	bloomHashes     = 7
+	bloomMinBits    = 64
+)
+
+func bloomHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+func newBloom(keyHashes []uint64) *bloom {
+	bitsNum := len(keyHashes) * bloomBitsPerKey
+	if bitsNum < bloomMinBits {
+		bitsNum = bloomMinBits
+	}
+
+	b := &bloom{
+		bits:   make([]byte, (bitsNum+7)/8),
+		hashes: bloomHashes,
+	}
+
+	for _, hash := range keyHashes {
+		b.add(hash)
+	}
+
+	return b
+}
+
+// add and mayContain use double hashing: the i-th probe is h1 + i*h2.
+func (b *bloom) add(hash uint64) {
+	bitsNum := uint32(len(b.bits) * 8)
+	h1, h2 := uint32(hash), uint32(hash>>32)
+
+	for i := range uint32(b.hashes) {
+		bit := (h1 + i*h2) % bitsNum
+		b.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+func (b *bloom) mayContain(key string) bool {
+	if len(b.bits) == 0 {
+		return true
+	}
+
+	hash := bloomHash(key)
+	bitsNum := uint32(len(b.bits) * 8)
+	h1, h2 := uint32(hash), uint32(hash>>32)
+
+	for i := range uint32(b.hashes) {
+		bit := (h1 + i*h2) % bitsNum
+		if b.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *bloom) encode(buf []byte) []byte {
+	buf = append(buf, b.hashes)
+
+	return append(buf, b.bits...)
+}
+
+func decodeBloom(data []byte) (*bloom, error) {
+	if len(data) < 1 {
+		return nil, errCorruptedTable
+	}
+
+	return &bloom{hashes: data[0], bits: data[1:]}, nil
+}
@@ -0,0 +1,276 @@
+package lsm
+
+import (
+	"fmt"
+	"os"
+)
+
+// compaction merges tables of one level with the overlapping tables of the
+// next level, the result replaces all of them in the next level.
+type compaction struct {
+	level    int
+	inputs   []*table
+	overlaps []*table
+}
+
+func levelMaxSize(level int) int64 {
+	size := int64(baseLevelSize)
+	for i := 1; i < level; i++ {
+		size *= levelSizeMultiplier
+	}
+
+	return size
+}
+
+func levelSize(tables []*table) int64 {
+	var size int64
+	for _, t := range tables {
+		size += t.size
+	}
+
+	return size
+}
+
+func keyRange(tables []*table) (string, string) {
+	minKey, maxKey := tables[0].minKey(), tables[0].maxKey
+	for _, t := range tables[1:] {
+		if t.minKey() < minKey {
+			minKey = t.minKey()
+		}
+		if t.maxKey > maxKey {
+			maxKey = t.maxKey
+		}
+	}
+
+	return minKey, maxKey
+}
+
+func overlapping(tables []*table, minKey, maxKey string) []*table {
+	var result []*table
+	for _, t := range tables {
+		if t.overlaps(minKey, maxKey) {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
+
+func (l *LSM) needsCompaction() bool {
+	return l.pickCompaction() != nil
+}
+
+// pickCompaction prefers level 0 since its tables slow down every lookup,
+// then the first level which is over its size limit.
+func (l *LSM) pickCompaction() *compaction {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if len(l.levels[0]) >= l0CompactionTrigger {
+		inputs := append([]*table(nil), l.levels[0]...)
+		minKey, maxKey := keyRange(inputs)
+
+		return &compaction{
+			level:    0,
+			inputs:   inputs,
+			overlaps: overlapping(l.levels[1], minKey, maxKey),
+		}
+	}
+
+	for level := 1; level < maxLevels-1; level++ {
+		tables := l.levels[level]
+		if levelSize(tables) <= levelMaxSize(level) {
+			continue
+		}
+
+		input := tables[0]
+		for _, t := range tables {
+			if t.minKey() > l.compactPointers[level] {
+				input = t
+				break
+			}
+		}
+
+		return &compaction{
+			level:    level,
+			inputs:   []*table{input},
+			overlaps: overlapping(l.levels[level+1], input.minKey(), input.maxKey),
+		}
+	}
+
+	return nil
+}
+
+func (l *LSM) compact(c *compaction) error {
+	output := c.level + 1
+
+	// level 0 tables may overlap, the newest one goes first
+	sources := make([]iterator, 0, len(c.inputs)+len(c.overlaps))
+	for i := len(c.inputs) - 1; i >= 0; i-- {
+		sources = append(sources, c.inputs[i].iterator())
+	}
+	for _, t := range c.overlaps {
+		sources = append(sources, t.iterator())
+	}
+
+	it, err := newMergingIterator(sources)
+	if err != nil {
+		return fmt.Errorf("compacting level %d: %w", c.level, err)
+	}
+
+	outputs, err := l.writeTables(it, l.isBottom(output))
+	if err != nil {
+		return fmt.Errorf("compacting level %d: %w", c.level, err)
+	}
+
+	err = l.install(c, outputs)
+	if err != nil {
+		removeTables(outputs)
+		return fmt.Errorf("compacting level %d: %w", c.level, err)
+	}
+
+	removeTables(c.inputs)
+	removeTables(c.overlaps)
+
+	return nil
+}
+
+// isBottom reports whether no deeper level has data, tombstones written
+// there shadow nothing and can be dropped.
+func (l *LSM) isBottom(level int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, tables := range l.levels[level+1:] {
+		if len(tables) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeTables splits the merged entries into tables of about TableSize.
+func (l *LSM) writeTables(it iterator, dropTombstones bool) ([]*table, error) {
+	var outputs []*table
+	var w *tableWriter
+	var num uint64
+
+	for {
+		key, entry, err := it.next()
+		if isEOF(err) {
+			break
+		}
+		if err != nil {
+			abortWriter(w)
+			removeTables(outputs)
+			return nil, err
+		}
+
+		if entry.deleted && dropTombstones {
+			continue
+		}
+
+		if w == nil {
+			l.mu.Lock()
+			num = l.allocNum()
+			l.mu.Unlock()
+
+			w, err = createTable(l.tablePath(num))
+			if err != nil {
+				removeTables(outputs)
+				return nil, err
+			}
+		}
+
+		err = w.add(key, entry)
+		if err != nil {
+			abortWriter(w)
+			removeTables(outputs)
+			return nil, err
+		}
+
+		if w.size() >= l.opts.TableSize {
+			t, err := w.finish(num)
+			if err != nil {
+				abortWriter(w)
+				removeTables(outputs)
+				return nil, err
+			}
+
+			outputs = append(outputs, t)
+			w = nil
+		}
+	}
+
+	if w != nil {
+		t, err := w.finish(num)
+		if err != nil {
+			abortWriter(w)
+			removeTables(outputs)
+			return nil, err
+		}
+
+		outputs = append(outputs, t)
+	}
+
+	return outputs, nil
+}
+
+// install replaces compacted tables with the outputs and persists the new
+// set of tables in the manifest.
+func (l *LSM) install(c *compaction, outputs []*table) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	output := c.level + 1
+	prevInput, prevOutput := l.levels[c.level], l.levels[output]
+
+	l.levels[c.level] = without(l.levels[c.level], c.inputs)
+	l.levels[output] = append(without(l.levels[output], c.overlaps), outputs...)
+	sortTables(l.levels[output])
+
+	err := writeManifest(l.opts.DataDir, l.manifestLocked())
+	if err != nil {
+		l.levels[c.level], l.levels[output] = prevInput, prevOutput
+		return err
+	}
+
+	if c.level > 0 {
+		l.compactPointers[c.level] = c.inputs[len(c.inputs)-1].maxKey
+	}
+
+	return nil
+}
+
+func without(tables, removed []*table) []*table {
+	result := make([]*table, 0, len(tables))
+	for _, t := range tables {
+		found := false
+		for _, r := range removed {
+			if t == r {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
+
+func removeTables(tables []*table) {
+	for _, t := range tables {
+		t.close()
+		os.Remove(t.path)
+	}
+}
+
+func abortWriter(w *tableWriter) {
+	if w != nil {
+		w.abort()
+	}
+}
@@ -0,0 +1,48 @@
+package lsm
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/utils"
+)
+
+const Type = "lsm"
+
+type Config struct {
+	DataDir      string `mapstructure:"data_dir"`
+	MemtableSize string `mapstructure:"memtable_size"`
+	TableSize    string `mapstructure:"table_size"`
+}
+
+func init() {
+	engine.Register(Type, func(cfg Config, logger *slog.Logger) (engine.Interface, error) {
+		opts := &Opts{
+			DataDir: cfg.DataDir,
+		}
+
+		if cfg.MemtableSize != "" {
+			memtableSize, err := utils.ParseSize(cfg.MemtableSize)
+			if err != nil {
+				return nil, fmt.Errorf("parsing memtable size: %w", err)
+			}
+			opts.MemtableSize = memtableSize
+		}
+
+		if cfg.TableSize != "" {
+			tableSize, err := utils.ParseSize(cfg.TableSize)
+			if err != nil {
+				return nil, fmt.Errorf("parsing table size: %w", err)
+			}
+			opts.TableSize = tableSize
+		}
+
+		l, err := NewLSM(logger, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return l, nil
+	})
+}
@@ -0,0 +1,13 @@
+package lsm
+
+import "errors"
+
+var (
+	errInvalidLogger    = errors.New("invalid logger")
+	errClosed           = errors.New("lsm engine is closed")
+	errCorruptedTable   = errors.New("corrupted sstable")
+	errCorruptedEntry   = errors.New("corrupted sstable entry")
+	errInvalidManifest  = errors.New("invalid manifest")
+	errUnsortedKeys     = errors.New("keys are added out of order")
+	errBackgroundFailed = errors.New("background flush failed")
+)
@@ -0,0 +1,122 @@
+package lsm
+
+import (
+	"bufio"
+	"io"
+)
+
+type iterator interface {
+	// next returns io.EOF after the last entry
+	next() (string, memEntry, error)
+}
+
+type tableIterator struct {
+	reader *bufio.Reader
+}
+
+func (it *tableIterator) next() (string, memEntry, error) {
+	// a clean end of data is detected before reading a new entry
+	_, err := it.reader.Peek(1)
+	if err != nil {
+		return "", memEntry{}, err
+	}
+
+	return readEntry(it.reader)
+}
+
+type memtableIterator struct {
+	memtable *memtable
+	keys     []string
+	pos      int
+}
+
+func newMemtableIterator(m *memtable) *memtableIterator {
+	return &memtableIterator{
+		memtable: m,
+		keys:     m.sortedKeys(),
+	}
+}
+
+func (it *memtableIterator) next() (string, memEntry, error) {
+	if it.pos == len(it.keys) {
+		return "", memEntry{}, io.EOF
+	}
+
+	key := it.keys[it.pos]
+	it.pos++
+
+	return key, it.memtable.entries[key], nil
+}
+
+// mergingIterator merges sorted sources, sources are ordered from the
+// newest to the oldest and for equal keys only the newest entry is returned.
+type mergingIterator struct {
+	sources []iterator
+	heads   []head
+}
+
+type head struct {
+	key   string
+	entry memEntry
+	valid bool
+}
+
+func newMergingIterator(sources []iterator) (*mergingIterator, error) {
+	it := &mergingIterator{
+		sources: sources,
+		heads:   make([]head, len(sources)),
+	}
+
+	for i := range sources {
+		if err := it.advance(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return it, nil
+}
+
+func (it *mergingIterator) advance(i int) error {
+	key, entry, err := it.sources[i].next()
+	if err == io.EOF {
+		it.heads[i] = head{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	it.heads[i] = head{key: key, entry: entry, valid: true}
+
+	return nil
+}
+
+func (it *mergingIterator) next() (string, memEntry, error) {
+	smallest := -1
+	for i, h := range it.heads {
+		if h.valid && (smallest < 0 || h.key < it.heads[smallest].key) {
+			smallest = i
+		}
+	}
+
+	if smallest < 0 {
+		return "", memEntry{}, io.EOF
+	}
+
+	key, entry := it.heads[smallest].key, it.heads[smallest].entry
+
+	// older versions of the same key are skipped
+	for i := range it.heads {
+		if it.heads[i].valid && it.heads[i].key == key {
+			if err := it.advance(i); err != nil {
+				return "", memEntry{}, err
+			}
+		}
+	}
+
+	return key, entry, nil
+}
+
+func isEOF(err error) bool {
+	return err == io.EOF
+}
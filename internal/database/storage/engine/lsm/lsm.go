@@ -0,0 +1,583 @@
+package lsm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+// LSM is a disk-backed engine. Writes go to a memtable which is flushed
+// into level 0 tables when it grows over MemtableSize, background
+// compaction merges tables into deeper levels with non-overlapping tables.
+//
+// Unflushed memtables live only in memory, the storage WAL has to be
+// enabled to survive crashes. The manifest keeps the LSN of the last WAL
+// record in tables, so only the records after it are replayed.
+type LSM struct {
+	opts   Opts
+	logger *slog.Logger
+
+	mu *sync.RWMutex
+	// signaled when an immutable memtable is flushed
+	flushed *sync.Cond
+	mem     *memtable
+	// memtables waiting for the flush, ordered from the oldest
+	imm []*memtable
+	// level 0 tables may overlap and are ordered from the oldest, tables of
+	// deeper levels are sorted by keys and do not overlap
+	levels  [][]*table
+	nextNum uint64
+	// lsn of the last WAL record in flushed tables
+	lsn uint64
+	// the max key of the last compacted table per level, compaction goes
+	// round the key space
+	compactPointers []string
+	// the last background error, writers fail instead of waiting forever
+	bgErr  error
+	closed bool
+
+	work chan struct{}
+	done chan struct{}
+	wg   *sync.WaitGroup
+}
+
+type Opts struct {
+	DataDir      string
+	MemtableSize int64
+	TableSize    int64
+}
+
+const (
+	defaultDataDir      = "./data/lsm"
+	defaultMemtableSize = 4 << 20
+	defaultTableSize    = 2 << 20
+
+	maxLevels = 7
+	// memtables waiting for the flush before writers are blocked
+	maxImmutable = 2
+	// number of level 0 tables which triggers their compaction
+	l0CompactionTrigger = 4
+	baseLevelSize       = 10 << 20
+	levelSizeMultiplier = 10
+
+	retryInterval = time.Second
+)
+
+func NewLSM(logger *slog.Logger, opts *Opts) (*LSM, error) {
+	if logger == nil {
+		return nil, errInvalidLogger
+	}
+
+	mu := &sync.RWMutex{}
+	l := &LSM{
+		opts:    prepareOpts(opts),
+		logger:  logger,
+		mu:      mu,
+		flushed: sync.NewCond(mu),
+		mem:     newMemtable(),
+		levels:  make([][]*table, maxLevels),
+
+		compactPointers: make([]string, maxLevels),
+		work:            make(chan struct{}, 1),
+		done:            make(chan struct{}),
+		wg:              &sync.WaitGroup{},
+	}
+
+	err := os.MkdirAll(l.opts.DataDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("creating lsm directory: %w", err)
+	}
+
+	err = l.load()
+	if err != nil {
+		l.closeTables()
+		return nil, err
+	}
+
+	l.wg.Add(1)
+	go l.run()
+
+	// tables left from the previous run may need compaction
+	l.schedule()
+
+	return l, nil
+}
+
+func prepareOpts(opts *Opts) Opts {
+	prepared := Opts{}
+	if opts != nil {
+		prepared = *opts
+	}
+
+	if prepared.DataDir == "" {
+		prepared.DataDir = defaultDataDir
+	}
+	if prepared.MemtableSize <= 0 {
+		prepared.MemtableSize = defaultMemtableSize
+	}
+	if prepared.TableSize <= 0 {
+		prepared.TableSize = defaultTableSize
+	}
+
+	return prepared
+}
+
+// load opens tables listed in the manifest and removes unlisted ones.
+func (l *LSM) load() error {
+	m, err := readManifest(l.opts.DataDir)
+	if err != nil {
+		return err
+	}
+
+	live := make(map[uint64]struct{})
+	for level, nums := range m.levels {
+		for _, num := range nums {
+			t, err := openTable(l.tablePath(num), num)
+			if err != nil {
+				return err
+			}
+
+			l.levels[level] = append(l.levels[level], t)
+			live[num] = struct{}{}
+		}
+	}
+
+	for level := 1; level < maxLevels; level++ {
+		sortTables(l.levels[level])
+	}
+
+	l.nextNum = m.nextNum
+	l.lsn = m.lsn
+
+	entries, err := os.ReadDir(l.opts.DataDir)
+	if err != nil {
+		return fmt.Errorf("reading lsm directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, tableExt) {
+			continue
+		}
+
+		num, err := strconv.ParseUint(strings.TrimSuffix(name, tableExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := live[num]; !ok {
+			os.Remove(filepath.Join(l.opts.DataDir, name))
+		}
+		if num >= l.nextNum {
+			l.nextNum = num + 1
+		}
+	}
+
+	return nil
+}
+
+func (l *LSM) Get(ctx context.Context, key string) (string, bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return "", false, errClosed
+	}
+
+	entry, ok, err := l.getLocked(key)
+	if err != nil || !ok || entry.deleted {
+		return "", false, err
+	}
+
+	return entry.value, true, nil
+}
+
+// getLocked looks the key up from the newest data to the oldest, the second
+// result reports whether any record was found, a tombstone included.
+func (l *LSM) getLocked(key string) (memEntry, bool, error) {
+	if entry, ok := l.mem.get(key); ok {
+		return entry, true, nil
+	}
+
+	for i := len(l.imm) - 1; i >= 0; i-- {
+		if entry, ok := l.imm[i].get(key); ok {
+			return entry, true, nil
+		}
+	}
+
+	// tables are read under the read lock, so compaction removes them only
+	// after readers are gone
+	level0 := l.levels[0]
+	for i := len(level0) - 1; i >= 0; i-- {
+		entry, ok, err := level0[i].get(key)
+		if err != nil || ok {
+			return entry, ok, err
+		}
+	}
+
+	for _, tables := range l.levels[1:] {
+		i := sort.Search(len(tables), func(i int) bool {
+			return tables[i].maxKey >= key
+		})
+		if i == len(tables) {
+			continue
+		}
+
+		entry, ok, err := tables[i].get(key)
+		if err != nil || ok {
+			return entry, ok, err
+		}
+	}
+
+	return memEntry{}, false, nil
+}
+
+func (l *LSM) Set(ctx context.Context, key, value string) error {
+	return l.write(key, memEntry{value: value})
+}
+
+// Del looks the key up first to report whether it existed, a tombstone is
+// written only for existing keys.
+func (l *LSM) Del(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.waitLocked()
+	if err != nil {
+		return false, err
+	}
+
+	entry, ok, err := l.getLocked(key)
+	if err != nil || !ok || entry.deleted {
+		return false, err
+	}
+	l.putLocked(key, memEntry{deleted: true})
+
+	return true, nil
+}
+
+// Update replaces the value with the result of fn under the engine lock.
+func (l *LSM) Update(ctx context.Context, key string, fn engine.UpdateFunc) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.waitLocked()
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok, err := l.getLocked(key)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := fn(entry.value, ok && !entry.deleted)
+	if err != nil {
+		return "", err
+	}
+
+	l.putLocked(key, memEntry{value: value})
+
+	return value, nil
+}
+
+func (l *LSM) write(key string, entry memEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.waitLocked()
+	if err != nil {
+		return err
+	}
+
+	l.putLocked(key, entry)
+
+	return nil
+}
+
+// waitLocked blocks writers while too many memtables wait for the flush.
+func (l *LSM) waitLocked() error {
+	for len(l.imm) >= maxImmutable && !l.closed {
+		if l.bgErr != nil {
+			return fmt.Errorf("%w: %w", errBackgroundFailed, l.bgErr)
+		}
+
+		l.flushed.Wait()
+	}
+
+	if l.closed {
+		return errClosed
+	}
+
+	return nil
+}
+
+// putLocked switches to a new memtable before the write, so the write and
+// the following Applied with its LSN get into the same memtable.
+func (l *LSM) putLocked(key string, entry memEntry) {
+	if l.mem.size >= l.opts.MemtableSize {
+		l.imm = append(l.imm, l.mem)
+		l.mem = newMemtable()
+		l.schedule()
+	}
+
+	l.mem.put(key, entry.value, entry.deleted)
+}
+
+// Applied tags the memtable with the LSN of the WAL record written last.
+func (l *LSM) Applied(lsn uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mem.lsn = max(l.mem.lsn, lsn)
+}
+
+// DurableLSN returns the LSN of the last WAL record in flushed tables.
+func (l *LSM) DurableLSN() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.lsn
+}
+
+// Close flushes the memtable and closes table files.
+func (l *LSM) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.flushed.Broadcast()
+	l.mu.Unlock()
+
+	close(l.done)
+	l.wg.Wait()
+
+	l.mu.Lock()
+	if len(l.mem.entries) > 0 || l.mem.lsn > l.lsn {
+		l.imm = append(l.imm, l.mem)
+		l.mem = newMemtable()
+	}
+	l.mu.Unlock()
+
+	var err error
+	for l.pendingFlush() && err == nil {
+		err = l.flush()
+	}
+
+	l.closeTables()
+
+	return err
+}
+
+func (l *LSM) closeTables() {
+	for _, tables := range l.levels {
+		for _, t := range tables {
+			t.close()
+		}
+	}
+}
+
+func (l *LSM) schedule() {
+	select {
+	case l.work <- struct{}{}:
+	default:
+	}
+}
+
+func (l *LSM) run() {
+	defer l.wg.Done()
+
+	logAttrs := []any{
+		slog.String("component", "lsm"),
+		slog.String("method", "run"),
+	}
+
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.work:
+		case <-ticker.C:
+			// retries after failures
+			if !l.pendingFlush() && !l.needsCompaction() {
+				continue
+			}
+		}
+
+		err := l.background()
+
+		l.mu.Lock()
+		l.bgErr = err
+		l.flushed.Broadcast()
+		l.mu.Unlock()
+
+		if err != nil {
+			l.logger.Error(err.Error(), logAttrs...)
+		}
+	}
+}
+
+// background flushes all immutable memtables and compacts levels until
+// they fit into their limits.
+func (l *LSM) background() error {
+	for l.pendingFlush() {
+		select {
+		case <-l.done:
+			return nil
+		default:
+		}
+
+		err := l.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-l.done:
+			return nil
+		default:
+		}
+
+		c := l.pickCompaction()
+		if c == nil {
+			return nil
+		}
+
+		err := l.compact(c)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (l *LSM) pendingFlush() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.imm) > 0
+}
+
+// flush writes the oldest immutable memtable into a level 0 table.
+func (l *LSM) flush() error {
+	l.mu.Lock()
+	m := l.imm[0]
+	num := l.allocNum()
+	l.mu.Unlock()
+
+	t, err := l.writeTable(num, newMemtableIterator(m), false)
+	if err != nil {
+		return fmt.Errorf("flushing memtable: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if t != nil {
+		l.levels[0] = append(l.levels[0], t)
+	}
+	lsn := l.lsn
+	l.lsn = max(l.lsn, m.lsn)
+
+	err = writeManifest(l.opts.DataDir, l.manifestLocked())
+	if err != nil {
+		l.lsn = lsn
+		if t != nil {
+			l.levels[0] = l.levels[0][:len(l.levels[0])-1]
+			t.close()
+			os.Remove(t.path)
+		}
+		return fmt.Errorf("flushing memtable: %w", err)
+	}
+
+	l.imm = l.imm[1:]
+	l.flushed.Broadcast()
+
+	return nil
+}
+
+// writeTable writes all entries of the iterator into a single table, it
+// returns nil when nothing was written.
+func (l *LSM) writeTable(num uint64, it iterator, dropTombstones bool) (*table, error) {
+	w, err := createTable(l.tablePath(num))
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		key, entry, err := it.next()
+		if err != nil {
+			if isEOF(err) {
+				break
+			}
+			w.abort()
+			return nil, err
+		}
+
+		if entry.deleted && dropTombstones {
+			continue
+		}
+
+		err = w.add(key, entry)
+		if err != nil {
+			w.abort()
+			return nil, err
+		}
+	}
+
+	if w.empty() {
+		w.abort()
+		return nil, nil
+	}
+
+	t, err := w.finish(num)
+	if err != nil {
+		w.abort()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (l *LSM) allocNum() uint64 {
+	num := l.nextNum
+	l.nextNum++
+
+	return num
+}
+
+func (l *LSM) tablePath(num uint64) string {
+	return filepath.Join(l.opts.DataDir, tableName(num))
+}
+
+func (l *LSM) manifestLocked() manifest {
+	m := manifest{nextNum: l.nextNum, lsn: l.lsn, levels: make([][]uint64, maxLevels)}
+	for level, tables := range l.levels {
+		for _, t := range tables {
+			m.levels[level] = append(m.levels[level], t.num)
+		}
+	}
+
+	return m
+}
+
+func sortTables(tables []*table) {
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].minKey() < tables[j].minKey()
+	})
+}
@@ -0,0 +1,248 @@
+package lsm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/config"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+func TestNewLSMEmptyLogger(t *testing.T) {
+	_, err := NewLSM(nil, nil)
+	assert.ErrorIs(t, err, errInvalidLogger)
+}
+
+func TestRegisteredEngine(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	cfg := config.Engine{
+		Type: Type,
+		Settings: map[string]any{
+			Type: map[string]any{"data_dir": t.TempDir(), "memtable_size": "1KB"},
+		},
+	}
+
+	e, err := engine.New(cfg, logger)
+	require.NoError(t, err)
+
+	l, ok := e.(*LSM)
+	require.True(t, ok)
+	assert.Equal(t, int64(1024), l.opts.MemtableSize)
+	assert.NoError(t, l.Close())
+}
+
+func TestSetGetDel(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLSM(t, t.TempDir())
+	defer l.Close()
+
+	require.NoError(t, l.Set(ctx, "key", "value"))
+	value, _, err := l.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	removed, err := l.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	value, ok, err := l.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, value)
+
+	removed, err = l.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestFlushAndCompaction(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLSM(t, t.TempDir())
+	defer l.Close()
+
+	const keys = 5000
+	for i := range keys {
+		require.NoError(t, l.Set(ctx, testKey(i), fmt.Sprintf("value-%d", i)))
+	}
+	// overwrite and delete some keys, older versions stay in deeper tables
+	for i := 0; i < keys; i += 3 {
+		require.NoError(t, l.Set(ctx, testKey(i), "updated"))
+	}
+	for i := 1; i < keys; i += 3 {
+		_, err := l.Del(ctx, testKey(i))
+		require.NoError(t, err)
+	}
+
+	waitBackground(t, l)
+
+	l.mu.RLock()
+	assert.Less(t, len(l.levels[0]), l0CompactionTrigger)
+	assert.NotEmpty(t, l.levels[1])
+	l.mu.RUnlock()
+
+	for i := range keys {
+		value, _, err := l.Get(ctx, testKey(i))
+		require.NoError(t, err)
+		assert.Equal(t, expectedValue(i), value, testKey(i))
+	}
+}
+
+func TestReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	l := newTestLSM(t, dir)
+	for i := range 2000 {
+		require.NoError(t, l.Set(ctx, testKey(i), fmt.Sprintf("value-%d", i)))
+	}
+	_, err := l.Del(ctx, testKey(7))
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	// a leftover of an interrupted compaction is not listed in the manifest
+	orphan := filepath.Join(dir, tableName(999999))
+	require.NoError(t, os.WriteFile(orphan, []byte("garbage"), 0o644))
+
+	l = newTestLSM(t, dir)
+	defer l.Close()
+
+	_, err = os.Stat(orphan)
+	assert.True(t, os.IsNotExist(err))
+
+	for i := range 2000 {
+		value, _, err := l.Get(ctx, testKey(i))
+		require.NoError(t, err)
+		if i == 7 {
+			assert.Empty(t, value)
+			continue
+		}
+		assert.Equal(t, fmt.Sprintf("value-%d", i), value)
+	}
+}
+
+func TestDurableLSN(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	l := newTestLSM(t, dir)
+	const writes = 500
+	for i := range writes {
+		require.NoError(t, l.Set(ctx, testKey(i), fmt.Sprintf("value-%d", i)))
+		l.Applied(uint64(i + 1))
+	}
+	waitBackground(t, l)
+
+	// the last writes are still in the memtable
+	durable := l.DurableLSN()
+	assert.Positive(t, durable)
+	assert.Less(t, durable, uint64(writes))
+
+	value, _, err := l.Get(ctx, testKey(int(durable-1)))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("value-%d", durable-1), value)
+	require.NoError(t, l.Close())
+
+	l = newTestLSM(t, dir)
+	defer l.Close()
+	assert.Equal(t, uint64(writes), l.DurableLSN())
+}
+
+func TestClosed(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLSM(t, t.TempDir())
+	require.NoError(t, l.Close())
+
+	assert.ErrorIs(t, l.Set(ctx, "key", "value"), errClosed)
+	_, _, err := l.Get(ctx, "key")
+	assert.ErrorIs(t, err, errClosed)
+}
+
+func TestCorruptedTable(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := createTable(filepath.Join(dir, tableName(1)))
+	require.NoError(t, err)
+	require.NoError(t, w.add("a", memEntry{value: "1"}))
+	require.NoError(t, w.add("b", memEntry{deleted: true}))
+	assert.ErrorIs(t, w.add("a", memEntry{value: "2"}), errUnsortedKeys)
+
+	table, err := w.finish(1)
+	require.NoError(t, err)
+
+	entry, ok, err := table.get("b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, entry.deleted)
+	require.NoError(t, table.close())
+
+	data, err := os.ReadFile(table.path)
+	require.NoError(t, err)
+	data[len(data)-footerSize-1] ^= 0xff
+	require.NoError(t, os.WriteFile(table.path, data, 0o644))
+
+	_, err = openTable(table.path, 1)
+	assert.ErrorIs(t, err, errCorruptedTable)
+}
+
+func TestBloom(t *testing.T) {
+	var hashes []uint64
+	for i := range 1000 {
+		hashes = append(hashes, bloomHash(testKey(i)))
+	}
+
+	filter := newBloom(hashes)
+	for i := range 1000 {
+		assert.True(t, filter.mayContain(testKey(i)))
+	}
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if filter.mayContain(testKey(i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 300)
+}
+
+func newTestLSM(t *testing.T, dir string) *LSM {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	l, err := NewLSM(logger, &Opts{
+		DataDir:      dir,
+		MemtableSize: 4 << 10,
+		TableSize:    8 << 10,
+	})
+	require.NoError(t, err)
+
+	return l
+}
+
+func waitBackground(t *testing.T, l *LSM) {
+	require.Eventually(t, func() bool {
+		return !l.pendingFlush() && !l.needsCompaction()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func testKey(i int) string {
+	return fmt.Sprintf("key-%05d", i)
+}
+
+func expectedValue(i int) string {
+	switch i % 3 {
+	case 0:
+		return "updated"
+	case 1:
+		return ""
+	default:
+		return fmt.Sprintf("value-%d", i)
+	}
+}
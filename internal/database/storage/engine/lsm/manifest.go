@@ -0,0 +1,139 @@
+package lsm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// manifest lists live tables per level, it is rewritten atomically after
+// every flush and compaction. Table files which are not listed are leftovers
+// of an interrupted flush or compaction.
+//
+// layout, one record per line:
+// next <next table number>
+// lsn <lsn of the last WAL record in tables>
+// table <level> <table number>
+const (
+	manifestName = "MANIFEST"
+	tableExt     = ".sst"
+	tmpExt       = ".tmp"
+)
+
+type manifest struct {
+	nextNum uint64
+	lsn     uint64
+	// table numbers per level, level 0 is ordered from the oldest table
+	levels [][]uint64
+}
+
+func tableName(num uint64) string {
+	return fmt.Sprintf("%06d%s", num, tableExt)
+}
+
+func readManifest(dir string) (manifest, error) {
+	m := manifest{nextNum: 1, levels: make([][]uint64, maxLevels)}
+
+	f, err := os.Open(filepath.Join(dir, manifestName))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return manifest{}, fmt.Errorf("opening manifest: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		switch {
+		case len(fields) == 2 && fields[0] == "next":
+			m.nextNum, err = strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return manifest{}, fmt.Errorf("%w: %s", errInvalidManifest, scanner.Text())
+			}
+		case len(fields) == 2 && fields[0] == "lsn":
+			m.lsn, err = strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return manifest{}, fmt.Errorf("%w: %s", errInvalidManifest, scanner.Text())
+			}
+		case len(fields) == 3 && fields[0] == "table":
+			level, err := strconv.Atoi(fields[1])
+			if err != nil || level < 0 || level >= maxLevels {
+				return manifest{}, fmt.Errorf("%w: %s", errInvalidManifest, scanner.Text())
+			}
+
+			num, err := strconv.ParseUint(fields[2], 10, 64)
+			if err != nil {
+				return manifest{}, fmt.Errorf("%w: %s", errInvalidManifest, scanner.Text())
+			}
+
+			m.levels[level] = append(m.levels[level], num)
+		default:
+			return manifest{}, fmt.Errorf("%w: %s", errInvalidManifest, scanner.Text())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return manifest{}, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+func writeManifest(dir string, m manifest) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "next %d\n", m.nextNum)
+	fmt.Fprintf(&b, "lsn %d\n", m.lsn)
+	for level, nums := range m.levels {
+		for _, num := range nums {
+			fmt.Fprintf(&b, "table %d %d\n", level, num)
+		}
+	}
+
+	path := filepath.Join(dir, manifestName)
+	tmpPath := path + tmpExt
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating manifest: %w", err)
+	}
+
+	_, err = f.WriteString(b.String())
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("renaming manifest: %w", err)
+	}
+
+	// makes both the manifest and the new table files durable
+	return syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("opening lsm directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("syncing lsm directory: %w", err)
+	}
+
+	return nil
+}
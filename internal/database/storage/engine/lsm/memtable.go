@@ -0,0 +1,51 @@
+package lsm
+
+import "sort"
+
+// memtable keeps the latest writes in memory until it is flushed into a
+// level 0 table, deletions are kept as tombstones.
+type memtable struct {
+	entries map[string]memEntry
+	size    int64
+	// lsn of the last WAL record applied to the memtable
+	lsn uint64
+}
+
+type memEntry struct {
+	value   string
+	deleted bool
+}
+
+// approximate per-entry cost of the map bucket and string headers
+const memEntryOverhead = 48
+
+func newMemtable() *memtable {
+	return &memtable{
+		entries: make(map[string]memEntry),
+	}
+}
+
+func (m *memtable) put(key, value string, deleted bool) {
+	if old, ok := m.entries[key]; ok {
+		m.size -= int64(len(key) + len(old.value) + memEntryOverhead)
+	}
+
+	m.entries[key] = memEntry{value: value, deleted: deleted}
+	m.size += int64(len(key) + len(value) + memEntryOverhead)
+}
+
+func (m *memtable) get(key string) (memEntry, bool) {
+	entry, ok := m.entries[key]
+
+	return entry, ok
+}
+
+func (m *memtable) sortedKeys() []string {
+	keys := make([]string, 0, len(m.entries))
+	for key := range m.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,387 @@
+package lsm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+)
+
+// table file layout:
+// | data blocks | sparse index | bloom filter | meta | footer |
+// entry layout: | key length (uvarint) | key | kind (1) | value length (uvarint) | value |
+// index entry layout: | key length (uvarint) | key | block offset (uvarint) |
+// meta layout: | max key length (uvarint) | max key |
+// footer layout: | index offset (8) | bloom offset (8) | meta offset (8) | entries count (8) | crc32 (4) | magic (4) |
+// the checksum covers the index, the bloom filter and the meta.
+var tableMagic = []byte("KDBT")
+
+const (
+	footerSize = 40
+
+	kindValue     = 0
+	kindTombstone = 1
+
+	// every block starts with an index entry, so the index keeps one key
+	// per that many bytes of data
+	blockSize = 4 << 10
+
+	// protects from huge allocations on a damaged length
+	maxStringSize = 512 << 20
+)
+
+type indexEntry struct {
+	key    string
+	offset int64
+}
+
+// table is an immutable sorted file. The sparse index, the bloom filter and
+// the key range are kept in memory, the data is read from disk.
+type table struct {
+	num    uint64
+	path   string
+	file   *os.File
+	size   int64
+	count  uint64
+	index  []indexEntry
+	bloom  *bloom
+	maxKey string
+	// data blocks end where the index starts
+	dataEnd int64
+}
+
+func (t *table) minKey() string {
+	return t.index[0].key
+}
+
+func (t *table) overlaps(minKey, maxKey string) bool {
+	return t.minKey() <= maxKey && minKey <= t.maxKey
+}
+
+// get looks the key up, the second result reports whether the table has any
+// record for the key, a tombstone included.
+func (t *table) get(key string) (memEntry, bool, error) {
+	if key < t.minKey() || key > t.maxKey || !t.bloom.mayContain(key) {
+		return memEntry{}, false, nil
+	}
+
+	i := sort.Search(len(t.index), func(i int) bool {
+		return t.index[i].key > key
+	}) - 1
+	if i < 0 {
+		return memEntry{}, false, nil
+	}
+
+	start, end := t.index[i].offset, t.dataEnd
+	if i+1 < len(t.index) {
+		end = t.index[i+1].offset
+	}
+
+	block := make([]byte, end-start)
+	_, err := t.file.ReadAt(block, start)
+	if err != nil {
+		return memEntry{}, false, fmt.Errorf("reading block of %s: %w", t.path, err)
+	}
+
+	reader := bytes.NewReader(block)
+	for reader.Len() > 0 {
+		entryKey, entry, err := readEntry(reader)
+		if err != nil {
+			return memEntry{}, false, err
+		}
+
+		if entryKey == key {
+			return entry, true, nil
+		}
+		if entryKey > key {
+			break
+		}
+	}
+
+	return memEntry{}, false, nil
+}
+
+func (t *table) iterator() *tableIterator {
+	return &tableIterator{
+		reader: bufio.NewReader(io.NewSectionReader(t.file, 0, t.dataEnd)),
+	}
+}
+
+func (t *table) close() error {
+	return t.file.Close()
+}
+
+func openTable(path string, num uint64) (*table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening sstable: %w", err)
+	}
+
+	t, err := loadTable(f, path, num)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func loadTable(f *os.File, path string, num uint64) (*table, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat sstable: %w", err)
+	}
+
+	size := info.Size()
+	if size < footerSize {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	footer := make([]byte, footerSize)
+	_, err = f.ReadAt(footer, size-footerSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading sstable footer: %w", err)
+	}
+
+	if !bytes.Equal(footer[36:], tableMagic) {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	indexOffset := int64(binary.BigEndian.Uint64(footer))
+	bloomOffset := int64(binary.BigEndian.Uint64(footer[8:]))
+	metaOffset := int64(binary.BigEndian.Uint64(footer[16:]))
+	count := binary.BigEndian.Uint64(footer[24:])
+	checksum := binary.BigEndian.Uint32(footer[32:])
+
+	if indexOffset > bloomOffset || bloomOffset > metaOffset || metaOffset > size-footerSize {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	tail := make([]byte, size-footerSize-indexOffset)
+	_, err = f.ReadAt(tail, indexOffset)
+	if err != nil {
+		return nil, fmt.Errorf("reading sstable index: %w", err)
+	}
+
+	if crc32.ChecksumIEEE(tail) != checksum {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	index, err := decodeIndex(tail[:bloomOffset-indexOffset])
+	if err != nil || len(index) == 0 {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	filter, err := decodeBloom(tail[bloomOffset-indexOffset : metaOffset-indexOffset])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	maxKey, err := readString(bytes.NewReader(tail[metaOffset-indexOffset:]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errCorruptedTable, path)
+	}
+
+	return &table{
+		num:     num,
+		path:    path,
+		file:    f,
+		size:    size,
+		count:   count,
+		index:   index,
+		bloom:   filter,
+		maxKey:  maxKey,
+		dataEnd: indexOffset,
+	}, nil
+}
+
+func decodeIndex(data []byte) ([]indexEntry, error) {
+	reader := bytes.NewReader(data)
+
+	var index []indexEntry
+	for reader.Len() > 0 {
+		key, err := readString(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		offset, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, errCorruptedTable
+		}
+
+		index = append(index, indexEntry{key: key, offset: int64(offset)})
+	}
+
+	return index, nil
+}
+
+// tableWriter writes sorted entries into a new table file.
+type tableWriter struct {
+	path       string
+	file       *os.File
+	writer     *bufio.Writer
+	offset     int64
+	blockStart int64
+	index      []indexEntry
+	hashes     []uint64
+	lastKey    string
+	count      uint64
+	buf        []byte
+}
+
+func createTable(path string) (*tableWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("creating sstable: %w", err)
+	}
+
+	return &tableWriter{
+		path:   path,
+		file:   f,
+		writer: bufio.NewWriter(f),
+	}, nil
+}
+
+func (w *tableWriter) add(key string, entry memEntry) error {
+	if w.count > 0 && key <= w.lastKey {
+		return errUnsortedKeys
+	}
+
+	if w.count == 0 || w.offset-w.blockStart >= blockSize {
+		w.index = append(w.index, indexEntry{key: key, offset: w.offset})
+		w.blockStart = w.offset
+	}
+
+	w.buf = encodeEntry(w.buf[:0], key, entry)
+	n, err := w.writer.Write(w.buf)
+	if err != nil {
+		return fmt.Errorf("writing sstable entry: %w", err)
+	}
+
+	w.offset += int64(n)
+	w.hashes = append(w.hashes, bloomHash(key))
+	w.lastKey = key
+	w.count++
+
+	return nil
+}
+
+func (w *tableWriter) empty() bool {
+	return w.count == 0
+}
+
+// size returns the number of data bytes written so far.
+func (w *tableWriter) size() int64 {
+	return w.offset
+}
+
+// finish writes the index, the filter and the footer, syncs the file and
+// opens it for reading.
+func (w *tableWriter) finish(num uint64) (*table, error) {
+	var tail []byte
+	for _, entry := range w.index {
+		tail = binary.AppendUvarint(tail, uint64(len(entry.key)))
+		tail = append(tail, entry.key...)
+		tail = binary.AppendUvarint(tail, uint64(entry.offset))
+	}
+
+	bloomOffset := w.offset + int64(len(tail))
+	tail = newBloom(w.hashes).encode(tail)
+
+	metaOffset := w.offset + int64(len(tail))
+	tail = binary.AppendUvarint(tail, uint64(len(w.lastKey)))
+	tail = append(tail, w.lastKey...)
+
+	footer := make([]byte, 0, footerSize)
+	footer = binary.BigEndian.AppendUint64(footer, uint64(w.offset))
+	footer = binary.BigEndian.AppendUint64(footer, uint64(bloomOffset))
+	footer = binary.BigEndian.AppendUint64(footer, uint64(metaOffset))
+	footer = binary.BigEndian.AppendUint64(footer, w.count)
+	footer = binary.BigEndian.AppendUint32(footer, crc32.ChecksumIEEE(tail))
+	footer = append(footer, tableMagic...)
+
+	if _, err := w.writer.Write(tail); err != nil {
+		return nil, fmt.Errorf("writing sstable index: %w", err)
+	}
+	if _, err := w.writer.Write(footer); err != nil {
+		return nil, fmt.Errorf("writing sstable footer: %w", err)
+	}
+	if err := w.writer.Flush(); err != nil {
+		return nil, fmt.Errorf("flushing sstable: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return nil, fmt.Errorf("syncing sstable: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return nil, fmt.Errorf("closing sstable: %w", err)
+	}
+
+	return openTable(w.path, num)
+}
+
+func (w *tableWriter) abort() {
+	w.file.Close()
+	os.Remove(w.path)
+}
+
+func encodeEntry(buf []byte, key string, entry memEntry) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(key)))
+	buf = append(buf, key...)
+
+	if entry.deleted {
+		buf = append(buf, kindTombstone)
+	} else {
+		buf = append(buf, kindValue)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(entry.value)))
+
+	return append(buf, entry.value...)
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readEntry(reader byteReader) (string, memEntry, error) {
+	key, err := readString(reader)
+	if err != nil {
+		return "", memEntry{}, err
+	}
+
+	kind, err := reader.ReadByte()
+	if err != nil || kind > kindTombstone {
+		return "", memEntry{}, errCorruptedEntry
+	}
+
+	value, err := readString(reader)
+	if err != nil {
+		return "", memEntry{}, err
+	}
+
+	return key, memEntry{value: value, deleted: kind == kindTombstone}, nil
+}
+
+func readString(reader byteReader) (string, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil || length > maxStringSize {
+		return "", errCorruptedEntry
+	}
+	if sized, ok := reader.(interface{ Len() int }); ok && length > uint64(sized.Len()) {
+		return "", errCorruptedEntry
+	}
+
+	buf := make([]byte, length)
+	_, err = io.ReadFull(reader, buf)
+	if err != nil {
+		return "", errCorruptedEntry
+	}
+
+	return string(buf), nil
+}
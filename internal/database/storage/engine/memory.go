@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+type EvictionPolicy string
+
+const (
+	NoEviction    EvictionPolicy = "noeviction"
+	AllKeysLRU    EvictionPolicy = "allkeys-lru"
+	AllKeysLFU    EvictionPolicy = "allkeys-lfu"
+	VolatileLRU   EvictionPolicy = "volatile-lru"
+	AllKeysRandom EvictionPolicy = "allkeys-random"
+)
+
+func ParseEvictionPolicy(policy string) (EvictionPolicy, error) {
+	switch EvictionPolicy(policy) {
+	case NoEviction, AllKeysLRU, AllKeysLFU, VolatileLRU, AllKeysRandom:
+		return EvictionPolicy(policy), nil
+	case "":
+		return NoEviction, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errUnknownEvictionPolicy, policy)
+	}
+}
+
+// approximate per-key costs of the go runtime structures: map buckets,
+// string headers and the item itself
+const (
+	itemOverhead   = 64
+	expireOverhead = 24
+
+	evictionSampleSize = 5
+)
+
+// memory accounts bytes used by all shards of one engine.
+type memory struct {
+	used   atomic.Int64
+	limit  int64
+	policy EvictionPolicy
+	// deferred writes ignore the limit, the caller evicts keys beforehand
+	deferred atomic.Bool
+}
+
+func newMemory(limit int64, policy EvictionPolicy) *memory {
+	if policy == "" {
+		policy = NoEviction
+	}
+
+	return &memory{
+		limit:  limit,
+		policy: policy,
+	}
+}
+
+func (m *memory) add(delta int64) {
+	m.used.Add(delta)
+}
+
+// exceeds reports whether growing by delta bytes goes over the limit.
+func (m *memory) exceeds(delta int64) bool {
+	return m.limit > 0 && delta > 0 && m.used.Load()+delta > m.limit
+}
+
+func itemSize(key, value string) int64 {
+	return int64(len(key) + len(value) + itemOverhead)
+}
+
+func (it *item) size(key string) int64 {
+	if it.obj == nil {
+		return itemSize(key, it.value)
+	}
+
+	return int64(len(key)+itemOverhead) + it.obj.size()
+}
+
+type item struct {
+	value string
+	// collection values, nil for strings
+	obj object
+	// last access time in unix nanoseconds
+	atime atomic.Int64
+	// logarithmic access frequency counter used by the LFU policy
+	freq atomic.Uint32
+}
+
+// LFU counter works like in redis: it grows logarithmically so 255 means
+// about a million hits, and decreases by one every decay period of idling.
+const (
+	lfuInitValue  = 5
+	lfuMaxValue   = 255
+	lfuLogFactor  = 10
+	lfuDecayEvery = time.Minute
+)
+
+func newItem(value string, now int64) *item {
+	it := &item{value: value}
+	it.atime.Store(now)
+	it.freq.Store(lfuInitValue)
+
+	return it
+}
+
+func (it *item) touch(now int64) {
+	freq := it.decayedFreq(now)
+	if freq < lfuMaxValue {
+		base := float64(0)
+		if freq > lfuInitValue {
+			base = float64(freq - lfuInitValue)
+		}
+
+		if rand.Float64() < 1/(base*lfuLogFactor+1) {
+			freq++
+		}
+	}
+
+	it.freq.Store(freq)
+	it.atime.Store(now)
+}
+
+func (it *item) decayedFreq(now int64) uint32 {
+	freq := it.freq.Load()
+
+	periods := (now - it.atime.Load()) / int64(lfuDecayEvery)
+	if periods <= 0 {
+		return freq
+	}
+	if periods >= int64(freq) {
+		return 0
+	}
+
+	return freq - uint32(periods)
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// every test key takes exactly testItemSize bytes
+const testItemSize = 1 + 1 + itemOverhead
+
+func TestParseEvictionPolicy(t *testing.T) {
+	for _, policy := range []EvictionPolicy{NoEviction, AllKeysLRU, AllKeysLFU, VolatileLRU, AllKeysRandom} {
+		parsed, err := ParseEvictionPolicy(string(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+
+	parsed, err := ParseEvictionPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, NoEviction, parsed)
+
+	_, err = ParseEvictionPolicy("allkeys-fifo")
+	assert.ErrorIs(t, err, errUnknownEvictionPolicy)
+}
+
+func TestMemoryAccounting(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	require.NoError(t, engine.Set(ctx, "k", "value"))
+	assert.Equal(t, itemSize("k", "value"), engine.UsedMemory())
+
+	require.NoError(t, engine.Set(ctx, "k", "v"))
+	assert.Equal(t, itemSize("k", "v"), engine.UsedMemory())
+
+	_, err := engine.ExpireAt(ctx, "k", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, itemSize("k", "v")+expireOverhead, engine.UsedMemory())
+
+	_, err = engine.Persist(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, itemSize("k", "v"), engine.UsedMemory())
+
+	_, err = engine.Del(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, engine.UsedMemory())
+
+	require.NoError(t, engine.SetWithDeadline(ctx, "k", "v", time.Now().Add(-time.Second)))
+	engine.DeleteExpired(ctx)
+	assert.Zero(t, engine.UsedMemory())
+}
+
+func TestNoEviction(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(2*testItemSize, NoEviction))
+
+	require.NoError(t, engine.Set(ctx, "a", "1"))
+	require.NoError(t, engine.Set(ctx, "b", "1"))
+
+	assert.ErrorIs(t, engine.Set(ctx, "c", "1"), ports.ErrOutOfMemory)
+	// overwriting with a value of the same size does not need more memory
+	assert.NoError(t, engine.Set(ctx, "a", "2"))
+	_, err := engine.Del(ctx, "b")
+	assert.NoError(t, err)
+	assert.NoError(t, engine.Set(ctx, "c", "1"))
+}
+
+func TestAllKeysLRU(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(3*testItemSize, AllKeysLRU))
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, engine.Set(ctx, key, "1"))
+		time.Sleep(time.Millisecond)
+	}
+
+	_, _, err := engine.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Set(ctx, "d", "1"))
+
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, keys(t, engine))
+	assert.LessOrEqual(t, engine.UsedMemory(), int64(3*testItemSize))
+}
+
+func TestAllKeysLFU(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(3*testItemSize, AllKeysLFU))
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, engine.Set(ctx, key, "1"))
+	}
+
+	for range 10 {
+		_, _, err := engine.Get(ctx, "a")
+		require.NoError(t, err)
+		_, _, err = engine.Get(ctx, "c")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, engine.Set(ctx, "d", "1"))
+
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, keys(t, engine))
+}
+
+func TestVolatileLRU(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(3*testItemSize+expireOverhead, VolatileLRU))
+
+	require.NoError(t, engine.Set(ctx, "a", "1"))
+	require.NoError(t, engine.SetWithDeadline(ctx, "b", "1", time.Now().Add(time.Hour)))
+	require.NoError(t, engine.Set(ctx, "c", "1"))
+
+	require.NoError(t, engine.Set(ctx, "d", "1"))
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, keys(t, engine))
+
+	// only persistent keys are left, nothing can be evicted
+	assert.ErrorIs(t, engine.Set(ctx, "e", "1"), ports.ErrOutOfMemory)
+}
+
+func TestAllKeysRandom(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(3*testItemSize, AllKeysRandom))
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, engine.Set(ctx, key, "1"))
+	}
+
+	assert.Len(t, keys(t, engine), 3)
+	value, _, err := engine.Get(ctx, "e")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+}
+
+func TestShardedEngineEviction(t *testing.T) {
+	ctx := context.Background()
+	const limit = 100 * testItemSize
+	engine := NewShardedEngine(8, WithMaxMemory(limit, AllKeysLRU))
+
+	for i := range 1000 {
+		require.NoError(t, engine.Set(ctx, fmt.Sprintf("%03d", i), "1"))
+		assert.LessOrEqual(t, engine.UsedMemory(), int64(limit))
+	}
+
+	entries, err := engine.Dump(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, entries)
+	assert.LessOrEqual(t, len(entries), 100)
+}
+
+func TestDeferredEviction(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(WithMaxMemory(2*testItemSize, AllKeysLRU))
+	engine.DeferEviction()
+
+	require.NoError(t, engine.Set(ctx, "a", "1"))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, engine.Set(ctx, "b", "1"))
+
+	victims, err := engine.Victims(ctx, "c", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, victims)
+
+	// the overwrite fits without evictions
+	victims, err = engine.Victims(ctx, "b", 1)
+	require.NoError(t, err)
+	assert.Empty(t, victims)
+
+	// writes do not evict keys themselves
+	require.NoError(t, engine.Set(ctx, "c", "1"))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, keys(t, engine))
+
+	noEviction := NewEngine(WithMaxMemory(testItemSize, NoEviction))
+	noEviction.DeferEviction()
+	require.NoError(t, noEviction.Set(ctx, "a", "1"))
+
+	_, err = noEviction.Victims(ctx, "b", 1)
+	assert.ErrorIs(t, err, ports.ErrOutOfMemory)
+}
+
+func TestShardedEngineVictims(t *testing.T) {
+	ctx := context.Background()
+	const limit = 10 * testItemSize
+	engine := NewShardedEngine(8, WithMaxMemory(limit, AllKeysLRU))
+	engine.DeferEviction()
+
+	for i := range 10 {
+		require.NoError(t, engine.Set(ctx, fmt.Sprint(i), "1"))
+	}
+
+	victims, err := engine.Victims(ctx, "k", 1+2*testItemSize)
+	require.NoError(t, err)
+	assert.Len(t, victims, 3)
+	assert.NotContains(t, victims, "k")
+}
+
+func keys(t *testing.T, engine *Engine) []string {
+	entries, err := engine.Dump(context.Background())
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+
+	return keys
+}
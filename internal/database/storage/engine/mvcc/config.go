@@ -0,0 +1,18 @@
+package mvcc
+
+import (
+	"log/slog"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+const Type = "mvcc"
+
+// Config has no settings yet.
+type Config struct{}
+
+func init() {
+	engine.Register(Type, func(cfg Config, logger *slog.Logger) (engine.Interface, error) {
+		return NewMVCC(), nil
+	})
+}
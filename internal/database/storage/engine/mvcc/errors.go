@@ -0,0 +1,8 @@
+package mvcc
+
+import "errors"
+
+var (
+	errTxClosed        = errors.New("read transaction is closed")
+	errUnsupportedKind = errors.New("engine does not support values of kind")
+)
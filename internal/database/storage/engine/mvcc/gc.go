@@ -0,0 +1,82 @@
+package mvcc
+
+// gcBatchSize is the number of keys collected under a single lock, so
+// writers are not blocked for the whole collection.
+const gcBatchSize = 256
+
+func (m *MVCC) runGC() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-m.work:
+			m.collect()
+		}
+	}
+}
+
+// collect goes over keys with old versions once, keys still needed by open
+// read transactions stay for the next run.
+func (m *MVCC) collect() {
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.garbage))
+	for key := range m.garbage {
+		keys = append(keys, key)
+	}
+	m.mu.RUnlock()
+
+	for start := 0; start < len(keys); start += gcBatchSize {
+		select {
+		case <-m.done:
+			return
+		default:
+		}
+
+		m.mu.Lock()
+		for _, key := range keys[start:min(start+gcBatchSize, len(keys))] {
+			if head, ok := m.list.Get(key); ok {
+				m.collectLocked(key, head)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// collectLocked drops versions of the key which are older than the one seen
+// by the oldest read transaction, a key deleted for everyone is removed.
+func (m *MVCC) collectLocked(key string, head *version) {
+	horizon := m.horizonLocked()
+
+	v := head
+	for v != nil && v.ts > horizon {
+		v = v.prev
+	}
+
+	switch {
+	case v == head && v.deleted:
+		m.list.Delete(key)
+		delete(m.garbage, key)
+		return
+	case v != nil:
+		v.prev = nil
+	}
+
+	if head.prev == nil && !head.deleted {
+		delete(m.garbage, key)
+	} else {
+		m.garbage[key] = struct{}{}
+	}
+}
+
+// horizonLocked returns the timestamp of the oldest open read transaction,
+// the last commit when there are none.
+func (m *MVCC) horizonLocked() uint64 {
+	horizon := m.clock
+	for ts := range m.readers {
+		horizon = min(horizon, ts)
+	}
+
+	return horizon
+}
@@ -0,0 +1,219 @@
+package mvcc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/skiplist"
+)
+
+// MVCC is an ordered in-memory engine keeping a chain of versions per key.
+// Every write is stamped with a commit timestamp, read transactions see the
+// latest versions committed before they began. Versions which are not
+// visible to any open read transaction are reclaimed.
+type MVCC struct {
+	mu   *sync.RWMutex
+	list *skiplist.SkipList[string, *version]
+	// commit timestamp of the last write
+	clock uint64
+	// number of open read transactions per timestamp
+	readers map[uint64]int
+	// keys having versions which may be reclaimed later
+	garbage map[string]struct{}
+
+	work chan struct{}
+	done chan struct{}
+	wg   *sync.WaitGroup
+	once *sync.Once
+}
+
+// version is a single committed state of a key, a tombstone for deletions.
+type version struct {
+	value   string
+	deleted bool
+	ts      uint64
+	// the previous version, older versions have smaller timestamps
+	prev *version
+}
+
+func NewMVCC() *MVCC {
+	m := &MVCC{
+		mu:      &sync.RWMutex{},
+		list:    skiplist.New[string, *version](strings.Compare),
+		readers: make(map[uint64]int),
+		garbage: make(map[string]struct{}),
+		work:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		wg:      &sync.WaitGroup{},
+		once:    &sync.Once{},
+	}
+
+	m.wg.Add(1)
+	go m.runGC()
+
+	return m
+}
+
+func (m *MVCC) Get(ctx context.Context, key string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	head, _ := m.list.Get(key)
+	if head == nil || head.deleted {
+		return "", false, nil
+	}
+
+	return head.value, true, nil
+}
+
+func (m *MVCC) Set(ctx context.Context, key, value string) error {
+	m.write(key, value, false)
+
+	return nil
+}
+
+func (m *MVCC) Del(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	head, _ := m.list.Get(key)
+	if head == nil || head.deleted {
+		return false, nil
+	}
+	m.writeLocked(key, "", true)
+
+	return true, nil
+}
+
+// Update replaces the value with the result of fn under the engine lock.
+func (m *MVCC) Update(ctx context.Context, key string, fn engine.UpdateFunc) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var value string
+	head, _ := m.list.Get(key)
+	exists := head != nil && !head.deleted
+	if exists {
+		value = head.value
+	}
+
+	value, err := fn(value, exists)
+	if err != nil {
+		return "", err
+	}
+
+	m.writeLocked(key, value, false)
+
+	return value, nil
+}
+
+func (m *MVCC) write(key, value string, deleted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writeLocked(key, value, deleted)
+}
+
+func (m *MVCC) writeLocked(key, value string, deleted bool) {
+	head, _ := m.list.Get(key)
+	if head == nil && deleted {
+		return
+	}
+
+	m.clock++
+	head = &version{value: value, deleted: deleted, ts: m.clock, prev: head}
+	m.list.Set(key, head)
+
+	m.collectLocked(key, head)
+}
+
+// BeginRead opens a read transaction over the latest committed state.
+func (m *MVCC) BeginRead(ctx context.Context) (engine.ReadTx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.readers[m.clock]++
+
+	return &readTx{mvcc: m, ts: m.clock}, nil
+}
+
+// Range iterates over a consistent snapshot taken when it is called, the
+// snapshot is kept until the iterator is closed.
+func (m *MVCC) Range(ctx context.Context, start, end string) (engine.Iterator, error) {
+	tx, err := m.BeginRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rangeIterator{tx: tx.(*readTx), from: start, end: end, ownsTx: true}, nil
+}
+
+// Dump returns a consistent copy of the whole keyspace in key order, writers
+// are not blocked while it is copied.
+func (m *MVCC) Dump(ctx context.Context) ([]engine.Entry, error) {
+	it, err := m.Range(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var entries []engine.Entry
+	for it.Next() {
+		entries = append(entries, engine.Entry{Key: it.Key(), Value: it.Value()})
+	}
+
+	return entries, it.Err()
+}
+
+func (m *MVCC) Restore(ctx context.Context, entry engine.Entry) error {
+	if entry.Kind != engine.KindString {
+		return fmt.Errorf("%w: %s", errUnsupportedKind, entry.Kind)
+	}
+
+	return m.Set(ctx, entry.Key, entry.Value)
+}
+
+// Close stops the garbage collector.
+func (m *MVCC) Close() error {
+	m.once.Do(func() {
+		close(m.done)
+		m.wg.Wait()
+	})
+
+	return nil
+}
+
+func (m *MVCC) release(ts uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.readers[ts]--
+	if m.readers[ts] == 0 {
+		delete(m.readers, ts)
+	}
+
+	if len(m.garbage) > 0 {
+		select {
+		case m.work <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// visible returns the version seen at the timestamp, nil when the key did
+// not exist.
+func visible(head *version, ts uint64) *version {
+	for v := head; v != nil; v = v.prev {
+		if v.ts <= ts {
+			if v.deleted {
+				return nil
+			}
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/config"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+func TestRegisteredEngine(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	e, err := engine.New(config.Engine{Type: Type}, logger)
+	require.NoError(t, err)
+
+	m, ok := e.(*MVCC)
+	require.True(t, ok)
+	assert.NoError(t, m.Close())
+}
+
+func TestSetGetDel(t *testing.T) {
+	ctx := context.Background()
+	m := NewMVCC()
+	defer m.Close()
+
+	require.NoError(t, m.Set(ctx, "key", "value"))
+	value, _, err := m.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	removed, err := m.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	value, ok, err := m.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, value)
+
+	removed, err = m.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	// nothing is left once no transaction can see old versions
+	m.mu.RLock()
+	assert.Zero(t, m.list.Len())
+	assert.Empty(t, m.garbage)
+	m.mu.RUnlock()
+}
+
+func TestReadTxSnapshot(t *testing.T) {
+	ctx := context.Background()
+	m := NewMVCC()
+	defer m.Close()
+
+	require.NoError(t, m.Set(ctx, "a", "1"))
+	require.NoError(t, m.Set(ctx, "b", "1"))
+
+	tx, err := m.BeginRead(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Set(ctx, "a", "2"))
+	_, err = m.Del(ctx, "b")
+	require.NoError(t, err)
+	require.NoError(t, m.Set(ctx, "c", "2"))
+
+	value, _, err := tx.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	value, _, err = tx.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	value, ok, err := tx.Get(ctx, "c")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, value)
+
+	it, err := tx.Range(ctx, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []engine.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "1"}}, collect(t, it))
+
+	value, _, err = m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+
+	require.NoError(t, tx.Close())
+	_, _, err = tx.Get(ctx, "a")
+	assert.ErrorIs(t, err, errTxClosed)
+
+	// closing the last transaction lets the collector drop old versions
+	require.Eventually(t, func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		return len(m.garbage) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	m.mu.RLock()
+	assert.Equal(t, 2, m.list.Len())
+	head, _ := m.list.Get("a")
+	assert.Nil(t, head.prev)
+	m.mu.RUnlock()
+}
+
+func TestRangeIsConsistent(t *testing.T) {
+	ctx := context.Background()
+	m := NewMVCC()
+	defer m.Close()
+
+	// more keys than fit into a single batch
+	const keys = 3*rangeBatchSize + 10
+	for i := range keys {
+		require.NoError(t, m.Set(ctx, fmt.Sprintf("%04d", i), "old"))
+	}
+
+	it, err := m.Range(ctx, "0010", "")
+	require.NoError(t, err)
+
+	count := 0
+	for it.Next() {
+		assert.Equal(t, fmt.Sprintf("%04d", count+10), it.Key())
+		assert.Equal(t, "old", it.Value())
+
+		// writes made during the scan are not visible
+		if count == 0 {
+			for i := range keys {
+				require.NoError(t, m.Set(ctx, fmt.Sprintf("%04d", i), "new"))
+			}
+			_, err = m.Del(ctx, fmt.Sprintf("%04d", 2*rangeBatchSize))
+			require.NoError(t, err)
+		}
+		count++
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, keys-10, count)
+	require.NoError(t, it.Close())
+
+	m.mu.RLock()
+	assert.Empty(t, m.readers)
+	m.mu.RUnlock()
+}
+
+func TestDump(t *testing.T) {
+	ctx := context.Background()
+	m := NewMVCC()
+	defer m.Close()
+
+	require.NoError(t, m.Set(ctx, "b", "2"))
+	require.NoError(t, m.Set(ctx, "a", "1"))
+
+	entries, err := m.Dump(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []engine.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, entries)
+
+	restored := NewMVCC()
+	defer restored.Close()
+	for _, entry := range entries {
+		require.NoError(t, restored.Restore(ctx, entry))
+	}
+
+	value, _, err := restored.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+}
+
+func collect(t *testing.T, it engine.Iterator) []engine.Entry {
+	defer it.Close()
+
+	var entries []engine.Entry
+	for it.Next() {
+		entries = append(entries, engine.Entry{Key: it.Key(), Value: it.Value()})
+	}
+	require.NoError(t, it.Err())
+
+	return entries
+}
@@ -0,0 +1,150 @@
+package mvcc
+
+import (
+	"context"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+// readTx sees versions committed up to its timestamp, they are kept by the
+// garbage collector until the transaction is closed.
+type readTx struct {
+	mvcc   *MVCC
+	ts     uint64
+	closed bool
+}
+
+func (tx *readTx) Get(ctx context.Context, key string) (string, bool, error) {
+	if tx.closed {
+		return "", false, errTxClosed
+	}
+
+	tx.mvcc.mu.RLock()
+	defer tx.mvcc.mu.RUnlock()
+
+	head, _ := tx.mvcc.list.Get(key)
+	if v := visible(head, tx.ts); v != nil {
+		return v.value, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (tx *readTx) Range(ctx context.Context, start, end string) (engine.Iterator, error) {
+	if tx.closed {
+		return nil, errTxClosed
+	}
+
+	return &rangeIterator{tx: tx, from: start, end: end}, nil
+}
+
+func (tx *readTx) Close() error {
+	if tx.closed {
+		return nil
+	}
+	tx.closed = true
+
+	tx.mvcc.release(tx.ts)
+
+	return nil
+}
+
+// rangeBatchSize is the number of entries copied under a single read lock,
+// writers are not blocked for the whole scan.
+const rangeBatchSize = 128
+
+// rangeIterator reads the snapshot of the transaction in batches, unlike
+// the ordered engine it is not affected by writes between batches.
+type rangeIterator struct {
+	tx *readTx
+	// the iterator closes the transaction it was opened with
+	ownsTx bool
+	// the next batch starts from this key, excluding it after the first batch
+	from    string
+	started bool
+	end     string
+	batch   []engine.Entry
+	pos     int
+	done    bool
+	err     error
+}
+
+func (it *rangeIterator) Next() bool {
+	if it.pos+1 < len(it.batch) {
+		it.pos++
+		return true
+	}
+
+	if it.done {
+		it.batch = nil
+		return false
+	}
+
+	if it.tx.closed {
+		it.err = errTxClosed
+		it.done = true
+		it.batch = nil
+		return false
+	}
+
+	it.fetch()
+	it.pos = 0
+
+	return len(it.batch) > 0
+}
+
+func (it *rangeIterator) fetch() {
+	m := it.tx.mvcc
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	it.batch = it.batch[:0]
+
+	cursor := m.list.Seek(it.from)
+	if it.started && cursor.Valid() && cursor.Key() == it.from {
+		cursor.Next()
+	}
+
+	for ; cursor.Valid() && len(it.batch) < rangeBatchSize; cursor.Next() {
+		if it.end != "" && cursor.Key() >= it.end {
+			it.done = true
+			break
+		}
+
+		// the last seen key moves on over invisible keys too
+		it.from = cursor.Key()
+		it.started = true
+
+		if v := visible(cursor.Value(), it.tx.ts); v != nil {
+			it.batch = append(it.batch, engine.Entry{Key: cursor.Key(), Value: v.value})
+		}
+	}
+
+	if !cursor.Valid() {
+		it.done = true
+	}
+}
+
+func (it *rangeIterator) Key() string {
+	return it.batch[it.pos].Key
+}
+
+func (it *rangeIterator) Value() string {
+	return it.batch[it.pos].Value
+}
+
+func (it *rangeIterator) Err() error {
+	return it.err
+}
+
+func (it *rangeIterator) Close() error {
+	it.batch = nil
+	it.done = true
+
+	if it.ownsTx {
+		return it.tx.Close()
+	}
+
+	return nil
+}
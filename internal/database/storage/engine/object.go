@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Kind is the type of a value, every key holds a value of a single kind.
+type Kind uint8
+
+const (
+	KindString Kind = iota
+	KindList
+	KindHash
+	KindSet
+	KindSortedSet
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindList:
+		return "list"
+	case KindHash:
+		return "hash"
+	case KindSet:
+		return "set"
+	case KindSortedSet:
+		return "zset"
+	default:
+		return fmt.Sprintf("kind(%d)", uint8(k))
+	}
+}
+
+// object is a value of a collection kind, strings are kept in item.value
+// directly.
+type object interface {
+	kind() Kind
+	len() int
+	// size returns the approximate number of bytes taken by the object
+	size() int64
+	// items returns the content as it is stored in snapshots
+	items() []string
+}
+
+// newObject builds an object of the kind from its snapshot items.
+func newObject(kind Kind, items []string) (object, error) {
+	switch kind {
+	case KindList:
+		l := newList()
+		l.push(Right, items...)
+		return l, nil
+	case KindHash:
+		if len(items)%2 != 0 {
+			return nil, fmt.Errorf("%w: odd number of hash items", errInvalidItems)
+		}
+
+		h := newHash()
+		for i := 0; i < len(items); i += 2 {
+			h.set(items[i], items[i+1])
+		}
+		return h, nil
+	case KindSet:
+		set := newSet()
+		for _, member := range items {
+			set.add(member)
+		}
+		return set, nil
+	case KindSortedSet:
+		if len(items)%2 != 0 {
+			return nil, fmt.Errorf("%w: odd number of sorted set items", errInvalidItems)
+		}
+
+		z := newSortedSet()
+		for i := 0; i < len(items); i += 2 {
+			score, err := strconv.ParseFloat(items[i+1], 64)
+			if err != nil || math.IsNaN(score) {
+				return nil, fmt.Errorf("%w: invalid score %q", errInvalidItems, items[i+1])
+			}
+			z.add(items[i], score)
+		}
+		return z, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownKind, kind)
+	}
+}
@@ -0,0 +1,27 @@
+package engine
+
+type Option func(*options)
+
+type options struct {
+	maxMemory      int64
+	evictionPolicy EvictionPolicy
+}
+
+// WithMaxMemory limits the approximate memory used by the data, when the
+// limit is reached keys are evicted according to the policy. Zero limit
+// means no limit.
+func WithMaxMemory(limit int64, policy EvictionPolicy) Option {
+	return func(o *options) {
+		o.maxMemory = limit
+		o.evictionPolicy = policy
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := options{evictionPolicy: NoEviction}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/skiplist"
+)
+
+// OrderedEngine keeps keys in a skiplist, so besides point lookups it
+// serves range scans.
+type OrderedEngine struct {
+	mu   *sync.RWMutex
+	list *skiplist.SkipList[string, string]
+}
+
+func NewOrderedEngine() *OrderedEngine {
+	return &OrderedEngine{
+		mu:   &sync.RWMutex{},
+		list: skiplist.New[string, string](strings.Compare),
+	}
+}
+
+func (e *OrderedEngine) Get(ctx context.Context, key string) (string, bool, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	value, ok := e.list.Get(key)
+
+	return value, ok, nil
+}
+
+func (e *OrderedEngine) Set(ctx context.Context, key, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.list.Set(key, value)
+
+	return nil
+}
+
+// Update replaces the value with the result of fn under the engine lock.
+func (e *OrderedEngine) Update(ctx context.Context, key string, fn UpdateFunc) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	value, err := fn(e.list.Get(key))
+	if err != nil {
+		return "", err
+	}
+
+	e.list.Set(key, value)
+
+	return value, nil
+}
+
+func (e *OrderedEngine) Del(ctx context.Context, key string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.list.Delete(key), nil
+}
+
+func (e *OrderedEngine) Range(ctx context.Context, start, end string) (Iterator, error) {
+	return &rangeIterator{
+		engine: e,
+		from:   start,
+		end:    end,
+	}, nil
+}
+
+// Dump returns a consistent copy of the whole keyspace in key order.
+func (e *OrderedEngine) Dump(ctx context.Context) ([]Entry, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	entries := make([]Entry, 0, e.list.Len())
+	for it := e.list.First(); it.Valid(); it.Next() {
+		entries = append(entries, Entry{Key: it.Key(), Value: it.Value()})
+	}
+
+	return entries, nil
+}
+
+func (e *OrderedEngine) Restore(ctx context.Context, entry Entry) error {
+	if entry.Kind != KindString {
+		return fmt.Errorf("%w: %s", errUnsupportedKind, entry.Kind)
+	}
+
+	return e.Set(ctx, entry.Key, entry.Value)
+}
+
+// rangeBatchSize is the number of entries copied under a single read lock,
+// writers are not blocked for the whole scan.
+const rangeBatchSize = 128
+
+// rangeIterator reads the range in batches. Entries changed between
+// batches are seen in their latest state, like with redis SCAN.
+type rangeIterator struct {
+	engine *OrderedEngine
+	// the next batch starts from this key, excluding it after the first batch
+	from    string
+	started bool
+	end     string
+	batch   []Entry
+	pos     int
+	done    bool
+}
+
+func (it *rangeIterator) Next() bool {
+	if it.pos+1 < len(it.batch) {
+		it.pos++
+		return true
+	}
+
+	if it.done {
+		it.batch = nil
+		return false
+	}
+
+	it.fetch()
+	it.pos = 0
+
+	return len(it.batch) > 0
+}
+
+func (it *rangeIterator) fetch() {
+	it.engine.mu.RLock()
+	defer it.engine.mu.RUnlock()
+
+	it.batch = it.batch[:0]
+
+	cursor := it.engine.list.Seek(it.from)
+	if it.started && cursor.Valid() && cursor.Key() == it.from {
+		cursor.Next()
+	}
+
+	for ; cursor.Valid() && len(it.batch) < rangeBatchSize; cursor.Next() {
+		if it.end != "" && cursor.Key() >= it.end {
+			break
+		}
+
+		it.batch = append(it.batch, Entry{Key: cursor.Key(), Value: cursor.Value()})
+	}
+
+	if len(it.batch) < rangeBatchSize {
+		it.done = true
+	}
+	if len(it.batch) > 0 {
+		it.from = it.batch[len(it.batch)-1].Key
+		it.started = true
+	}
+}
+
+func (it *rangeIterator) Key() string {
+	return it.batch[it.pos].Key
+}
+
+func (it *rangeIterator) Value() string {
+	return it.batch[it.pos].Value
+}
+
+func (it *rangeIterator) Err() error {
+	return nil
+}
+
+func (it *rangeIterator) Close() error {
+	it.batch = nil
+	it.done = true
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedEngine(t *testing.T) {
+	ctx := context.Background()
+	engine := NewOrderedEngine()
+
+	require.NoError(t, engine.Set(ctx, "key", "value"))
+	value, _, err := engine.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	removed, err := engine.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	value, ok, err := engine.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, value)
+
+	removed, err = engine.Del(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestOrderedEngineRange(t *testing.T) {
+	ctx := context.Background()
+	engine := NewOrderedEngine()
+
+	// more keys than fit into a single batch
+	const keys = 3*rangeBatchSize + 10
+	for i := range keys {
+		require.NoError(t, engine.Set(ctx, fmt.Sprintf("%04d", i), fmt.Sprint(i)))
+	}
+
+	it, err := engine.Range(ctx, "0010", "")
+	require.NoError(t, err)
+	defer it.Close()
+
+	expected := 10
+	for it.Next() {
+		assert.Equal(t, fmt.Sprintf("%04d", expected), it.Key())
+		assert.Equal(t, fmt.Sprint(expected), it.Value())
+
+		// keys deleted after the batch is read are not returned
+		if expected == 10 {
+			_, err = engine.Del(ctx, fmt.Sprintf("%04d", 2*rangeBatchSize))
+			require.NoError(t, err)
+		}
+		expected++
+		if expected == 2*rangeBatchSize {
+			expected++
+		}
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, keys, expected)
+
+	it, err = engine.Range(ctx, "0005", "0008")
+	require.NoError(t, err)
+
+	var got []string
+	for it.Next() {
+		got = append(got, it.Key())
+	}
+	assert.Equal(t, []string{"0005", "0006", "0007"}, got)
+	assert.NoError(t, it.Close())
+	assert.False(t, it.Next())
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-viper/mapstructure/v2"
+
+	"github.com/cat-go-dev/kdb/internal/config"
+)
+
+type Interface interface {
+	// Get reports whether the key exists, so a missing key is told apart
+	// from an empty value
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+	// Del reports whether the key existed
+	Del(ctx context.Context, key string) (bool, error)
+}
+
+// Constructor builds an engine from its own config subsection, the one
+// named after the engine type.
+type Constructor[T any] func(cfg T, logger *slog.Logger) (Interface, error)
+
+type factory func(section any, logger *slog.Logger) (Interface, error)
+
+var (
+	registryMu = &sync.RWMutex{}
+	registry   = make(map[string]factory)
+)
+
+// DefaultType is used when the engine type is not configured.
+const DefaultType = InMemoryType
+
+// Register makes an engine available by name, it is meant to be called from
+// init functions and panics when the name is already taken.
+func Register[T any](name string, constructor Constructor[T]) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if constructor == nil {
+		panic("engine: nil constructor for " + name)
+	}
+	if _, ok := registry[name]; ok {
+		panic("engine: " + name + " is registered twice")
+	}
+
+	registry[name] = func(section any, logger *slog.Logger) (Interface, error) {
+		var cfg T
+		err := decodeSection(section, &cfg)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s config: %w", name, err)
+		}
+
+		return constructor(cfg, logger)
+	}
+}
+
+// Types returns names of all registered engines.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New builds the engine selected by cfg.Type.
+func New(cfg config.Engine, logger *slog.Logger) (Interface, error) {
+	name := cfg.Type
+	if name == "" {
+		name = DefaultType
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q, known engines: %s", errUnknownEngine, name, strings.Join(Types(), ", "))
+	}
+
+	return factory(cfg.Settings[name], logger)
+}
+
+func decodeSection(section any, cfg any) error {
+	if section == nil {
+		return nil
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           cfg,
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(section)
+}
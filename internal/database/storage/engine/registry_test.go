@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/config"
+)
+
+func TestNewDefaultEngine(t *testing.T) {
+	engine, err := New(config.Engine{}, getTestLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &Engine{}, engine)
+}
+
+func TestNewWithSettings(t *testing.T) {
+	cfg := config.Engine{
+		Type: ShardedInMemoryType,
+		Settings: map[string]any{
+			ShardedInMemoryType: map[string]any{
+				"shards":          4,
+				"max_memory":      "1KB",
+				"eviction_policy": "allkeys-lfu",
+			},
+			// settings of other engines are ignored
+			InMemoryType: map[string]any{"unknown": true},
+		},
+	}
+
+	engine, err := New(cfg, getTestLogger())
+	require.NoError(t, err)
+
+	sharded, ok := engine.(*ShardedEngine)
+	require.True(t, ok)
+	assert.Len(t, sharded.shards, 4)
+	assert.Equal(t, int64(1024), sharded.mem.limit)
+	assert.Equal(t, AllKeysLFU, sharded.mem.policy)
+}
+
+func TestNewInvalidSettings(t *testing.T) {
+	cfg := config.Engine{
+		Type: InMemoryType,
+		Settings: map[string]any{
+			InMemoryType: map[string]any{"max_memori": "1KB"},
+		},
+	}
+
+	_, err := New(cfg, getTestLogger())
+	assert.Error(t, err)
+
+	cfg.Settings[InMemoryType] = map[string]any{"max_memory": "1KB", "eviction_policy": "lru"}
+	_, err = New(cfg, getTestLogger())
+	assert.ErrorIs(t, err, errUnknownEvictionPolicy)
+
+	cfg.Settings[InMemoryType] = map[string]any{"eviction_policy": "lru"}
+	_, err = New(cfg, getTestLogger())
+	assert.ErrorIs(t, err, errUnknownEvictionPolicy)
+}
+
+func TestNewUnknownEngine(t *testing.T) {
+	_, err := New(config.Engine{Type: "btree"}, getTestLogger())
+	assert.ErrorIs(t, err, errUnknownEngine)
+	assert.ErrorContains(t, err, "in_memory, ordered, sharded_in_memory")
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		Register(InMemoryType, func(cfg InMemoryConfig, logger *slog.Logger) (Interface, error) {
+			return NewEngine(), nil
+		})
+	})
+}
+
+func getTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// Scanner is implemented by engines which can walk the keyspace with a
+// cursor. Scan starts from the cursor 0 and returns the cursor of the next
+// call, 0 means the walk is over. Every key present during the whole walk
+// is returned at least once, keys added or removed meanwhile may be missed.
+type Scanner interface {
+	Scan(ctx context.Context, cursor uint64, count int) ([]string, uint64, error)
+}
+
+// scanBucketsPerKey limits the number of buckets visited by a single call,
+// so sparse keyspaces do not make it walk all buckets at once.
+const scanBucketsPerKey = 10
+
+func (e *Engine) Scan(ctx context.Context, cursor uint64, count int) ([]string, uint64, error) {
+	keys, next := scanShards([]*shard{e.shard}, cursor, count)
+
+	return keys, next, nil
+}
+
+func (e *ShardedEngine) Scan(ctx context.Context, cursor uint64, count int) ([]string, uint64, error) {
+	keys, next := scanShards(e.shards, cursor, count)
+
+	return keys, next, nil
+}
+
+// scanShards walks buckets of all shards one after another, the cursor is
+// the global number of the next bucket. Keys never move between buckets,
+// so buckets already visited can not hide a key which was there before.
+func scanShards(shards []*shard, cursor uint64, count int) ([]string, uint64) {
+	if count <= 0 {
+		count = 1
+	}
+
+	total := uint64(len(shards)) * shardBuckets
+	now := time.Now().UnixNano()
+
+	var keys []string
+	for visited := 0; cursor < total && len(keys) < count && visited < count*scanBucketsPerKey; visited++ {
+		shard := shards[cursor/shardBuckets]
+		keys = shard.scanBucket(int(cursor%shardBuckets), keys, now)
+		cursor++
+	}
+
+	if cursor >= total {
+		return keys, 0
+	}
+
+	return keys, cursor
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+
+	engines := map[string]Scanner{
+		"engine":  NewEngine(),
+		"sharded": NewShardedEngine(4),
+	}
+
+	for name, scanner := range engines {
+		t.Run(name, func(t *testing.T) {
+			e := scanner.(interface {
+				Set(ctx context.Context, key, value string) error
+				Del(ctx context.Context, key string) (bool, error)
+			})
+
+			const keys = 1000
+			for i := range keys {
+				require.NoError(t, e.Set(ctx, fmt.Sprintf("key-%d", i), "value"))
+			}
+
+			seen := make(map[string]struct{})
+			cursor, calls := uint64(0), 0
+			for {
+				batch, next, err := scanner.Scan(ctx, cursor, 10)
+				require.NoError(t, err)
+				calls++
+
+				for _, key := range batch {
+					seen[key] = struct{}{}
+				}
+
+				// keys written during the walk do not hide the old ones
+				if calls%5 == 0 {
+					require.NoError(t, e.Set(ctx, fmt.Sprintf("new-%d", calls), "value"))
+					_, err = e.Del(ctx, fmt.Sprintf("new-%d", calls-5))
+					require.NoError(t, err)
+				}
+
+				if next == 0 {
+					break
+				}
+				cursor = next
+			}
+
+			for i := range keys {
+				assert.Contains(t, seen, fmt.Sprintf("key-%d", i))
+			}
+			assert.Greater(t, calls, 1)
+		})
+	}
+}
+
+func TestScanSkipsExpired(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	require.NoError(t, engine.Set(ctx, "alive", "value"))
+	require.NoError(t, engine.SetWithDeadline(ctx, "expired", "value", time.Now().Add(-time.Second)))
+
+	var keys []string
+	cursor := uint64(0)
+	for {
+		batch, next, err := engine.Scan(ctx, cursor, 100)
+		require.NoError(t, err)
+		keys = append(keys, batch...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Equal(t, []string{"alive"}, keys)
+}
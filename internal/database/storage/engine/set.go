@@ -0,0 +1,311 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// memberOverhead is the approximate memory taken by a set member besides
+// its bytes.
+const memberOverhead = 32
+
+type set struct {
+	members map[string]struct{}
+	bytes   int64
+}
+
+func newSet() *set {
+	return &set{members: make(map[string]struct{})}
+}
+
+func (s *set) kind() Kind {
+	return KindSet
+}
+
+func (s *set) len() int {
+	return len(s.members)
+}
+
+func (s *set) size() int64 {
+	return s.bytes + int64(len(s.members))*memberOverhead
+}
+
+// items returns members in order.
+func (s *set) items() []string {
+	members := make([]string, 0, len(s.members))
+	for member := range s.members {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members
+}
+
+func (s *set) add(member string) bool {
+	if _, ok := s.members[member]; ok {
+		return false
+	}
+
+	s.members[member] = struct{}{}
+	s.bytes += int64(len(member))
+
+	return true
+}
+
+func (s *set) remove(member string) bool {
+	if _, ok := s.members[member]; !ok {
+		return false
+	}
+
+	delete(s.members, member)
+	s.bytes -= int64(len(member))
+
+	return true
+}
+
+func (s *set) has(member string) bool {
+	_, ok := s.members[member]
+	return ok
+}
+
+// SetOp combines sets of several keys.
+type SetOp uint8
+
+const (
+	SetInter SetOp = iota
+	SetUnion
+	SetDiff
+)
+
+// Sets is implemented by engines which store set values. Operations on a
+// key holding a value of another kind fail with ports.ErrWrongType.
+type Sets interface {
+	// SetAdd returns the number of added members
+	SetAdd(ctx context.Context, key string, members ...string) (int, error)
+	// SetRemove returns the number of removed members
+	SetRemove(ctx context.Context, key string, members ...string) (int, error)
+	// SetMembers returns members in order
+	SetMembers(ctx context.Context, key string) ([]string, error)
+	SetIsMember(ctx context.Context, key, member string) (bool, error)
+	SetCard(ctx context.Context, key string) (int, error)
+	// SetCombine returns the ordered result of the operation over sets of
+	// the keys, missing keys are empty sets. Keys are read atomically.
+	SetCombine(ctx context.Context, op SetOp, keys ...string) ([]string, error)
+}
+
+func membersSize(members []string) int64 {
+	var size int64
+	for _, member := range members {
+		size += int64(len(member)) + memberOverhead
+	}
+
+	return size
+}
+
+func (s *shard) setAdd(key string, members []string, now int64) (int, error) {
+	var added int
+	err := s.collection(key, KindSet, membersSize(members), func() object { return newSet() }, now, func(obj object) error {
+		set := obj.(*set)
+		for _, member := range members {
+			if set.add(member) {
+				added++
+			}
+		}
+		return nil
+	})
+
+	return added, err
+}
+
+func (s *shard) setRemove(key string, members []string, now int64) (int, error) {
+	var removed int
+	err := s.collection(key, KindSet, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		set := obj.(*set)
+		for _, member := range members {
+			if set.remove(member) {
+				removed++
+			}
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+func (s *shard) setMembers(key string, now int64) ([]string, error) {
+	var members []string
+	err := s.collection(key, KindSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			members = obj.items()
+		}
+		return nil
+	})
+
+	return members, err
+}
+
+func (s *shard) setIsMember(key, member string, now int64) (bool, error) {
+	var ok bool
+	err := s.collection(key, KindSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			ok = obj.(*set).has(member)
+		}
+		return nil
+	})
+
+	return ok, err
+}
+
+func (s *shard) setCard(key string, now int64) (int, error) {
+	var card int
+	err := s.collection(key, KindSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			card = obj.len()
+		}
+		return nil
+	})
+
+	return card, err
+}
+
+// combineLocked runs the set operation, shards of all keys are locked by
+// the caller.
+func combineLocked(op SetOp, keys []string, now int64, shardFor func(key string) *shard) ([]string, error) {
+	sets := make([]*set, len(keys))
+	for i, key := range keys {
+		err := shardFor(key).collectionLocked(key, KindSet, 0, nil, now, func(obj object) error {
+			if obj != nil {
+				sets[i] = obj.(*set)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := newSet()
+	switch op {
+	case SetInter:
+		smallest := sets[0]
+		for _, set := range sets {
+			if set == nil {
+				return nil, nil
+			}
+			if set.len() < smallest.len() {
+				smallest = set
+			}
+		}
+
+	members:
+		for member := range smallest.members {
+			for _, set := range sets {
+				if !set.has(member) {
+					continue members
+				}
+			}
+			result.add(member)
+		}
+	case SetUnion:
+		for _, set := range sets {
+			if set == nil {
+				continue
+			}
+			for member := range set.members {
+				result.add(member)
+			}
+		}
+	case SetDiff:
+		if sets[0] == nil {
+			return nil, nil
+		}
+
+	diff:
+		for member := range sets[0].members {
+			for _, set := range sets[1:] {
+				if set != nil && set.has(member) {
+					continue diff
+				}
+			}
+			result.add(member)
+		}
+	}
+
+	return result.items(), nil
+}
+
+func (e *Engine) SetAdd(ctx context.Context, key string, members ...string) (int, error) {
+	return e.setAdd(key, members, time.Now().UnixNano())
+}
+
+func (e *Engine) SetRemove(ctx context.Context, key string, members ...string) (int, error) {
+	return e.setRemove(key, members, time.Now().UnixNano())
+}
+
+func (e *Engine) SetMembers(ctx context.Context, key string) ([]string, error) {
+	return e.setMembers(key, time.Now().UnixNano())
+}
+
+func (e *Engine) SetIsMember(ctx context.Context, key, member string) (bool, error) {
+	return e.setIsMember(key, member, time.Now().UnixNano())
+}
+
+func (e *Engine) SetCard(ctx context.Context, key string) (int, error) {
+	return e.setCard(key, time.Now().UnixNano())
+}
+
+func (e *Engine) SetCombine(ctx context.Context, op SetOp, keys ...string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return combineLocked(op, keys, time.Now().UnixNano(), func(string) *shard { return e.shard })
+}
+
+func (e *ShardedEngine) SetAdd(ctx context.Context, key string, members ...string) (int, error) {
+	target := e.shardFor(key)
+
+	var added int
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		added, err = target.setAdd(key, members, time.Now().UnixNano())
+		return membersSize(members), err
+	})
+
+	return added, err
+}
+
+func (e *ShardedEngine) SetRemove(ctx context.Context, key string, members ...string) (int, error) {
+	return e.shardFor(key).setRemove(key, members, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) SetMembers(ctx context.Context, key string) ([]string, error) {
+	return e.shardFor(key).setMembers(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) SetIsMember(ctx context.Context, key, member string) (bool, error) {
+	return e.shardFor(key).setIsMember(key, member, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) SetCard(ctx context.Context, key string) (int, error) {
+	return e.shardFor(key).setCard(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) SetCombine(ctx context.Context, op SetOp, keys ...string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	unlock := e.lockShards(keys)
+	defer unlock()
+
+	return combineLocked(op, keys, time.Now().UnixNano(), e.shardFor)
+}
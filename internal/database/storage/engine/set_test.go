@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestEngineSets(t *testing.T) {
+	ctx := context.Background()
+
+	engines := []struct {
+		name string
+		sets interface {
+			Sets
+			Set(ctx context.Context, key, value string) error
+			UsedMemory() int64
+		}
+	}{
+		{"engine", NewEngine()},
+		{"sharded", NewShardedEngine(4)},
+	}
+
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			added, err := e.sets.SetAdd(ctx, "a", "x", "y", "z", "x")
+			require.NoError(t, err)
+			assert.Equal(t, 3, added)
+
+			_, err = e.sets.SetAdd(ctx, "b", "y", "z", "w")
+			require.NoError(t, err)
+
+			members, err := e.sets.SetMembers(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"x", "y", "z"}, members)
+
+			ok, err := e.sets.SetIsMember(ctx, "b", "w")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			tests := []struct {
+				op       SetOp
+				keys     []string
+				expected []string
+			}{
+				{SetInter, []string{"a", "b"}, []string{"y", "z"}},
+				{SetInter, []string{"a", "missing"}, nil},
+				{SetUnion, []string{"a", "b", "missing"}, []string{"w", "x", "y", "z"}},
+				{SetDiff, []string{"a", "b"}, []string{"x"}},
+				{SetDiff, []string{"missing", "a"}, nil},
+			}
+			for _, tt := range tests {
+				members, err := e.sets.SetCombine(ctx, tt.op, tt.keys...)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, members, "%d of %v", tt.op, tt.keys)
+			}
+
+			require.NoError(t, e.sets.Set(ctx, "string", "value"))
+			_, err = e.sets.SetCombine(ctx, SetUnion, "a", "string")
+			assert.ErrorIs(t, err, ports.ErrWrongType)
+			require.NoError(t, e.sets.Set(ctx, "string", ""))
+
+			removed, err := e.sets.SetRemove(ctx, "a", "x", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, 1, removed)
+
+			card, err := e.sets.SetCard(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, 2, card)
+		})
+	}
+}
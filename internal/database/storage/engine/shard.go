@@ -0,0 +1,553 @@
+package engine
+
+import (
+	"hash/maphash"
+	"math/rand"
+	"sync"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// shard is a single lock-protected part of the keyspace. Engine is backed
+// by one shard, ShardedEngine spreads keys over many of them.
+type shard struct {
+	mu *sync.RWMutex
+	// keys are spread over a fixed number of buckets, a key never moves to
+	// another bucket so SCAN can walk them with a stable cursor
+	buckets []map[string]*item
+	seed    maphash.Seed
+	count   int
+	// deadlines of volatile keys in unix nanoseconds
+	expires map[string]int64
+	mem     *memory
+}
+
+// shardBuckets has to be a power of two.
+const shardBuckets = 1024
+
+const (
+	// active expiration samples that many volatile keys per round and
+	// continues while more than 1/expireRepeatRatio of them were expired
+	expireSampleSize  = 20
+	expireRepeatRatio = 4
+	expireMaxRounds   = 16
+)
+
+func newShard(mem *memory) *shard {
+	return &shard{
+		mu:      &sync.RWMutex{},
+		buckets: make([]map[string]*item, shardBuckets),
+		seed:    maphash.MakeSeed(),
+		expires: make(map[string]int64),
+		mem:     mem,
+	}
+}
+
+func (s *shard) bucketFor(key string) int {
+	return int(maphash.String(s.seed, key) & (shardBuckets - 1))
+}
+
+func (s *shard) lookup(key string) (*item, bool) {
+	it, ok := s.buckets[s.bucketFor(key)][key]
+
+	return it, ok
+}
+
+func (s *shard) len() int {
+	return s.count
+}
+
+// get returns the string value, a collection value fails with
+// ports.ErrWrongType.
+func (s *shard) get(key string, now int64) (string, bool, error) {
+	s.mu.RLock()
+	it, ok := s.lookup(key)
+	deadline, volatile := s.expires[key]
+	var value string
+	var obj object
+	if ok {
+		value, obj = it.value, it.obj
+		it.touch(now)
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return "", false, nil
+	}
+
+	if volatile && deadline <= now {
+		s.mu.Lock()
+		s.expireLocked(key, now)
+		s.mu.Unlock()
+
+		return "", false, nil
+	}
+
+	if obj != nil {
+		return "", false, ports.ErrWrongType
+	}
+
+	return value, true, nil
+}
+
+// set stores the value, deadline 0 makes the key persistent. It fails with
+// ports.ErrOutOfMemory when the memory limit can not be satisfied.
+func (s *shard) set(key, value string, deadline, now int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.setLocked(key, value, deadline, now, false)
+}
+
+// setLocked stores the value, with force the memory limit is ignored which
+// is used for restoring data.
+func (s *shard) setLocked(key, value string, deadline, now int64, force bool) error {
+	delta := itemSize(key, value)
+	old, exists := s.lookup(key)
+	if exists {
+		delta -= old.size(key)
+	}
+
+	_, volatile := s.expires[key]
+	switch {
+	case deadline > 0 && !volatile:
+		delta += expireOverhead
+	case deadline == 0 && volatile:
+		delta -= expireOverhead
+	}
+
+	if !force {
+		err := s.evictLocked(delta, key, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if exists {
+		old.value, old.obj = value, nil
+		old.touch(now)
+	} else {
+		s.insertLocked(key, newItem(value, now))
+	}
+
+	if deadline > 0 {
+		s.expires[key] = deadline
+	} else {
+		delete(s.expires, key)
+	}
+
+	s.mem.add(delta)
+
+	return nil
+}
+
+// update stores the result of fn keeping the key deadline, the new value is
+// returned even when it does not fit into the memory limit.
+func (s *shard) update(key string, fn UpdateFunc, now int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var old string
+	exists := s.existsLocked(key, now)
+	if exists {
+		it, _ := s.lookup(key)
+		if it.obj != nil {
+			return "", ports.ErrWrongType
+		}
+		old = it.value
+	}
+
+	value, err := fn(old, exists)
+	if err != nil {
+		return "", err
+	}
+
+	return value, s.setLocked(key, value, s.expires[key], now, false)
+}
+
+// collection calls fn with the object of the key, it has to be of the kind.
+// A missing key gets a new object from create, fn gets nil when create is
+// nil. Objects left empty by fn are removed. grow is the estimated growth
+// of the object, the memory limit is enforced before fn is called.
+func (s *shard) collection(key string, kind Kind, grow int64, create func() object, now int64, fn func(obj object) error) error {
+	// reads take the write lock too, expired keys are removed on access
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.collectionLocked(key, kind, grow, create, now, fn)
+}
+
+func (s *shard) collectionLocked(key string, kind Kind, grow int64, create func() object, now int64, fn func(obj object) error) error {
+	var it *item
+	if s.existsLocked(key, now) {
+		it, _ = s.lookup(key)
+		if it.obj == nil || it.obj.kind() != kind {
+			return ports.ErrWrongType
+		}
+		it.touch(now)
+	}
+
+	if it == nil && create == nil {
+		return fn(nil)
+	}
+
+	err := s.evictLocked(grow, key, now)
+	if err != nil {
+		return err
+	}
+
+	if it == nil {
+		it = newItem("", now)
+		it.obj = create()
+		s.insertLocked(key, it)
+		s.mem.add(it.size(key))
+	}
+
+	before := it.obj.size()
+	err = fn(it.obj)
+	s.mem.add(it.obj.size() - before)
+
+	if it.obj.len() == 0 {
+		s.removeLocked(key)
+	}
+
+	return err
+}
+
+// restoreLocked stores a snapshot entry ignoring the memory limit.
+func (s *shard) restoreLocked(entry Entry, now int64) error {
+	if entry.Kind == KindString {
+		return s.setLocked(entry.Key, entry.Value, entry.ExpireAt, now, true)
+	}
+
+	obj, err := newObject(entry.Kind, entry.Items)
+	if err != nil {
+		return err
+	}
+
+	s.removeLocked(entry.Key)
+
+	it := newItem("", now)
+	it.obj = obj
+	s.insertLocked(entry.Key, it)
+
+	size := it.size(entry.Key)
+	if entry.ExpireAt > 0 {
+		s.expires[entry.Key] = entry.ExpireAt
+		size += expireOverhead
+	}
+	s.mem.add(size)
+
+	return nil
+}
+
+// del removes the key and reports whether it was alive.
+func (s *shard) del(key string, now int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.existsLocked(key, now) {
+		return false
+	}
+	s.removeLocked(key)
+
+	return true
+}
+
+func (s *shard) expire(key string, deadline, now int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.existsLocked(key, now) {
+		return false
+	}
+
+	if deadline <= now {
+		s.removeLocked(key)
+		return true
+	}
+
+	if _, volatile := s.expires[key]; !volatile {
+		s.mem.add(expireOverhead)
+	}
+	s.expires[key] = deadline
+
+	return true
+}
+
+// deadline returns the key deadline, 0 for a persistent key.
+func (s *shard) deadline(key string, now int64) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.existsLocked(key, now) {
+		return 0, false
+	}
+
+	return s.expires[key], true
+}
+
+func (s *shard) persist(key string, now int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.existsLocked(key, now) {
+		return false
+	}
+
+	_, volatile := s.expires[key]
+	if volatile {
+		delete(s.expires, key)
+		s.mem.add(-expireOverhead)
+	}
+
+	return volatile
+}
+
+// deleteExpired samples volatile keys and removes expired ones. The lock is
+// released between rounds so requests are not blocked for long.
+func (s *shard) deleteExpired(now int64) int {
+	deleted := 0
+
+	for range expireMaxRounds {
+		s.mu.Lock()
+		sampled, expired := 0, 0
+		for key, deadline := range s.expires {
+			if sampled == expireSampleSize {
+				break
+			}
+			sampled++
+
+			if deadline <= now {
+				s.removeLocked(key)
+				expired++
+			}
+		}
+		s.mu.Unlock()
+
+		deleted += expired
+		if sampled == 0 || expired*expireRepeatRatio <= sampled {
+			break
+		}
+	}
+
+	return deleted
+}
+
+// existsLocked reports whether the key is alive and lazily removes it when
+// it is expired, the caller holds the write lock.
+func (s *shard) existsLocked(key string, now int64) bool {
+	if _, ok := s.lookup(key); !ok {
+		return false
+	}
+
+	return !s.expireLocked(key, now)
+}
+
+func (s *shard) expireLocked(key string, now int64) bool {
+	deadline, volatile := s.expires[key]
+	if !volatile || deadline > now {
+		return false
+	}
+
+	s.removeLocked(key)
+
+	return true
+}
+
+// insertLocked adds a new key, memory is accounted by the caller.
+func (s *shard) insertLocked(key string, it *item) {
+	bucket := s.bucketFor(key)
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]*item)
+	}
+
+	s.buckets[bucket][key] = it
+	s.count++
+}
+
+func (s *shard) removeLocked(key string) {
+	bucket := s.bucketFor(key)
+	it, ok := s.buckets[bucket][key]
+	if !ok {
+		return
+	}
+
+	delta := s.footprintLocked(key, it)
+
+	delete(s.buckets[bucket], key)
+	delete(s.expires, key)
+	s.count--
+	s.mem.add(-delta)
+}
+
+// footprintLocked is the memory taken by the key together with its deadline.
+func (s *shard) footprintLocked(key string, it *item) int64 {
+	size := it.size(key)
+	if _, volatile := s.expires[key]; volatile {
+		size += expireOverhead
+	}
+
+	return size
+}
+
+// evictLocked frees memory in the shard until growing by delta bytes fits
+// into the limit. The key being written is never evicted. Deferred writes
+// do not evict, their keys were evicted before the write.
+func (s *shard) evictLocked(delta int64, skip string, now int64) error {
+	if s.mem.deferred.Load() {
+		return nil
+	}
+
+	victims, _ := s.victimsLocked(delta, skip, now)
+	for _, victim := range victims {
+		s.removeLocked(victim)
+	}
+
+	if s.mem.exceeds(delta) {
+		return ports.ErrOutOfMemory
+	}
+
+	return nil
+}
+
+// victims picks keys to evict before writing size bytes into the key, the
+// keys are not removed. The part of the growth which still does not fit is
+// returned too.
+func (s *shard) victims(key string, size, now int64) ([]string, int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	delta := int64(len(key)+itemOverhead) + size
+	if it, ok := s.lookup(key); ok {
+		delta = size
+		if it.obj == nil {
+			delta -= int64(len(it.value))
+		}
+	}
+
+	return s.victimsLocked(delta, key, now)
+}
+
+// victimsLocked picks keys to evict until growing by delta bytes fits into
+// the limit or the shard has nothing left to evict, it returns them with
+// the rest of delta.
+func (s *shard) victimsLocked(delta int64, skip string, now int64) ([]string, int64) {
+	if !s.mem.exceeds(delta) {
+		return nil, delta
+	}
+
+	var victims []string
+	picked := map[string]struct{}{skip: {}}
+	for s.mem.exceeds(delta) {
+		victim, ok := s.evictionCandidateLocked(picked, now)
+		if !ok {
+			break
+		}
+
+		it, _ := s.lookup(victim)
+		delta -= s.footprintLocked(victim, it)
+		picked[victim] = struct{}{}
+		victims = append(victims, victim)
+	}
+
+	return victims, delta
+}
+
+// evictionCandidateLocked samples a few keys according to the policy and
+// picks the best one to evict, the same way redis approximates LRU and LFU.
+func (s *shard) evictionCandidateLocked(skip map[string]struct{}, now int64) (string, bool) {
+	var best string
+	var bestScore int64
+	found := false
+	sampled := 0
+
+	// skipped keys are not counted, so picked victims do not take the
+	// places of new ones in the sample
+	consider := func(key string) bool {
+		if _, ok := skip[key]; ok {
+			return true
+		}
+		sampled++
+
+		it, _ := s.lookup(key)
+
+		var score int64
+		switch s.mem.policy {
+		case AllKeysLRU, VolatileLRU:
+			score = it.atime.Load()
+		case AllKeysLFU:
+			score = int64(it.decayedFreq(now))
+		}
+
+		if !found || score < bestScore {
+			best, bestScore, found = key, score, true
+		}
+
+		return s.mem.policy != AllKeysRandom
+	}
+
+	switch s.mem.policy {
+	case VolatileLRU:
+		for key := range s.expires {
+			if sampled == evictionSampleSize || !consider(key) {
+				break
+			}
+		}
+	case AllKeysLRU, AllKeysLFU, AllKeysRandom:
+		// starts from a random bucket, so sampling is not stuck on the
+		// first buckets
+		first := rand.Intn(shardBuckets)
+		for i := 0; i < shardBuckets && sampled < evictionSampleSize; i++ {
+			stop := false
+			for key := range s.buckets[(first+i)&(shardBuckets-1)] {
+				if sampled == evictionSampleSize || !consider(key) {
+					stop = true
+					break
+				}
+			}
+			if stop {
+				break
+			}
+		}
+	}
+
+	return best, found
+}
+
+// dumpLocked appends all alive entries of the shard, the caller holds the lock.
+func (s *shard) dumpLocked(entries []Entry, now int64) []Entry {
+	for _, bucket := range s.buckets {
+		for key, it := range bucket {
+			deadline, volatile := s.expires[key]
+			if volatile && deadline <= now {
+				continue
+			}
+
+			entry := Entry{Key: key, Value: it.value, ExpireAt: deadline}
+			if it.obj != nil {
+				entry.Kind, entry.Items = it.obj.kind(), it.obj.items()
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// scanBucket appends alive keys of the bucket.
+func (s *shard) scanBucket(bucket int, keys []string, now int64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for key := range s.buckets[bucket] {
+		deadline, volatile := s.expires[key]
+		if volatile && deadline <= now {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
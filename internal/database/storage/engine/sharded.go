@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+type ShardedEngine struct {
+	shards []*shard
+	mask   uint64
+	seed   maphash.Seed
+	mem    *memory
+}
+
+const shardsPerProc = 4
+
+// NewShardedEngine creates an engine with the number of shards rounded up
+// to a power of two, zero means a default based on GOMAXPROCS.
+func NewShardedEngine(shardsNum int, opts ...Option) *ShardedEngine {
+	if shardsNum <= 0 {
+		shardsNum = runtime.GOMAXPROCS(0) * shardsPerProc
+	}
+
+	size := 1
+	for size < shardsNum {
+		size <<= 1
+	}
+
+	o := applyOptions(opts)
+	mem := newMemory(o.maxMemory, o.evictionPolicy)
+
+	shards := make([]*shard, size)
+	for i := range shards {
+		shards[i] = newShard(mem)
+	}
+
+	return &ShardedEngine{
+		shards: shards,
+		mask:   uint64(size - 1),
+		seed:   maphash.MakeSeed(),
+		mem:    mem,
+	}
+}
+
+func (e *ShardedEngine) Get(ctx context.Context, key string) (string, bool, error) {
+	return e.shardFor(key).get(key, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) Set(ctx context.Context, key, value string) error {
+	return e.set(key, value, 0)
+}
+
+func (e *ShardedEngine) Del(ctx context.Context, key string) (bool, error) {
+	return e.shardFor(key).del(key, time.Now().UnixNano()), nil
+}
+
+// UsedMemory returns the approximate number of bytes taken by the data.
+func (e *ShardedEngine) UsedMemory() int64 {
+	return e.mem.used.Load()
+}
+
+func (e *ShardedEngine) shardFor(key string) *shard {
+	return e.shards[e.shardIndex(key)]
+}
+
+func (e *ShardedEngine) shardIndex(key string) uint64 {
+	return maphash.String(e.seed, key) & e.mask
+}
+
+// lockShards locks the shards of all keys in the order of their indexes, so
+// multi-key operations do not deadlock each other. It returns the unlock.
+func (e *ShardedEngine) lockShards(keys []string) func() {
+	indexes := make([]uint64, 0, len(keys))
+	for _, key := range keys {
+		indexes = append(indexes, e.shardIndex(key))
+	}
+	slices.Sort(indexes)
+	indexes = slices.Compact(indexes)
+
+	for _, i := range indexes {
+		e.shards[i].mu.Lock()
+	}
+
+	return func() {
+		for _, i := range indexes {
+			e.shards[i].mu.Unlock()
+		}
+	}
+}
+
+func (e *ShardedEngine) set(key, value string, deadline int64) error {
+	target := e.shardFor(key)
+
+	return e.withEviction(target, func() (int64, error) {
+		err := target.set(key, value, deadline, time.Now().UnixNano())
+		return itemSize(key, value), err
+	})
+}
+
+// Update replaces the value with the result of fn under the shard lock.
+func (e *ShardedEngine) Update(ctx context.Context, key string, fn UpdateFunc) (string, error) {
+	target := e.shardFor(key)
+
+	var value string
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		value, err = target.update(key, fn, time.Now().UnixNano())
+		return itemSize(key, value), err
+	})
+
+	return value, err
+}
+
+// withEviction runs the write which returns the size of the written item.
+// The write evicts from the key shard first, when it has nothing left to
+// evict the other shards are asked to free memory and the write is retried.
+func (e *ShardedEngine) withEviction(target *shard, write func() (int64, error)) error {
+	size, err := write()
+	if !errors.Is(err, ports.ErrOutOfMemory) || e.mem.policy == NoEviction {
+		return err
+	}
+
+	need := size + expireOverhead
+	for _, shard := range e.shards {
+		if shard == target {
+			continue
+		}
+
+		shard.mu.Lock()
+		_ = shard.evictLocked(need, "", time.Now().UnixNano())
+		shard.mu.Unlock()
+
+		if !e.mem.exceeds(need) {
+			break
+		}
+	}
+
+	_, err = write()
+
+	return err
+}
+
+// DeferEviction makes writes ignore the memory limit, keys are evicted by
+// the caller with Victims before the write instead.
+func (e *ShardedEngine) DeferEviction() {
+	e.mem.deferred.Store(true)
+}
+
+// Victims picks keys to evict before writing size bytes into the key. Keys
+// of the key shard are picked first, other shards are asked when it has
+// nothing left to evict.
+func (e *ShardedEngine) Victims(ctx context.Context, key string, size int64) ([]string, error) {
+	now := time.Now().UnixNano()
+
+	target := e.shardFor(key)
+	victims, rest := target.victims(key, size, now)
+	for _, shard := range e.shards {
+		if !e.mem.exceeds(rest) {
+			break
+		}
+		if shard == target {
+			continue
+		}
+
+		shard.mu.RLock()
+		more, left := shard.victimsLocked(rest, "", now)
+		shard.mu.RUnlock()
+
+		victims, rest = append(victims, more...), left
+	}
+
+	if e.mem.exceeds(rest) {
+		return nil, ports.ErrOutOfMemory
+	}
+
+	return victims, nil
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShardedEngineRoundsShards(t *testing.T) {
+	tests := []struct {
+		name     string
+		shards   int
+		expected int
+	}{
+		{name: "power of two", shards: 8, expected: 8},
+		{name: "rounded up", shards: 5, expected: 8},
+		{name: "single shard", shards: 1, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewShardedEngine(tt.shards)
+			assert.Len(t, engine.shards, tt.expected)
+			assert.Equal(t, uint64(tt.expected-1), engine.mask)
+		})
+	}
+
+	assert.NotEmpty(t, NewShardedEngine(0).shards)
+}
+
+func TestShardedEngine(t *testing.T) {
+	ctx := context.Background()
+	engine := NewShardedEngine(16)
+
+	data := getTestData()
+
+	wg := &sync.WaitGroup{}
+	for i := range data {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+
+			err := engine.Set(ctx, item, item)
+			assert.Nil(t, err)
+		}(data[i])
+	}
+	wg.Wait()
+
+	used := 0
+	for _, shard := range engine.shards {
+		if shard.len() > 0 {
+			used++
+		}
+	}
+	assert.Greater(t, used, 1)
+
+	for i := range data {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+
+			value, _, err := engine.Get(ctx, item)
+			assert.Nil(t, err)
+			assert.Equal(t, item, value)
+		}(data[i])
+	}
+	wg.Wait()
+
+	entries, err := engine.Dump(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(data))
+
+	for i := range data {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+
+			removed, err := engine.Del(ctx, item)
+			assert.Nil(t, err)
+			assert.True(t, removed)
+		}(data[i])
+	}
+	wg.Wait()
+
+	for _, shard := range engine.shards {
+		assert.Zero(t, shard.len())
+	}
+}
@@ -0,0 +1,216 @@
+package skiplist
+
+import "math/rand"
+
+// SkipList is an ordered map. It is not safe for concurrent use, callers
+// have to synchronize access.
+type SkipList[K, V any] struct {
+	compare func(a, b K) int
+	head    *node[K, V]
+	level   int
+	length  int
+}
+
+type node[K, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V]
+	// span[i] is the number of level 0 steps to next[i], it gives ranks
+	span []int
+}
+
+const (
+	maxLevel    = 32
+	probability = 0.25
+)
+
+// New creates a list ordered by compare, which returns a negative number
+// when a < b, zero when a == b and a positive number otherwise.
+func New[K, V any](compare func(a, b K) int) *SkipList[K, V] {
+	return &SkipList[K, V]{
+		compare: compare,
+		head:    &node[K, V]{next: make([]*node[K, V], maxLevel), span: make([]int, maxLevel)},
+		level:   1,
+	}
+}
+
+func (s *SkipList[K, V]) Len() int {
+	return s.length
+}
+
+func (s *SkipList[K, V]) Get(key K) (V, bool) {
+	n := s.lowerBound(key, nil, nil)
+	if n != nil && s.compare(n.key, key) == 0 {
+		return n.value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set inserts or replaces the value, it reports whether the key was new.
+func (s *SkipList[K, V]) Set(key K, value V) bool {
+	var update [maxLevel]*node[K, V]
+	var rank [maxLevel]int
+
+	n := s.lowerBound(key, update[:], rank[:])
+	if n != nil && s.compare(n.key, key) == 0 {
+		n.value = value
+		return false
+	}
+
+	level := randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			update[i] = s.head
+			rank[i] = 0
+			s.head.span[i] = s.length
+		}
+		s.level = level
+	}
+
+	n = &node[K, V]{key: key, value: value, next: make([]*node[K, V], level), span: make([]int, level)}
+	for i := range level {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < s.level; i++ {
+		update[i].span[i]++
+	}
+	s.length++
+
+	return true
+}
+
+// Delete removes the key and reports whether it existed.
+func (s *SkipList[K, V]) Delete(key K) bool {
+	var update [maxLevel]*node[K, V]
+
+	n := s.lowerBound(key, update[:], nil)
+	if n == nil || s.compare(n.key, key) != 0 {
+		return false
+	}
+
+	for i := range s.level {
+		if update[i].next[i] == n {
+			update[i].span[i] += n.span[i] - 1
+			update[i].next[i] = n.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.length--
+
+	return true
+}
+
+// Seek returns an iterator positioned at the first key >= key.
+func (s *SkipList[K, V]) Seek(key K) *Iterator[K, V] {
+	return &Iterator[K, V]{node: s.lowerBound(key, nil, nil)}
+}
+
+// Rank returns the zero-based position of the key.
+func (s *SkipList[K, V]) Rank(key K) (int, bool) {
+	rank := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && s.compare(x.next[i].key, key) <= 0 {
+			rank += x.span[i]
+			x = x.next[i]
+		}
+
+		if x != s.head && s.compare(x.key, key) == 0 {
+			return rank - 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// SeekRank returns an iterator positioned at the zero-based rank, it is
+// not valid when the rank is out of range.
+func (s *SkipList[K, V]) SeekRank(rank int) *Iterator[K, V] {
+	if rank < 0 || rank >= s.length {
+		return &Iterator[K, V]{}
+	}
+
+	traversed := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.span[i] <= rank+1 {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+
+		if traversed == rank+1 {
+			break
+		}
+	}
+
+	return &Iterator[K, V]{node: x}
+}
+
+// First returns an iterator positioned at the smallest key.
+func (s *SkipList[K, V]) First() *Iterator[K, V] {
+	return &Iterator[K, V]{node: s.head.next[0]}
+}
+
+// lowerBound finds the first node with key >= key, update collects the last
+// node before it on every level and rank the number of nodes up to them.
+func (s *SkipList[K, V]) lowerBound(key K, update []*node[K, V], rank []int) *node[K, V] {
+	traversed := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && s.compare(x.next[i].key, key) < 0 {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+
+		if update != nil {
+			update[i] = x
+		}
+		if rank != nil {
+			rank[i] = traversed
+		}
+	}
+
+	return x.next[0]
+}
+
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.Float64() < probability {
+		level++
+	}
+
+	return level
+}
+
+// Iterator walks the list in order. It is invalidated by any modification
+// of the list.
+type Iterator[K, V any] struct {
+	node *node[K, V]
+}
+
+func (it *Iterator[K, V]) Valid() bool {
+	return it.node != nil
+}
+
+func (it *Iterator[K, V]) Key() K {
+	return it.node.key
+}
+
+func (it *Iterator[K, V]) Value() V {
+	return it.node.value
+}
+
+func (it *Iterator[K, V]) Next() {
+	it.node = it.node.next[0]
+}
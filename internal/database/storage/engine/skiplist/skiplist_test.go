@@ -0,0 +1,99 @@
+package skiplist
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	list := New[string, int](strings.Compare)
+
+	assert.True(t, list.Set("b", 2))
+	assert.True(t, list.Set("a", 1))
+	assert.False(t, list.Set("b", 3))
+	assert.Equal(t, 2, list.Len())
+
+	value, ok := list.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	_, ok = list.Get("c")
+	assert.False(t, ok)
+
+	assert.True(t, list.Delete("a"))
+	assert.False(t, list.Delete("a"))
+	assert.Equal(t, 1, list.Len())
+}
+
+func TestOrder(t *testing.T) {
+	list := New[int, struct{}](func(a, b int) int { return a - b })
+
+	expected := make(map[int]struct{})
+	for range 1000 {
+		key := rand.Intn(500)
+		list.Set(key, struct{}{})
+		expected[key] = struct{}{}
+	}
+	for key := range 100 {
+		list.Delete(key)
+		delete(expected, key)
+	}
+
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	var got []int
+	for it := list.First(); it.Valid(); it.Next() {
+		got = append(got, it.Key())
+	}
+	assert.Equal(t, keys, got)
+	assert.Equal(t, len(keys), list.Len())
+
+	it := list.Seek(250)
+	assert.True(t, it.Valid())
+	assert.Equal(t, keys[sort.SearchInts(keys, 250)], it.Key())
+}
+
+func TestRank(t *testing.T) {
+	list := New[int, struct{}](func(a, b int) int { return a - b })
+
+	expected := make(map[int]struct{})
+	for range 2000 {
+		key := rand.Intn(1000)
+		list.Set(key, struct{}{})
+		expected[key] = struct{}{}
+	}
+	for range 500 {
+		key := rand.Intn(1000)
+		list.Delete(key)
+		delete(expected, key)
+	}
+
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	for i, key := range keys {
+		rank, ok := list.Rank(key)
+		assert.True(t, ok)
+		assert.Equal(t, i, rank, "rank of %d", key)
+
+		it := list.SeekRank(i)
+		assert.True(t, it.Valid())
+		assert.Equal(t, key, it.Key(), "key at %d", i)
+	}
+
+	_, ok := list.Rank(-1)
+	assert.False(t, ok)
+	assert.False(t, list.SeekRank(len(keys)).Valid())
+	assert.False(t, list.SeekRank(-1).Valid())
+}
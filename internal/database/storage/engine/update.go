@@ -0,0 +1,13 @@
+package engine
+
+import "context"
+
+// UpdateFunc returns the new value for the current one, exists reports
+// whether the key is present. An error leaves the key unchanged.
+type UpdateFunc func(value string, exists bool) (string, error)
+
+// Updater is implemented by engines which can atomically read and modify a
+// key, fn is called under the engine lock.
+type Updater interface {
+	Update(ctx context.Context, key string, fn UpdateFunc) (string, error)
+}
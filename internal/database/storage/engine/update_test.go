@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	engines := map[string]Updater{
+		"engine":  NewEngine(),
+		"sharded": NewShardedEngine(4),
+		"ordered": NewOrderedEngine(),
+	}
+
+	appendFn := func(value string, exists bool) (string, error) {
+		if !exists {
+			return "a", nil
+		}
+		return value + "a", nil
+	}
+
+	for name, updater := range engines {
+		t.Run(name, func(t *testing.T) {
+			value, err := updater.Update(ctx, "key", appendFn)
+			assert.NoError(t, err)
+			assert.Equal(t, "a", value)
+
+			value, err = updater.Update(ctx, "key", appendFn)
+			assert.NoError(t, err)
+			assert.Equal(t, "aa", value)
+
+			failure := errors.New("failure")
+			_, err = updater.Update(ctx, "key", func(string, bool) (string, error) {
+				return "", failure
+			})
+			assert.ErrorIs(t, err, failure)
+
+			stored, _, err := updater.(Interface).Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, "aa", stored)
+		})
+	}
+}
+
+func TestUpdateKeepsDeadline(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	deadline := time.Now().Add(time.Hour)
+	require.NoError(t, engine.SetWithDeadline(ctx, "key", "1", deadline))
+
+	_, err := engine.Update(ctx, "key", func(string, bool) (string, error) {
+		return "2", nil
+	})
+	require.NoError(t, err)
+
+	actual, ok, err := engine.Deadline(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, deadline.UnixNano(), actual.UnixNano())
+}
@@ -0,0 +1,17 @@
+package engine
+
+import "context"
+
+// Versioned is implemented by engines which keep old versions of keys, so
+// readers get a consistent view of the keyspace without blocking writers.
+type Versioned interface {
+	BeginRead(ctx context.Context) (ReadTx, error)
+}
+
+// ReadTx is a read-only snapshot of the keyspace, writes committed after it
+// was opened are not visible. It must be closed to let old versions go.
+type ReadTx interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Ordered
+	Close() error
+}
@@ -0,0 +1,357 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/skiplist"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// scoredOverhead is the approximate memory taken by a sorted set member
+// besides its bytes: the map entry and the skiplist node.
+const scoredOverhead = 96
+
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// ScoreBound limits ZRangeByScore, infinite values make open ranges.
+type ScoreBound struct {
+	Value     float64
+	Exclusive bool
+}
+
+func compareScored(a, b ScoredMember) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Member, b.Member)
+}
+
+// sortedSet keeps members ordered by score and then by member in a skiplist,
+// the map gives scores of members.
+type sortedSet struct {
+	scores map[string]float64
+	list   *skiplist.SkipList[ScoredMember, struct{}]
+	bytes  int64
+}
+
+func newSortedSet() *sortedSet {
+	return &sortedSet{
+		scores: make(map[string]float64),
+		list:   skiplist.New[ScoredMember, struct{}](compareScored),
+	}
+}
+
+func (z *sortedSet) kind() Kind {
+	return KindSortedSet
+}
+
+func (z *sortedSet) len() int {
+	return len(z.scores)
+}
+
+func (z *sortedSet) size() int64 {
+	return z.bytes + int64(len(z.scores))*scoredOverhead
+}
+
+// items returns member score pairs in order, scores keep full precision.
+func (z *sortedSet) items() []string {
+	items := make([]string, 0, 2*z.len())
+	for it := z.list.First(); it.Valid(); it.Next() {
+		items = append(items, it.Key().Member, strconv.FormatFloat(it.Key().Score, 'g', -1, 64))
+	}
+
+	return items
+}
+
+// add sets the score and reports whether the member is new.
+func (z *sortedSet) add(member string, score float64) bool {
+	old, exists := z.scores[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		z.list.Delete(ScoredMember{Member: member, Score: old})
+	} else {
+		z.bytes += int64(len(member))
+	}
+
+	z.scores[member] = score
+	z.list.Set(ScoredMember{Member: member, Score: score}, struct{}{})
+
+	return !exists
+}
+
+func (z *sortedSet) remove(member string) bool {
+	score, exists := z.scores[member]
+	if !exists {
+		return false
+	}
+
+	delete(z.scores, member)
+	z.list.Delete(ScoredMember{Member: member, Score: score})
+	z.bytes -= int64(len(member))
+
+	return true
+}
+
+// rangeByRank returns members in [start, stop).
+func (z *sortedSet) rangeByRank(start, stop int) []ScoredMember {
+	members := make([]ScoredMember, 0, stop-start)
+	for it := z.list.SeekRank(start); it.Valid() && len(members) < stop-start; it.Next() {
+		members = append(members, it.Key())
+	}
+
+	return members
+}
+
+func (z *sortedSet) rangeByScore(min, max ScoreBound, offset, count int) []ScoredMember {
+	var members []ScoredMember
+	if count == 0 {
+		return members
+	}
+
+	for it := z.list.Seek(ScoredMember{Score: min.Value}); it.Valid(); it.Next() {
+		score := it.Key().Score
+		if min.Exclusive && score == min.Value {
+			continue
+		}
+		if score > max.Value || (max.Exclusive && score == max.Value) {
+			break
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		members = append(members, it.Key())
+		if count > 0 && len(members) == count {
+			break
+		}
+	}
+
+	return members
+}
+
+// SortedSets is implemented by engines which store sorted set values.
+// Operations on a key holding a value of another kind fail with
+// ports.ErrWrongType.
+type SortedSets interface {
+	// ZAdd sets scores of members and returns the number of new members
+	ZAdd(ctx context.Context, key string, members ...ScoredMember) (int, error)
+	// ZRemove returns the number of removed members
+	ZRemove(ctx context.Context, key string, members ...string) (int, error)
+	ZScore(ctx context.Context, key, member string) (float64, bool, error)
+	// ZRank returns the zero-based position of the member in score order
+	ZRank(ctx context.Context, key, member string) (int, bool, error)
+	// ZRange returns members between inclusive ranks, negative ranks count
+	// from the end
+	ZRange(ctx context.Context, key string, start, stop int) ([]ScoredMember, error)
+	// ZRangeByScore returns members with scores between the bounds, skipping
+	// offset of them, a negative count means all
+	ZRangeByScore(ctx context.Context, key string, min, max ScoreBound, offset, count int) ([]ScoredMember, error)
+	// ZIncrBy adds delta to the score of the member and returns the new
+	// score, a missing member is added with delta
+	ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error)
+}
+
+func scoredSize(members []ScoredMember) int64 {
+	var size int64
+	for _, m := range members {
+		size += int64(len(m.Member)) + scoredOverhead
+	}
+
+	return size
+}
+
+func (s *shard) zAdd(key string, members []ScoredMember, now int64) (int, error) {
+	var added int
+	err := s.collection(key, KindSortedSet, scoredSize(members), func() object { return newSortedSet() }, now, func(obj object) error {
+		z := obj.(*sortedSet)
+		for _, m := range members {
+			if z.add(m.Member, m.Score) {
+				added++
+			}
+		}
+		return nil
+	})
+
+	return added, err
+}
+
+func (s *shard) zRemove(key string, members []string, now int64) (int, error) {
+	var removed int
+	err := s.collection(key, KindSortedSet, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		z := obj.(*sortedSet)
+		for _, member := range members {
+			if z.remove(member) {
+				removed++
+			}
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+func (s *shard) zScore(key, member string, now int64) (float64, bool, error) {
+	var score float64
+	var ok bool
+	err := s.collection(key, KindSortedSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			score, ok = obj.(*sortedSet).scores[member]
+		}
+		return nil
+	})
+
+	return score, ok, err
+}
+
+func (s *shard) zRank(key, member string, now int64) (int, bool, error) {
+	var rank int
+	var ok bool
+	err := s.collection(key, KindSortedSet, 0, nil, now, func(obj object) error {
+		if obj == nil {
+			return nil
+		}
+
+		z := obj.(*sortedSet)
+		score, exists := z.scores[member]
+		if exists {
+			rank, ok = z.list.Rank(ScoredMember{Member: member, Score: score})
+		}
+		return nil
+	})
+
+	return rank, ok, err
+}
+
+func (s *shard) zRange(key string, start, stop int, now int64) ([]ScoredMember, error) {
+	var members []ScoredMember
+	err := s.collection(key, KindSortedSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			z := obj.(*sortedSet)
+			members = z.rangeByRank(listBounds(start, stop, z.len()))
+		}
+		return nil
+	})
+
+	return members, err
+}
+
+func (s *shard) zRangeByScore(key string, min, max ScoreBound, offset, count int, now int64) ([]ScoredMember, error) {
+	var members []ScoredMember
+	err := s.collection(key, KindSortedSet, 0, nil, now, func(obj object) error {
+		if obj != nil {
+			members = obj.(*sortedSet).rangeByScore(min, max, offset, count)
+		}
+		return nil
+	})
+
+	return members, err
+}
+
+func (s *shard) zIncrBy(key, member string, delta float64, now int64) (float64, error) {
+	var score float64
+	grow := scoredSize([]ScoredMember{{Member: member}})
+	err := s.collection(key, KindSortedSet, grow, func() object { return newSortedSet() }, now, func(obj object) error {
+		z := obj.(*sortedSet)
+
+		score = z.scores[member] + delta
+		if math.IsNaN(score) {
+			return ports.ErrNotFloat
+		}
+
+		z.add(member, score)
+		return nil
+	})
+
+	return score, err
+}
+
+func (e *Engine) ZAdd(ctx context.Context, key string, members ...ScoredMember) (int, error) {
+	return e.zAdd(key, members, time.Now().UnixNano())
+}
+
+func (e *Engine) ZRemove(ctx context.Context, key string, members ...string) (int, error) {
+	return e.zRemove(key, members, time.Now().UnixNano())
+}
+
+func (e *Engine) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	return e.zScore(key, member, time.Now().UnixNano())
+}
+
+func (e *Engine) ZRank(ctx context.Context, key, member string) (int, bool, error) {
+	return e.zRank(key, member, time.Now().UnixNano())
+}
+
+func (e *Engine) ZRange(ctx context.Context, key string, start, stop int) ([]ScoredMember, error) {
+	return e.zRange(key, start, stop, time.Now().UnixNano())
+}
+
+func (e *Engine) ZRangeByScore(ctx context.Context, key string, min, max ScoreBound, offset, count int) ([]ScoredMember, error) {
+	return e.zRangeByScore(key, min, max, offset, count, time.Now().UnixNano())
+}
+
+func (e *Engine) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	return e.zIncrBy(key, member, delta, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZAdd(ctx context.Context, key string, members ...ScoredMember) (int, error) {
+	target := e.shardFor(key)
+
+	var added int
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		added, err = target.zAdd(key, members, time.Now().UnixNano())
+		return scoredSize(members), err
+	})
+
+	return added, err
+}
+
+func (e *ShardedEngine) ZRemove(ctx context.Context, key string, members ...string) (int, error) {
+	return e.shardFor(key).zRemove(key, members, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	return e.shardFor(key).zScore(key, member, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZRank(ctx context.Context, key, member string) (int, bool, error) {
+	return e.shardFor(key).zRank(key, member, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZRange(ctx context.Context, key string, start, stop int) ([]ScoredMember, error) {
+	return e.shardFor(key).zRange(key, start, stop, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZRangeByScore(ctx context.Context, key string, min, max ScoreBound, offset, count int) ([]ScoredMember, error) {
+	return e.shardFor(key).zRangeByScore(key, min, max, offset, count, time.Now().UnixNano())
+}
+
+func (e *ShardedEngine) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	target := e.shardFor(key)
+
+	var score float64
+	err := e.withEviction(target, func() (int64, error) {
+		var err error
+		score, err = target.zIncrBy(key, member, delta, time.Now().UnixNano())
+		return scoredSize([]ScoredMember{{Member: member}}), err
+	})
+
+	return score, err
+}
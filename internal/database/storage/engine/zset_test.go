@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestEngineSortedSets(t *testing.T) {
+	ctx := context.Background()
+
+	engines := []struct {
+		name  string
+		zsets SortedSets
+	}{
+		{"engine", NewEngine()},
+		{"sharded", NewShardedEngine(4)},
+	}
+
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			added, err := e.zsets.ZAdd(ctx, "board",
+				ScoredMember{"alice", 10}, ScoredMember{"bob", 20}, ScoredMember{"carol", 20}, ScoredMember{"dave", 5})
+			require.NoError(t, err)
+			assert.Equal(t, 4, added)
+
+			added, err = e.zsets.ZAdd(ctx, "board", ScoredMember{"dave", 30})
+			require.NoError(t, err)
+			assert.Zero(t, added)
+
+			members, err := e.zsets.ZRange(ctx, "board", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []ScoredMember{{"alice", 10}, {"bob", 20}, {"carol", 20}, {"dave", 30}}, members)
+
+			rank, ok, err := e.zsets.ZRank(ctx, "board", "carol")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, 2, rank)
+
+			members, err = e.zsets.ZRangeByScore(ctx, "board",
+				ScoreBound{Value: 10, Exclusive: true}, ScoreBound{Value: math.Inf(1)}, 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, []ScoredMember{{"carol", 20}}, members)
+
+			members, err = e.zsets.ZRangeByScore(ctx, "board",
+				ScoreBound{Value: math.Inf(-1)}, ScoreBound{Value: 20, Exclusive: true}, 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []ScoredMember{{"alice", 10}}, members)
+
+			members, err = e.zsets.ZRangeByScore(ctx, "board",
+				ScoreBound{Value: math.Inf(-1)}, ScoreBound{Value: math.Inf(1)}, 0, 0)
+			require.NoError(t, err)
+			assert.Empty(t, members)
+
+			score, err := e.zsets.ZIncrBy(ctx, "board", "alice", 15.5)
+			require.NoError(t, err)
+			assert.Equal(t, 25.5, score)
+
+			rank, _, err = e.zsets.ZRank(ctx, "board", "alice")
+			require.NoError(t, err)
+			assert.Equal(t, 2, rank)
+
+			_, err = e.zsets.ZAdd(ctx, "inf", ScoredMember{"x", math.Inf(1)})
+			require.NoError(t, err)
+			_, err = e.zsets.ZIncrBy(ctx, "inf", "x", math.Inf(-1))
+			assert.ErrorIs(t, err, ports.ErrNotFloat)
+
+			removed, err := e.zsets.ZRemove(ctx, "board", "bob", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, 1, removed)
+
+			_, ok, err = e.zsets.ZScore(ctx, "board", "bob")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestDumpAndRestoreSets(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine()
+
+	_, err := engine.SetAdd(ctx, "tags", "go", "db")
+	require.NoError(t, err)
+	_, err = engine.ZAdd(ctx, "board", ScoredMember{"alice", 0.1}, ScoredMember{"bob", math.Inf(-1)})
+	require.NoError(t, err)
+
+	entries, err := engine.Dump(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Entry{
+		{Key: "tags", Kind: KindSet, Items: []string{"db", "go"}},
+		{Key: "board", Kind: KindSortedSet, Items: []string{"bob", "-Inf", "alice", "0.1"}},
+	}, entries)
+
+	restored := NewEngine()
+	for _, entry := range entries {
+		require.NoError(t, restored.Restore(ctx, entry))
+	}
+	assert.Equal(t, engine.UsedMemory(), restored.UsedMemory())
+
+	members, err := restored.ZRange(ctx, "board", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []ScoredMember{{"bob", math.Inf(-1)}, {"alice", 0.1}}, members)
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+var (
+	errInvalidLogger   = errors.New("invalid logger")
+	errUnknownWALOp    = errors.New("unknown wal operation")
+	errInvalidWALEntry = errors.New("invalid wal entry")
+
+	errSnapshotsDisabled     = ports.NewClientError(ports.CodeNotSupported, "snapshots are disabled")
+	errSnapshotsNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support snapshots")
+	errSaveInProgress        = ports.NewClientError(ports.CodeBusy, "snapshot saving is already in progress")
+
+	errExpirationNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support key expiration")
+
+	errRangeNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support range scans")
+	errScanNotSupported  = ports.NewClientError(ports.CodeNotSupported, "engine does not support scans")
+	errInvalidCursor     = ports.NewClientError(ports.CodeInvalidArgument, "invalid cursor")
+
+	errReadTxNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support read transactions")
+	errUpdateNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support atomic updates")
+	errListsNotSupported  = ports.NewClientError(ports.CodeNotSupported, "engine does not support lists")
+	errHashesNotSupported = ports.NewClientError(ports.CodeNotSupported, "engine does not support hashes")
+	errSetsNotSupported   = ports.NewClientError(ports.CodeNotSupported, "engine does not support sets")
+	errZSetsNotSupported  = ports.NewClientError(ports.CodeNotSupported, "engine does not support sorted sets")
+)
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ports.Code
+	}{
+		{name: "snapshots disabled", err: errSnapshotsDisabled, expected: ports.CodeNotSupported},
+		{name: "snapshots not supported", err: errSnapshotsNotSupported, expected: ports.CodeNotSupported},
+		{name: "save in progress", err: errSaveInProgress, expected: ports.CodeBusy},
+		{name: "expiration not supported", err: errExpirationNotSupported, expected: ports.CodeNotSupported},
+		{name: "range not supported", err: errRangeNotSupported, expected: ports.CodeNotSupported},
+		{name: "scan not supported", err: errScanNotSupported, expected: ports.CodeNotSupported},
+		{name: "invalid cursor", err: errInvalidCursor, expected: ports.CodeInvalidArgument},
+		{name: "read transactions not supported", err: errReadTxNotSupported, expected: ports.CodeNotSupported},
+		{name: "updates not supported", err: errUpdateNotSupported, expected: ports.CodeNotSupported},
+		{name: "lists not supported", err: errListsNotSupported, expected: ports.CodeNotSupported},
+		{name: "hashes not supported", err: errHashesNotSupported, expected: ports.CodeNotSupported},
+		{name: "sets not supported", err: errSetsNotSupported, expected: ports.CodeNotSupported},
+		{name: "sorted sets not supported", err: errZSetsNotSupported, expected: ports.CodeNotSupported},
+		{name: "wal entry is internal", err: errInvalidWALEntry, expected: ports.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ports.CodeOf(tt.err))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+type expiringEngine interface {
+	SetWithDeadline(ctx context.Context, key, value string, deadline time.Time) error
+	ExpireAt(ctx context.Context, key string, deadline time.Time) (bool, error)
+	Deadline(ctx context.Context, key string) (time.Time, bool, error)
+	Persist(ctx context.Context, key string) (bool, error)
+	DeleteExpired(ctx context.Context) int
+}
+
+const expirationInterval = 100 * time.Millisecond
+
+// SetWithTTL stores the value which expires after ttl. The WAL keeps the
+// absolute deadline so the replay does not prolong the key life.
+func (s Storage) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SetWithTTL"),
+		slog.String("key", key),
+		slog.Duration("ttl", ttl),
+	}
+
+	engine, ok := s.engine.(expiringEngine)
+	if !ok {
+		s.logger.ErrorContext(ctx, errExpirationNotSupported.Error(), logAttrs...)
+		return errExpirationNotSupported
+	}
+
+	deadline := time.Now().Add(ttl)
+	args := []string{key, value, formatDeadline(deadline)}
+
+	err := s.write(ctx, wal.OpSet, args, func() error {
+		return engine.SetWithDeadline(ctx, key, value, deadline)
+	})
+	if err != nil {
+		wErr := fmt.Errorf("set with ttl to engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	return nil
+}
+
+// Expire sets the key time to live, a non-positive ttl deletes the key. It
+// reports whether the key exists.
+func (s Storage) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Expire"),
+		slog.String("key", key),
+		slog.Duration("ttl", ttl),
+	}
+
+	engine, ok := s.engine.(expiringEngine)
+	if !ok {
+		s.logger.ErrorContext(ctx, errExpirationNotSupported.Error(), logAttrs...)
+		return false, errExpirationNotSupported
+	}
+
+	deadline := time.Now().Add(ttl)
+
+	var exists bool
+	err := s.write(ctx, wal.OpExpire, []string{key, formatDeadline(deadline)}, func() error {
+		var err error
+		exists, err = engine.ExpireAt(ctx, key, deadline)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("expire in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return false, wErr
+	}
+
+	return exists, nil
+}
+
+// TTL returns the remaining time to live of the key. A negative duration
+// means that the key has no expiration.
+func (s Storage) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "TTL"),
+		slog.String("key", key),
+	}
+
+	engine, ok := s.engine.(expiringEngine)
+	if !ok {
+		s.logger.ErrorContext(ctx, errExpirationNotSupported.Error(), logAttrs...)
+		return 0, false, errExpirationNotSupported
+	}
+
+	deadline, exists, err := engine.Deadline(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("get deadline from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, false, wErr
+	}
+
+	if !exists {
+		return 0, false, nil
+	}
+
+	if deadline.IsZero() {
+		return -1, true, nil
+	}
+
+	ttl := time.Until(deadline)
+	if ttl <= 0 {
+		return 0, false, nil
+	}
+
+	return ttl, true, nil
+}
+
+// Persist removes the key expiration, it reports whether the key had one.
+func (s Storage) Persist(ctx context.Context, key string) (bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Persist"),
+		slog.String("key", key),
+	}
+
+	engine, ok := s.engine.(expiringEngine)
+	if !ok {
+		s.logger.ErrorContext(ctx, errExpirationNotSupported.Error(), logAttrs...)
+		return false, errExpirationNotSupported
+	}
+
+	var persisted bool
+	err := s.write(ctx, wal.OpPersist, []string{key}, func() error {
+		var err error
+		persisted, err = engine.Persist(ctx, key)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("persist in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return false, wErr
+	}
+
+	return persisted, nil
+}
+
+// RunExpiration periodically removes expired keys which are never accessed
+// again, until ctx is canceled.
+func (s Storage) RunExpiration(ctx context.Context) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "RunExpiration"),
+	}
+
+	engine, ok := s.engine.(expiringEngine)
+	if !ok {
+		s.logger.WarnContext(ctx, errExpirationNotSupported.Error(), logAttrs...)
+		return
+	}
+
+	ticker := time.NewTicker(expirationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted := engine.DeleteExpired(ctx)
+			if deleted > 0 {
+				s.logger.DebugContext(ctx, fmt.Sprintf("%d expired keys deleted", deleted), logAttrs...)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func formatDeadline(deadline time.Time) string {
+	return strconv.FormatInt(deadline.UnixNano(), 10)
+}
+
+func parseDeadline(str string) (time.Time, error) {
+	nanos, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, nanos), nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+func TestExpirationCommands(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, exists, err := st.TTL(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, st.Set(ctx, "key", "value"))
+
+	ttl, exists, err := st.TTL(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Negative(t, ttl)
+
+	exists, err = st.Expire(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	ttl, _, err = st.TTL(ctx, "key")
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	persisted, err := st.Persist(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, persisted)
+
+	exists, err = st.Expire(ctx, "key", 0)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	value, _, err := st.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Empty(t, value)
+}
+
+func TestExpirationIsReplayedWithAbsoluteDeadline(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	require.NoError(t, st.SetWithTTL(ctx, "short", "value", 50*time.Millisecond))
+	require.NoError(t, st.SetWithTTL(ctx, "long", "value", time.Hour))
+	require.NoError(t, st.Set(ctx, "persisted", "value"))
+	_, err = st.Expire(ctx, "persisted", time.Hour)
+	require.NoError(t, err)
+	_, err = st.Persist(ctx, "persisted")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	time.Sleep(100 * time.Millisecond)
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err = NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Recover(ctx))
+
+	_, exists, err := st.TTL(ctx, "short")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	ttl, exists, err := st.TTL(ctx, "long")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Greater(t, ttl, 59*time.Minute)
+
+	ttl, exists, err = st.TTL(ctx, "persisted")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Negative(t, ttl)
+}
+
+func TestExpirationNotSupported(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewStorage(mocks.NewEngineLayer(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, st.SetWithTTL(ctx, "key", "value", time.Second), errExpirationNotSupported)
+
+	_, err = st.Expire(ctx, "key", time.Second)
+	assert.ErrorIs(t, err, errExpirationNotSupported)
+}
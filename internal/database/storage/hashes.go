@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+// HSet stores field value pairs in the hash at the key and returns the
+// number of new fields.
+func (s Storage) HSet(ctx context.Context, key string, pairs ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HSet"),
+		slog.String("key", key),
+		slog.Int("fields", len(pairs)/2),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return 0, errHashesNotSupported
+	}
+
+	var added int
+	err := s.write(ctx, wal.OpHSet, append([]string{key}, pairs...), func() error {
+		var err error
+		added, err = hashes.HashSet(ctx, key, pairs...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("hash set to engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return added, nil
+}
+
+// HGet returns values of the fields and whether each field exists.
+func (s Storage) HGet(ctx context.Context, key string, fields ...string) ([]string, []bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HGet"),
+		slog.String("key", key),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return nil, nil, errHashesNotSupported
+	}
+
+	values, exists, err := hashes.HashGet(ctx, key, fields...)
+	if err != nil {
+		wErr := fmt.Errorf("hash get from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, nil, wErr
+	}
+
+	return values, exists, nil
+}
+
+func (s Storage) HExists(ctx context.Context, key, field string) (bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HExists"),
+		slog.String("key", key),
+		slog.String("field", field),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return false, errHashesNotSupported
+	}
+
+	exists, err := hashes.HashExists(ctx, key, field)
+	if err != nil {
+		wErr := fmt.Errorf("hash exists in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return false, wErr
+	}
+
+	return exists, nil
+}
+
+// HGetAll returns field value pairs ordered by field.
+func (s Storage) HGetAll(ctx context.Context, key string) ([]string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HGetAll"),
+		slog.String("key", key),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return nil, errHashesNotSupported
+	}
+
+	pairs, err := hashes.HashGetAll(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("hash get all from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return pairs, nil
+}
+
+// HDel removes the fields and returns the number of removed ones.
+func (s Storage) HDel(ctx context.Context, key string, fields ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HDel"),
+		slog.String("key", key),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return 0, errHashesNotSupported
+	}
+
+	var removed int
+	err := s.write(ctx, wal.OpHDel, append([]string{key}, fields...), func() error {
+		var err error
+		removed, err = hashes.HashDel(ctx, key, fields...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("hash delete from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return removed, nil
+}
+
+func (s Storage) HLen(ctx context.Context, key string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HLen"),
+		slog.String("key", key),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return 0, errHashesNotSupported
+	}
+
+	length, err := hashes.HashLen(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("hash length in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return length, nil
+}
+
+// HIncrBy adds delta to the integer stored in the field, like IncrBy the
+// WAL keeps the increment.
+func (s Storage) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "HIncrBy"),
+		slog.String("key", key),
+		slog.String("field", field),
+		slog.Int64("delta", delta),
+	}
+
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		s.logger.ErrorContext(ctx, errHashesNotSupported.Error(), logAttrs...)
+		return 0, errHashesNotSupported
+	}
+
+	var result int64
+	err := s.write(ctx, wal.OpHIncrBy, []string{key, field, strconv.FormatInt(delta, 10)}, func() error {
+		_, err := hashes.HashUpdate(ctx, key, field, incrementInteger(delta, &result))
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("hash incr in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return result, nil
+}
+
+func (s Storage) applyHashRecord(ctx context.Context, record wal.Record) error {
+	hashes, ok := s.engine.(engine.Hashes)
+	if !ok {
+		return errHashesNotSupported
+	}
+
+	if len(record.Args) == 0 {
+		return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+	}
+	key := record.Args[0]
+
+	switch record.Op {
+	case wal.OpHSet:
+		_, err := hashes.HashSet(ctx, key, record.Args[1:]...)
+		return err
+	case wal.OpHDel:
+		_, err := hashes.HashDel(ctx, key, record.Args[1:]...)
+		return err
+	default:
+		if len(record.Args) != 3 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		delta, err := strconv.ParseInt(record.Args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		var result int64
+		_, err = hashes.HashUpdate(ctx, key, record.Args[1], incrementInteger(delta, &result))
+		return err
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestHashes(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	added, err := storage.HSet(ctx, "user", "name", "alice", "visits", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	visits, err := storage.HIncrBy(ctx, "user", "visits", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), visits)
+
+	_, err = storage.HIncrBy(ctx, "user", "name", 1)
+	assert.ErrorIs(t, err, ports.ErrNotInteger)
+
+	values, found, err := storage.HGet(ctx, "user", "name", "visits", "email")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "11", ""}, values)
+	assert.Equal(t, []bool{true, true, false}, found)
+
+	exists, err := storage.HExists(ctx, "user", "email")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	removed, err := storage.HDel(ctx, "user", "name", "email")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	length, err := storage.HLen(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, length)
+
+	_, err = storage.IncrBy(ctx, "user", 1)
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+}
+
+func TestHashesNotSupported(t *testing.T) {
+	storage, err := NewStorage(mocks.NewEngineLayer(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, err = storage.HGetAll(context.Background(), "user")
+	assert.ErrorIs(t, err, errHashesNotSupported)
+}
+
+func TestRecoverReplaysHashes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	_, err = st.HSet(ctx, "user", "name", "alice", "age", "30", "city", "paris")
+	require.NoError(t, err)
+	_, err = st.HDel(ctx, "user", "city")
+	require.NoError(t, err)
+	_, err = st.HIncrBy(ctx, "user", "age", 2)
+	require.NoError(t, err)
+	_, err = st.HIncrBy(ctx, "user", "name", 2)
+	require.ErrorIs(t, err, ports.ErrNotInteger)
+	require.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err = NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Recover(ctx))
+
+	pairs, err := st.HGetAll(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age", "32", "name", "alice"}, pairs)
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+// Push adds values to the side of the list at the key and returns the new
+// length of the list.
+func (s Storage) Push(ctx context.Context, key string, side engine.Side, values ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Push"),
+		slog.String("key", key),
+		slog.Int("values", len(values)),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return 0, errListsNotSupported
+	}
+
+	op := wal.OpRPush
+	if side == engine.Left {
+		op = wal.OpLPush
+	}
+
+	var length int
+	err := s.write(ctx, op, append([]string{key}, values...), func() error {
+		var err error
+		length, err = lists.Push(ctx, key, side, values...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("push to engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return length, nil
+}
+
+// Pop removes an element from the side of the list, ok is false when the
+// list is empty.
+func (s Storage) Pop(ctx context.Context, key string, side engine.Side) (string, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Pop"),
+		slog.String("key", key),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return "", false, errListsNotSupported
+	}
+
+	op := wal.OpRPop
+	if side == engine.Left {
+		op = wal.OpLPop
+	}
+
+	var value string
+	var popped bool
+	err := s.write(ctx, op, []string{key}, func() error {
+		var err error
+		value, popped, err = lists.Pop(ctx, key, side)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("pop from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return "", false, wErr
+	}
+
+	return value, popped, nil
+}
+
+func (s Storage) ListRange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ListRange"),
+		slog.String("key", key),
+		slog.Int("start", start),
+		slog.Int("stop", stop),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return nil, errListsNotSupported
+	}
+
+	values, err := lists.ListRange(ctx, key, start, stop)
+	if err != nil {
+		wErr := fmt.Errorf("list range in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return values, nil
+}
+
+func (s Storage) ListLen(ctx context.Context, key string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ListLen"),
+		slog.String("key", key),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return 0, errListsNotSupported
+	}
+
+	length, err := lists.ListLen(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("list length in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return length, nil
+}
+
+func (s Storage) ListIndex(ctx context.Context, key string, index int) (string, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ListIndex"),
+		slog.String("key", key),
+		slog.Int("index", index),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return "", false, errListsNotSupported
+	}
+
+	value, found, err := lists.ListIndex(ctx, key, index)
+	if err != nil {
+		wErr := fmt.Errorf("list index in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return "", false, wErr
+	}
+
+	return value, found, nil
+}
+
+// ListTrim keeps only the elements between inclusive indexes.
+func (s Storage) ListTrim(ctx context.Context, key string, start, stop int) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ListTrim"),
+		slog.String("key", key),
+		slog.Int("start", start),
+		slog.Int("stop", stop),
+	}
+
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		s.logger.ErrorContext(ctx, errListsNotSupported.Error(), logAttrs...)
+		return errListsNotSupported
+	}
+
+	args := []string{key, strconv.Itoa(start), strconv.Itoa(stop)}
+	err := s.write(ctx, wal.OpLTrim, args, func() error {
+		return lists.ListTrim(ctx, key, start, stop)
+	})
+	if err != nil {
+		wErr := fmt.Errorf("list trim in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	return nil
+}
+
+func (s Storage) applyListRecord(ctx context.Context, record wal.Record) error {
+	lists, ok := s.engine.(engine.Lists)
+	if !ok {
+		return errListsNotSupported
+	}
+
+	if len(record.Args) == 0 {
+		return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+	}
+	key := record.Args[0]
+
+	switch record.Op {
+	case wal.OpLPush:
+		_, err := lists.Push(ctx, key, engine.Left, record.Args[1:]...)
+		return err
+	case wal.OpRPush:
+		_, err := lists.Push(ctx, key, engine.Right, record.Args[1:]...)
+		return err
+	case wal.OpLPop:
+		_, _, err := lists.Pop(ctx, key, engine.Left)
+		return err
+	case wal.OpRPop:
+		_, _, err := lists.Pop(ctx, key, engine.Right)
+		return err
+	default:
+		if len(record.Args) != 3 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		start, err := strconv.Atoi(record.Args[1])
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+		stop, err := strconv.Atoi(record.Args[2])
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		return lists.ListTrim(ctx, key, start, stop)
+	}
+}
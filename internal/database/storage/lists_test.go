@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestLists(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	length, err := storage.Push(ctx, "list", engine.Right, "b", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, length)
+
+	length, err = storage.Push(ctx, "list", engine.Left, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, length)
+
+	values, err := storage.ListRange(ctx, "list", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, values)
+
+	value, ok, err := storage.ListIndex(ctx, "list", 1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", value)
+
+	assert.NoError(t, storage.ListTrim(ctx, "list", 0, 1))
+
+	value, ok, err = storage.Pop(ctx, "list", engine.Right)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", value)
+
+	length, err = storage.ListLen(ctx, "list")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, length)
+
+	_, _, err = storage.Get(ctx, "list")
+	assert.ErrorIs(t, err, ports.ErrWrongType)
+}
+
+func TestListsNotSupported(t *testing.T) {
+	storage, err := NewStorage(mocks.NewEngineLayer(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, err = storage.Push(context.Background(), "list", engine.Left, "a")
+	assert.ErrorIs(t, err, errListsNotSupported)
+}
+
+func TestRecoverReplaysLists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	_, err = st.Push(ctx, "list", engine.Right, "a", "b", "c", "d")
+	require.NoError(t, err)
+	_, err = st.Push(ctx, "list", engine.Left, "z")
+	require.NoError(t, err)
+	_, _, err = st.Pop(ctx, "list", engine.Left)
+	require.NoError(t, err)
+	_, _, err = st.Pop(ctx, "list", engine.Right)
+	require.NoError(t, err)
+	require.NoError(t, st.ListTrim(ctx, "list", 1, -1))
+	require.NoError(t, st.Set(ctx, "text", "abc"))
+	_, err = st.Push(ctx, "text", engine.Left, "a")
+	require.ErrorIs(t, err, ports.ErrWrongType)
+	require.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err = NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Recover(ctx))
+
+	values, err := st.ListRange(ctx, "list", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, values)
+}
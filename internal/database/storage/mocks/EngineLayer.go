@@ -1,4 +1,4 @@
-// Code generated by mockery v2.53.0. DO NOT EDIT.
+// Code generated by mockery v2.53.3. DO NOT EDIT.
 
 package mocks
 
@@ -22,21 +22,31 @@ func (_m *EngineLayer) EXPECT() *EngineLayer_Expecter {
 }
 
 // Del provides a mock function with given fields: ctx, key
-func (_m *EngineLayer) Del(ctx context.Context, key string) error {
+func (_m *EngineLayer) Del(ctx context.Context, key string) (bool, error) {
 	ret := _m.Called(ctx, key)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Del")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
 		r0 = rf(ctx, key)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // EngineLayer_Del_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Del'
@@ -58,18 +68,18 @@ func (_c *EngineLayer_Del_Call) Run(run func(ctx context.Context, key string)) *
 	return _c
 }
 
-func (_c *EngineLayer_Del_Call) Return(_a0 error) *EngineLayer_Del_Call {
-	_c.Call.Return(_a0)
+func (_c *EngineLayer_Del_Call) Return(_a0 bool, _a1 error) *EngineLayer_Del_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *EngineLayer_Del_Call) RunAndReturn(run func(context.Context, string) error) *EngineLayer_Del_Call {
+func (_c *EngineLayer_Del_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *EngineLayer_Del_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Get provides a mock function with given fields: ctx, key
-func (_m *EngineLayer) Get(ctx context.Context, key string) (string, error) {
+func (_m *EngineLayer) Get(ctx context.Context, key string) (string, bool, error) {
 	ret := _m.Called(ctx, key)
 
 	if len(ret) == 0 {
@@ -77,8 +87,9 @@ func (_m *EngineLayer) Get(ctx context.Context, key string) (string, error) {
 	}
 
 	var r0 string
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, bool, error)); ok {
 		return rf(ctx, key)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
@@ -87,13 +98,19 @@ func (_m *EngineLayer) Get(ctx context.Context, key string) (string, error) {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string) bool); ok {
 		r1 = rf(ctx, key)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(bool)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // EngineLayer_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
@@ -115,12 +132,12 @@ func (_c *EngineLayer_Get_Call) Run(run func(ctx context.Context, key string)) *
 	return _c
 }
 
-func (_c *EngineLayer_Get_Call) Return(_a0 string, _a1 error) *EngineLayer_Get_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *EngineLayer_Get_Call) Return(_a0 string, _a1 bool, _a2 error) *EngineLayer_Get_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *EngineLayer_Get_Call) RunAndReturn(run func(context.Context, string) (string, error)) *EngineLayer_Get_Call {
+func (_c *EngineLayer_Get_Call) RunAndReturn(run func(context.Context, string) (string, bool, error)) *EngineLayer_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
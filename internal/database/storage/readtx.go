@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+// ReadTx reads a consistent snapshot of the keyspace, it is not safe for
+// concurrent use.
+type ReadTx struct {
+	tx     engine.ReadTx
+	logger *slog.Logger
+}
+
+// BeginRead opens a read-only transaction, only versioned engines support it.
+func (s Storage) BeginRead(ctx context.Context) (*ReadTx, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "BeginRead"),
+	}
+
+	versioned, ok := s.engine.(engine.Versioned)
+	if !ok {
+		s.logger.ErrorContext(ctx, errReadTxNotSupported.Error(), logAttrs...)
+		return nil, errReadTxNotSupported
+	}
+
+	tx, err := versioned.BeginRead(ctx)
+	if err != nil {
+		wErr := fmt.Errorf("begin read in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return &ReadTx{tx: tx, logger: s.logger}, nil
+}
+
+// Get reports whether the key exists in the snapshot.
+func (t *ReadTx) Get(ctx context.Context, key string) (string, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ReadTx.Get"),
+		slog.String("key", key),
+	}
+
+	value, ok, err := t.tx.Get(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("get from read transaction: %w", err)
+		t.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return "", false, wErr
+	}
+
+	return value, ok, nil
+}
+
+func (t *ReadTx) Range(ctx context.Context, start, end string, limit int) (engine.Iterator, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ReadTx.Range"),
+		slog.String("start", start),
+		slog.String("end", end),
+	}
+
+	it, err := limitedRange(ctx, t.tx, start, end, limit)
+	if err != nil {
+		wErr := fmt.Errorf("range in read transaction: %w", err)
+		t.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return it, nil
+}
+
+func (t *ReadTx) Prefix(ctx context.Context, prefix string, limit int) (engine.Iterator, error) {
+	return t.Range(ctx, prefix, prefixEnd(prefix), limit)
+}
+
+func (t *ReadTx) Close() error {
+	return t.tx.Close()
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine/mvcc"
+)
+
+func TestReadTx(t *testing.T) {
+	ctx := context.Background()
+
+	e := mvcc.NewMVCC()
+	defer e.Close()
+
+	storage, err := NewStorage(e, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	for _, key := range []string{"user:1", "user:2", "video:1"} {
+		require.NoError(t, storage.Set(ctx, key, "v-"+key))
+	}
+
+	tx, err := storage.BeginRead(ctx)
+	require.NoError(t, err)
+	defer tx.Close()
+
+	require.NoError(t, storage.Set(ctx, "user:1", "new"))
+	require.NoError(t, storage.Set(ctx, "user:3", "new"))
+
+	value, _, err := tx.Get(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "v-user:1", value)
+
+	it, err := tx.Prefix(ctx, "user:", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:2"}, collectKeys(t, it))
+
+	it, err = tx.Range(ctx, "", "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, collectKeys(t, it))
+}
+
+func TestReadTxNotSupported(t *testing.T) {
+	storage, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, err = storage.BeginRead(context.Background())
+	assert.ErrorIs(t, err, errReadTxNotSupported)
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Recover restores the engine state from the latest snapshot and the WAL
+// records written after it. It has to be called before the storage starts
+// serving requests.
+func (s Storage) Recover(ctx context.Context) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Recover"),
+	}
+
+	lsn, err := s.loadSnapshot(ctx)
+	if err != nil {
+		wErr := fmt.Errorf("load snapshot: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	if s.wal == nil {
+		return nil
+	}
+
+	// records kept by the engine itself are not replayed, increments
+	// would be applied twice
+	if durable, ok := s.engine.(durableEngine); ok {
+		lsn = max(lsn, durable.DurableLSN())
+	}
+
+	s.wal.AdvanceLSN(lsn)
+
+	replayed := 0
+	err = s.wal.Replay(ctx, lsn, func(record wal.Record) error {
+		replayed++
+
+		err := s.applyRecord(ctx, record)
+		s.applied(record.LSN)
+		if isRejected(err) {
+			// the write failed on the same data when it happened as well
+			s.logger.WarnContext(ctx, fmt.Errorf("skip wal record %d: %w", record.LSN, err).Error(), logAttrs...)
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("replay wal: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	s.logger.InfoContext(ctx, fmt.Sprintf("replayed %d wal records", replayed), logAttrs...)
+
+	return nil
+}
+
+// durableEngine is implemented by engines which keep their data on disk
+// themselves. They are told the LSN of every applied WAL record and report
+// the last one which survives a restart.
+type durableEngine interface {
+	Applied(lsn uint64)
+	DurableLSN() uint64
+}
+
+func (s Storage) applied(lsn uint64) {
+	if durable, ok := s.engine.(durableEngine); ok {
+		durable.Applied(lsn)
+	}
+}
+
+// isRejected reports whether the record failed the same way when it was
+// written. Other errors like a missing feature of the engine or a lower
+// memory limit would drop data, so they fail the recovery.
+func isRejected(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch ports.CodeOf(err) {
+	case ports.CodeWrongType, ports.CodeNotInteger, ports.CodeNotFloat, ports.CodeOverflow:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s Storage) applyRecord(ctx context.Context, record wal.Record) error {
+	switch record.Op {
+	case wal.OpSet:
+		switch len(record.Args) {
+		case 2:
+			return s.engine.Set(ctx, record.Args[0], record.Args[1])
+		case 3:
+			engine, ok := s.engine.(expiringEngine)
+			if !ok {
+				return errExpirationNotSupported
+			}
+
+			deadline, err := parseDeadline(record.Args[2])
+			if err != nil {
+				return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+			}
+			return engine.SetWithDeadline(ctx, record.Args[0], record.Args[1], deadline)
+		default:
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+	case wal.OpDel:
+		if len(record.Args) == 0 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+		for _, key := range record.Args {
+			if _, err := s.engine.Del(ctx, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case wal.OpExpire:
+		engine, ok := s.engine.(expiringEngine)
+		if !ok {
+			return errExpirationNotSupported
+		}
+
+		if len(record.Args) != 2 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		deadline, err := parseDeadline(record.Args[1])
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		_, err = engine.ExpireAt(ctx, record.Args[0], deadline)
+		return err
+	case wal.OpPersist:
+		engine, ok := s.engine.(expiringEngine)
+		if !ok {
+			return errExpirationNotSupported
+		}
+
+		if len(record.Args) != 1 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		_, err := engine.Persist(ctx, record.Args[0])
+		return err
+	case wal.OpIncrBy:
+		updater, ok := s.engine.(engine.Updater)
+		if !ok {
+			return errUpdateNotSupported
+		}
+
+		if len(record.Args) != 2 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		delta, err := strconv.ParseInt(record.Args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		_, err = incrBy(ctx, updater, record.Args[0], delta)
+		return err
+	case wal.OpIncrByFloat:
+		updater, ok := s.engine.(engine.Updater)
+		if !ok {
+			return errUpdateNotSupported
+		}
+
+		if len(record.Args) != 2 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		delta, err := strconv.ParseFloat(record.Args[1], 64)
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		_, err = incrByFloat(ctx, updater, record.Args[0], delta)
+		return err
+	case wal.OpLPush, wal.OpRPush, wal.OpLPop, wal.OpRPop, wal.OpLTrim:
+		return s.applyListRecord(ctx, record)
+	case wal.OpHSet, wal.OpHDel, wal.OpHIncrBy:
+		return s.applyHashRecord(ctx, record)
+	case wal.OpSAdd, wal.OpSRem:
+		return s.applySetRecord(ctx, record)
+	case wal.OpZAdd, wal.OpZRem, wal.OpZIncrBy:
+		return s.applyZSetRecord(ctx, record)
+	default:
+		return fmt.Errorf("%w: %d", errUnknownWALOp, record.Op)
+	}
+}
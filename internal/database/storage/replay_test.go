@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestRecoverReplaysWAL(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	engine := mocks.NewEngineLayer(t)
+	engine.EXPECT().Set(ctx, "key", "value").Return(nil).Once()
+	engine.EXPECT().Del(ctx, "key").Return(true, nil).Once()
+
+	st, err := NewStorage(engine, logger, WithWAL(w))
+	require.NoError(t, err)
+
+	assert.NoError(t, st.Set(ctx, "key", "value"))
+	removed, err := st.Del(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	restored := mocks.NewEngineLayer(t)
+	setCall := restored.EXPECT().Set(ctx, "key", "value").Return(nil).Once()
+	restored.EXPECT().Del(ctx, "key").Return(true, nil).Once().NotBefore(setCall)
+
+	st, err = NewStorage(restored, logger, WithWAL(w))
+	require.NoError(t, err)
+
+	assert.NoError(t, st.Recover(ctx))
+}
+
+func TestRecoverWithoutWAL(t *testing.T) {
+	engine := mocks.NewEngineLayer(t)
+	st, err := NewStorage(engine, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	assert.NoError(t, st.Recover(context.Background()))
+}
+
+func TestRecoverReplaysEvictions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	open := func() (*engine.Engine, *Storage, *wal.WAL) {
+		w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+		require.NoError(t, err)
+
+		e := engine.NewEngine(engine.WithMaxMemory(500, engine.AllKeysRandom))
+		st, err := NewStorage(e, logger, WithWAL(w))
+		require.NoError(t, err)
+		require.NoError(t, st.Recover(ctx))
+
+		return e, st, w
+	}
+
+	e, st, w := open()
+	for i := range 20 {
+		require.NoError(t, st.Set(ctx, fmt.Sprintf("key%02d", i), "value"))
+	}
+
+	entries, err := e.Dump(ctx)
+	require.NoError(t, err)
+	assert.Less(t, len(entries), 20)
+	assert.NoError(t, w.Close())
+
+	e, _, w = open()
+	defer w.Close()
+
+	restored, err := e.Dump(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, entries, restored)
+}
+
+func TestRecoverFailsOnUnsupportedRecords(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Set(ctx, "key", "value"))
+	_, err = st.Push(ctx, "list", engine.Left, "a")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	// the engine has no lists, skipping the record would lose it
+	restored := mocks.NewEngineLayer(t)
+	restored.EXPECT().Set(ctx, "key", "value").Return(nil).Once()
+
+	st, err = NewStorage(restored, logger, WithWAL(w))
+	require.NoError(t, err)
+	assert.ErrorIs(t, st.Recover(ctx), errListsNotSupported)
+}
+
+func TestOutOfMemoryIsNotLogged(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err := NewStorage(engine.NewEngine(engine.WithMaxMemory(100, engine.NoEviction)), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	require.NoError(t, st.Set(ctx, "a", "1"))
+	assert.ErrorIs(t, st.Set(ctx, "b", "1"), ports.ErrOutOfMemory)
+	assert.Equal(t, uint64(1), w.LastLSN())
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/utils"
+)
+
+// scanEndCursor starts and ends a SCAN walk.
+const scanEndCursor = "0"
+
+// keysBatchSize is the number of keys KEYS asks the engine for at once.
+const keysBatchSize = 1000
+
+// Scan returns about count keys matching the glob pattern, an empty pattern
+// matches all keys, and the cursor of the next call. The walk starts and
+// ends with the cursor "0". Keys present during the whole walk are returned
+// at least once.
+//
+// Hash engines walk their buckets and the cursor is the bucket number,
+// ordered engines walk keys in order and the cursor is the hex encoded key
+// to continue from.
+func (s Storage) Scan(ctx context.Context, cursor, pattern string, count int) ([]string, string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Scan"),
+		slog.String("cursor", cursor),
+	}
+
+	var keys []string
+	var next string
+	var err error
+
+	switch e := s.engine.(type) {
+	case engine.Scanner:
+		keys, next, err = scanHashed(ctx, e, cursor, count)
+	case engine.Ordered:
+		keys, next, err = scanOrdered(ctx, e, cursor, count)
+	default:
+		err = errScanNotSupported
+	}
+
+	if err != nil {
+		wErr := fmt.Errorf("scan in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, "", wErr
+	}
+
+	if pattern != "" {
+		matched := keys[:0]
+		for _, key := range keys {
+			if utils.MatchGlob(pattern, key) {
+				matched = append(matched, key)
+			}
+		}
+		keys = matched
+	}
+
+	return keys, next, nil
+}
+
+// Keys returns all keys matching the glob pattern. It walks the whole
+// keyspace, so it is meant for small databases.
+func (s Storage) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+
+	cursor := scanEndCursor
+	for {
+		batch, next, err := s.Scan(ctx, cursor, pattern, keysBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, batch...)
+		if next == scanEndCursor {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+func scanHashed(ctx context.Context, scanner engine.Scanner, cursor string, count int) ([]string, string, error) {
+	position, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return nil, "", errInvalidCursor
+	}
+
+	keys, next, err := scanner.Scan(ctx, position, count)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return keys, strconv.FormatUint(next, 10), nil
+}
+
+func scanOrdered(ctx context.Context, ordered engine.Ordered, cursor string, count int) ([]string, string, error) {
+	start := ""
+	if cursor != scanEndCursor {
+		decoded, err := hex.DecodeString(cursor)
+		if err != nil || len(decoded) == 0 {
+			return nil, "", errInvalidCursor
+		}
+		start = string(decoded)
+	}
+
+	it, err := ordered.Range(ctx, start, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer it.Close()
+
+	var keys []string
+	for len(keys) < count && it.Next() {
+		keys = append(keys, it.Key())
+	}
+	if err := it.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(keys) < count || !it.Next() {
+		return keys, scanEndCursor, it.Err()
+	}
+
+	// the next key is the first one not returned yet
+	return keys, hex.EncodeToString([]byte(it.Key())), nil
+}
+
+// Range iterates over keys in [start, end) in order, an empty end means no
+// upper bound and a non-positive limit means no limit. Only ordered engines
+// support it.
+func (s Storage) Range(ctx context.Context, start, end string, limit int) (engine.Iterator, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Range"),
+		slog.String("start", start),
+		slog.String("end", end),
+	}
+
+	ordered, ok := s.engine.(engine.Ordered)
+	if !ok {
+		s.logger.ErrorContext(ctx, errRangeNotSupported.Error(), logAttrs...)
+		return nil, errRangeNotSupported
+	}
+
+	it, err := limitedRange(ctx, ordered, start, end, limit)
+	if err != nil {
+		wErr := fmt.Errorf("range in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return it, nil
+}
+
+func limitedRange(ctx context.Context, ordered engine.Ordered, start, end string, limit int) (engine.Iterator, error) {
+	it, err := ordered.Range(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 {
+		it = &limitIterator{Iterator: it, left: limit}
+	}
+
+	return it, nil
+}
+
+// Prefix iterates over keys starting with prefix in order.
+func (s Storage) Prefix(ctx context.Context, prefix string, limit int) (engine.Iterator, error) {
+	return s.Range(ctx, prefix, prefixEnd(prefix), limit)
+}
+
+// prefixEnd returns the smallest key greater than all keys with the prefix,
+// or an empty string when there is no such key.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+
+	return ""
+}
+
+type limitIterator struct {
+	engine.Iterator
+	left int
+}
+
+func (it *limitIterator) Next() bool {
+	if it.left == 0 {
+		return false
+	}
+	it.left--
+
+	return it.Iterator.Next()
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+func TestRangeAndPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewOrderedEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	for _, key := range []string{"user:1", "user:10", "user:2", "users", "video:1", "a"} {
+		require.NoError(t, storage.Set(ctx, key, "v-"+key))
+	}
+
+	it, err := storage.Range(ctx, "b", "video", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:10", "user:2", "users"}, collectKeys(t, it))
+
+	it, err = storage.Prefix(ctx, "user:", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:10", "user:2"}, collectKeys(t, it))
+
+	it, err = storage.Prefix(ctx, "user:", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:10"}, collectKeys(t, it))
+
+	it, err = storage.Range(ctx, "", "", 0)
+	require.NoError(t, err)
+	assert.Len(t, collectKeys(t, it), 6)
+}
+
+func TestRangeNotSupported(t *testing.T) {
+	storage, err := NewStorage(engine.NewEngine(), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, err = storage.Range(context.Background(), "a", "b", 0)
+	assert.ErrorIs(t, err, errRangeNotSupported)
+}
+
+func TestScanAndKeys(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	engines := map[string]EngineLayer{
+		"hashed":  engine.NewEngine(),
+		"ordered": engine.NewOrderedEngine(),
+	}
+
+	for name, e := range engines {
+		t.Run(name, func(t *testing.T) {
+			storage, err := NewStorage(e, logger)
+			require.NoError(t, err)
+
+			for i := range 50 {
+				require.NoError(t, storage.Set(ctx, fmt.Sprintf("user:%d", i), "value"))
+				require.NoError(t, storage.Set(ctx, fmt.Sprintf("video:%d", i), "value"))
+			}
+
+			var keys []string
+			cursor := "0"
+			for {
+				batch, next, err := storage.Scan(ctx, cursor, "user:*", 7)
+				require.NoError(t, err)
+				keys = append(keys, batch...)
+				if next == "0" {
+					break
+				}
+				cursor = next
+			}
+
+			all, err := storage.Keys(ctx, "user:*")
+			require.NoError(t, err)
+
+			assert.Len(t, keys, 50)
+			assert.ElementsMatch(t, keys, all)
+
+			_, _, err = storage.Scan(ctx, "not a cursor", "", 10)
+			assert.ErrorIs(t, err, errInvalidCursor)
+		})
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, "b", prefixEnd("a"))
+	assert.Equal(t, "user;", prefixEnd("user:"))
+	assert.Equal(t, "b", prefixEnd("a\xff"))
+	assert.Equal(t, "", prefixEnd("\xff\xff"))
+	assert.Equal(t, "", prefixEnd(""))
+}
+
+func collectKeys(t *testing.T, it engine.Iterator) []string {
+	defer it.Close()
+
+	var keys []string
+	for it.Next() {
+		keys = append(keys, it.Key())
+		assert.Equal(t, "v-"+it.Key(), it.Value())
+	}
+	require.NoError(t, it.Err())
+
+	return keys
+}
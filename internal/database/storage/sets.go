@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+// SAdd adds members to the set at the key and returns the number of new ones.
+func (s Storage) SAdd(ctx context.Context, key string, members ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SAdd"),
+		slog.String("key", key),
+		slog.Int("members", len(members)),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return 0, errSetsNotSupported
+	}
+
+	var added int
+	err := s.write(ctx, wal.OpSAdd, append([]string{key}, members...), func() error {
+		var err error
+		added, err = sets.SetAdd(ctx, key, members...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("set add to engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return added, nil
+}
+
+// SRem removes members and returns the number of removed ones.
+func (s Storage) SRem(ctx context.Context, key string, members ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SRem"),
+		slog.String("key", key),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return 0, errSetsNotSupported
+	}
+
+	var removed int
+	err := s.write(ctx, wal.OpSRem, append([]string{key}, members...), func() error {
+		var err error
+		removed, err = sets.SetRemove(ctx, key, members...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("set remove from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return removed, nil
+}
+
+func (s Storage) SMembers(ctx context.Context, key string) ([]string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SMembers"),
+		slog.String("key", key),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return nil, errSetsNotSupported
+	}
+
+	members, err := sets.SetMembers(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("set members from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return members, nil
+}
+
+func (s Storage) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SIsMember"),
+		slog.String("key", key),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return false, errSetsNotSupported
+	}
+
+	isMember, err := sets.SetIsMember(ctx, key, member)
+	if err != nil {
+		wErr := fmt.Errorf("set is member in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return false, wErr
+	}
+
+	return isMember, nil
+}
+
+func (s Storage) SCard(ctx context.Context, key string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SCard"),
+		slog.String("key", key),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return 0, errSetsNotSupported
+	}
+
+	card, err := sets.SetCard(ctx, key)
+	if err != nil {
+		wErr := fmt.Errorf("set card in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return card, nil
+}
+
+// SCombine returns the intersection, union or difference of sets of the keys.
+func (s Storage) SCombine(ctx context.Context, op engine.SetOp, keys ...string) ([]string, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "SCombine"),
+		slog.Any("keys", keys),
+	}
+
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errSetsNotSupported.Error(), logAttrs...)
+		return nil, errSetsNotSupported
+	}
+
+	members, err := sets.SetCombine(ctx, op, keys...)
+	if err != nil {
+		wErr := fmt.Errorf("set combine in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return members, nil
+}
+
+func (s Storage) applySetRecord(ctx context.Context, record wal.Record) error {
+	sets, ok := s.engine.(engine.Sets)
+	if !ok {
+		return errSetsNotSupported
+	}
+
+	if len(record.Args) == 0 {
+		return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+	}
+
+	var err error
+	if record.Op == wal.OpSAdd {
+		_, err = sets.SetAdd(ctx, record.Args[0], record.Args[1:]...)
+	} else {
+		_, err = sets.SetRemove(ctx, record.Args[0], record.Args[1:]...)
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+func TestSets(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewStorage(engine.NewShardedEngine(4), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	added, err := storage.SAdd(ctx, "a", "x", "y")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	_, err = storage.SAdd(ctx, "b", "y", "z")
+	assert.NoError(t, err)
+
+	members, err := storage.SCombine(ctx, engine.SetUnion, "a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y", "z"}, members)
+
+	removed, err := storage.SRem(ctx, "a", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	isMember, err := storage.SIsMember(ctx, "a", "x")
+	assert.NoError(t, err)
+	assert.False(t, isMember)
+
+	card, err := storage.SCard(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, card)
+}
+
+func TestSetsNotSupported(t *testing.T) {
+	storage, err := NewStorage(mocks.NewEngineLayer(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	_, err = storage.SMembers(context.Background(), "a")
+	assert.ErrorIs(t, err, errSetsNotSupported)
+
+	_, err = storage.ZRange(context.Background(), "a", 0, -1)
+	assert.ErrorIs(t, err, errZSetsNotSupported)
+}
+
+func TestRecoverReplaysSets(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+
+	_, err = st.SAdd(ctx, "tags", "go", "db", "kv")
+	require.NoError(t, err)
+	_, err = st.SRem(ctx, "tags", "db")
+	require.NoError(t, err)
+	_, err = st.ZAdd(ctx, "board", engine.ScoredMember{Member: "alice", Score: 0.5}, engine.ScoredMember{Member: "bob", Score: 2})
+	require.NoError(t, err)
+	_, err = st.ZIncrBy(ctx, "board", "alice", 0.25)
+	require.NoError(t, err)
+	_, err = st.ZRem(ctx, "board", "bob")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = wal.NewWAL(logger, &wal.Opts{DataDir: dir})
+	require.NoError(t, err)
+	defer w.Close()
+
+	st, err = NewStorage(engine.NewEngine(), logger, WithWAL(w))
+	require.NoError(t, err)
+	require.NoError(t, st.Recover(ctx))
+
+	members, err := st.SMembers(ctx, "tags")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "kv"}, members)
+
+	scored, err := st.ZRange(ctx, "board", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []engine.ScoredMember{{Member: "alice", Score: 0.75}}, scored)
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+type dumper interface {
+	Dump(ctx context.Context) ([]engine.Entry, error)
+	Restore(ctx context.Context, entry engine.Entry) error
+}
+
+// SupportsSnapshots is false for engines which can not be dumped, like the
+// ones persisting data on their own.
+func (s Storage) SupportsSnapshots() bool {
+	_, ok := s.engine.(dumper)
+	return ok
+}
+
+// Save synchronously writes a snapshot of the engine and drops WAL segments
+// covered by it.
+func (s Storage) Save(ctx context.Context) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "Save"),
+	}
+
+	if !s.saving.CompareAndSwap(false, true) {
+		return errSaveInProgress
+	}
+	defer s.saving.Store(false)
+
+	lsn, entries, err := s.capture(ctx)
+	if err != nil {
+		wErr := fmt.Errorf("capture snapshot: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	err = s.persistSnapshot(ctx, lsn, entries)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), logAttrs...)
+		return err
+	}
+
+	return nil
+}
+
+// BackgroundSave captures the engine state synchronously and writes the
+// snapshot file in a separate goroutine.
+func (s Storage) BackgroundSave(ctx context.Context) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "BackgroundSave"),
+	}
+
+	if !s.saving.CompareAndSwap(false, true) {
+		return errSaveInProgress
+	}
+
+	lsn, entries, err := s.capture(ctx)
+	if err != nil {
+		s.saving.Store(false)
+		wErr := fmt.Errorf("capture snapshot: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	go func() {
+		defer s.saving.Store(false)
+
+		// the request context ends with the reply, saving must outlive it
+		err := s.persistSnapshot(context.WithoutCancel(ctx), lsn, entries)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error(), logAttrs...)
+		}
+	}()
+
+	return nil
+}
+
+// RunSnapshots saves a snapshot every interval until ctx is canceled.
+func (s Storage) RunSnapshots(ctx context.Context, interval time.Duration) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "RunSnapshots"),
+	}
+
+	if _, ok := s.engine.(dumper); !ok {
+		s.logger.WarnContext(ctx, errSnapshotsNotSupported.Error(), logAttrs...)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.Save(ctx)
+			if err != nil {
+				s.logger.ErrorContext(ctx, fmt.Errorf("periodic snapshot: %w", err).Error(), logAttrs...)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// capture copies the engine state together with the LSN of the last record
+// applied to it. With the WAL enabled the copy is taken between batches.
+func (s Storage) capture(ctx context.Context) (uint64, []engine.Entry, error) {
+	if s.snapshots == nil {
+		return 0, nil, errSnapshotsDisabled
+	}
+
+	dumper, ok := s.engine.(dumper)
+	if !ok {
+		return 0, nil, errSnapshotsNotSupported
+	}
+
+	if s.wal == nil {
+		entries, err := dumper.Dump(ctx)
+		return 0, entries, err
+	}
+
+	var lsn uint64
+	var entries []engine.Entry
+	var dumpErr error
+	err := s.wal.Barrier(ctx, func(appliedLSN uint64) {
+		lsn = appliedLSN
+		entries, dumpErr = dumper.Dump(ctx)
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("wal barrier: %w", err)
+	}
+
+	return lsn, entries, dumpErr
+}
+
+func (s Storage) persistSnapshot(ctx context.Context, lsn uint64, entries []engine.Entry) error {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "persistSnapshot"),
+		slog.Uint64("lsn", lsn),
+	}
+
+	err := s.snapshots.Write(lsn, entries)
+	if err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, fmt.Sprintf("snapshot with %d keys saved", len(entries)), logAttrs...)
+
+	if s.wal == nil {
+		return nil
+	}
+
+	err = s.wal.Truncate(lsn)
+	if err != nil {
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+
+	return nil
+}
+
+// loadSnapshot restores the latest snapshot, engines which can not be
+// dumped keep their data themselves and nothing is loaded.
+func (s Storage) loadSnapshot(ctx context.Context) (uint64, error) {
+	if s.snapshots == nil {
+		return 0, nil
+	}
+
+	dumper, ok := s.engine.(dumper)
+	if !ok {
+		return 0, nil
+	}
+
+	return s.snapshots.LoadLatest(func(entry engine.Entry) error {
+		return dumper.Restore(ctx, entry)
+	})
+}
@@ -0,0 +1,9 @@
+package snapshot
+
+import "errors"
+
+var (
+	errInvalidLogger      = errors.New("invalid logger")
+	errCorruptedSnapshot  = errors.New("corrupted snapshot")
+	errUnsupportedVersion = errors.New("unsupported snapshot version")
+)
@@ -0,0 +1,454 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+type Store struct {
+	opts   Opts
+	logger *slog.Logger
+
+	// serializes writers, reading is done only on startup
+	mu *sync.Mutex
+}
+
+type Opts struct {
+	DataDir string
+	Keep    int
+}
+
+const (
+	defaultDataDir = "./data/snapshots"
+	defaultKeep    = 2
+
+	snapshotExt = ".snap"
+	tmpExt      = ".tmp"
+)
+
+func NewStore(logger *slog.Logger, opts *Opts) (*Store, error) {
+	if logger == nil {
+		return nil, errInvalidLogger
+	}
+
+	s := &Store{
+		opts:   prepareOpts(opts),
+		logger: logger,
+		mu:     &sync.Mutex{},
+	}
+
+	err := os.MkdirAll(s.opts.DataDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	return s, nil
+}
+
+func prepareOpts(opts *Opts) Opts {
+	prepared := Opts{}
+	if opts != nil {
+		prepared = *opts
+	}
+
+	if prepared.DataDir == "" {
+		prepared.DataDir = defaultDataDir
+	}
+	if prepared.Keep <= 0 {
+		prepared.Keep = defaultKeep
+	}
+
+	return prepared
+}
+
+// Write stores entries as a snapshot covering the log up to lsn. The file
+// becomes visible atomically, older snapshots above the Keep limit are removed.
+func (s *Store) Write(lsn uint64, entries []engine.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.opts.DataDir, snapshotName(lsn))
+	tmpPath := path + tmpExt
+
+	err := writeFile(tmpPath, lsn, entries)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming snapshot: %w", err)
+	}
+
+	err = syncDir(s.opts.DataDir)
+	if err != nil {
+		return err
+	}
+
+	s.removeOutdated()
+
+	return nil
+}
+
+// LoadLatest calls fn for each entry of the newest snapshot and returns the
+// LSN covered by it or 0 when there is no snapshot. A corrupted newest
+// snapshot is an error, older ones miss records already dropped from the WAL.
+func (s *Store) LoadLatest(fn func(engine.Entry) error) (uint64, error) {
+	snapshots, err := s.list()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(snapshots) == 0 {
+		return 0, nil
+	}
+
+	path := snapshots[len(snapshots)-1].path
+	if err := verifyFile(path); err != nil {
+		return 0, fmt.Errorf("verifying snapshot %s: %w", path, err)
+	}
+
+	lsn, err := readFile(path, fn)
+	if err != nil {
+		return 0, fmt.Errorf("loading snapshot %s: %w", path, err)
+	}
+
+	return lsn, nil
+}
+
+type file struct {
+	path string
+	lsn  uint64
+}
+
+func snapshotName(lsn uint64) string {
+	return fmt.Sprintf("%020d%s", lsn, snapshotExt)
+}
+
+func (s *Store) list() ([]file, error) {
+	entries, err := os.ReadDir(s.opts.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot directory: %w", err)
+	}
+
+	files := make([]file, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+
+		lsn, err := strconv.ParseUint(strings.TrimSuffix(name, snapshotExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, file{path: filepath.Join(s.opts.DataDir, name), lsn: lsn})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].lsn < files[j].lsn
+	})
+
+	return files, nil
+}
+
+func (s *Store) removeOutdated() {
+	files, err := s.list()
+	if err != nil {
+		s.logger.Error(err.Error(), slog.String("component", "snapshot"))
+		return
+	}
+
+	for i := 0; i < len(files)-s.opts.Keep; i++ {
+		err := os.Remove(files[i].path)
+		if err != nil {
+			s.logger.Error(fmt.Errorf("removing outdated snapshot: %w", err).Error(),
+				slog.String("component", "snapshot"),
+				slog.String("path", files[i].path),
+			)
+		}
+	}
+}
+
+// file layout:
+// | magic (4) | version (1) | lsn (8) | entries... | entries count (8) | crc32 (4) |
+// entry layout: | key length (uvarint) | key | kind (1) | value | expire at (uvarint) |
+// value layout is | length (uvarint) | bytes | for strings and
+// | items count (uvarint) | (item length (uvarint) | item)... | for collections.
+// version 1 entries have no expiration field, versions 1 and 2 have no kind
+// and hold strings only.
+var magic = []byte("KDBS")
+
+const (
+	versionV1  = 1
+	versionV2  = 2
+	version    = 3
+	headerSize = 13
+	footerSize = 12
+)
+
+func writeFile(path string, lsn uint64, entries []engine.Entry) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer f.Close()
+
+	checksum := crc32.NewIEEE()
+	writer := bufio.NewWriter(io.MultiWriter(f, checksum))
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint64(header, lsn)
+	if _, err := writer.Write(header); err != nil {
+		return fmt.Errorf("writing snapshot header: %w", err)
+	}
+
+	buf := make([]byte, 0, 64)
+	for _, entry := range entries {
+		buf = encodeEntry(buf[:0], entry)
+		if _, err := writer.Write(buf); err != nil {
+			return fmt.Errorf("writing snapshot entry: %w", err)
+		}
+	}
+
+	if _, err := writer.Write(binary.BigEndian.AppendUint64(nil, uint64(len(entries)))); err != nil {
+		return fmt.Errorf("writing snapshot footer: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("flushing snapshot: %w", err)
+	}
+
+	if _, err := f.Write(binary.BigEndian.AppendUint32(nil, checksum.Sum32())); err != nil {
+		return fmt.Errorf("writing snapshot checksum: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("syncing snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func encodeEntry(buf []byte, entry engine.Entry) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(entry.Key)))
+	buf = append(buf, entry.Key...)
+	buf = append(buf, byte(entry.Kind))
+	if entry.Kind == engine.KindString {
+		buf = appendString(buf, entry.Value)
+	} else {
+		buf = binary.AppendUvarint(buf, uint64(len(entry.Items)))
+		for _, item := range entry.Items {
+			buf = appendString(buf, item)
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(entry.ExpireAt))
+
+	return buf
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	buf = append(buf, s...)
+
+	return buf
+}
+
+func verifyFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat snapshot: %w", err)
+	}
+
+	size := info.Size()
+	if size < headerSize+footerSize {
+		return errCorruptedSnapshot
+	}
+
+	checksum := crc32.NewIEEE()
+	if _, err := io.Copy(checksum, io.LimitReader(f, size-4)); err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	expected := make([]byte, 4)
+	if _, err := io.ReadFull(f, expected); err != nil {
+		return fmt.Errorf("reading snapshot checksum: %w", err)
+	}
+
+	if binary.BigEndian.Uint32(expected) != checksum.Sum32() {
+		return errCorruptedSnapshot
+	}
+
+	return nil
+}
+
+func readFile(path string, fn func(engine.Entry) error) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("stat snapshot: %w", err)
+	}
+
+	reader := &countingReader{reader: bufio.NewReader(f)}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, errCorruptedSnapshot
+	}
+	if string(header[:4]) != string(magic) {
+		return 0, errCorruptedSnapshot
+	}
+	fileVersion := header[4]
+	if fileVersion != version && fileVersion != versionV2 && fileVersion != versionV1 {
+		return 0, fmt.Errorf("%w: %d", errUnsupportedVersion, fileVersion)
+	}
+	lsn := binary.BigEndian.Uint64(header[5:])
+
+	entriesEnd := info.Size() - footerSize
+	var count uint64
+	for reader.read < entriesEnd {
+		key, err := readString(reader)
+		if err != nil {
+			return 0, err
+		}
+
+		entry := engine.Entry{Key: key}
+		if fileVersion == version {
+			entry, err = readValue(reader, entry)
+		} else {
+			entry.Value, err = readString(reader)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var expireAt uint64
+		if fileVersion != versionV1 {
+			expireAt, err = binary.ReadUvarint(reader)
+			if err != nil {
+				return 0, errCorruptedSnapshot
+			}
+		}
+
+		entry.ExpireAt = int64(expireAt)
+		if err := fn(entry); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	footer := make([]byte, 8)
+	if _, err := io.ReadFull(reader, footer); err != nil {
+		return 0, errCorruptedSnapshot
+	}
+	if binary.BigEndian.Uint64(footer) != count {
+		return 0, errCorruptedSnapshot
+	}
+
+	return lsn, nil
+}
+
+func readValue(reader *countingReader, entry engine.Entry) (engine.Entry, error) {
+	kind, err := reader.ReadByte()
+	if err != nil {
+		return entry, errCorruptedSnapshot
+	}
+	entry.Kind = engine.Kind(kind)
+
+	if entry.Kind == engine.KindString {
+		entry.Value, err = readString(reader)
+		return entry, err
+	}
+
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return entry, errCorruptedSnapshot
+	}
+
+	// the count comes from the file, it is not trusted for preallocation
+	for range count {
+		item, err := readString(reader)
+		if err != nil {
+			return entry, err
+		}
+		entry.Items = append(entry.Items, item)
+	}
+
+	return entry, nil
+}
+
+func readString(reader *countingReader) (string, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return "", errCorruptedSnapshot
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return "", errCorruptedSnapshot
+	}
+
+	return string(buf), nil
+}
+
+type countingReader struct {
+	reader *bufio.Reader
+	read   int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (byte, error) {
+	b, err := r.reader.ReadByte()
+	if err == nil {
+		r.read++
+	}
+
+	return b, err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("opening snapshot directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("syncing snapshot directory: %w", err)
+	}
+
+	return nil
+}
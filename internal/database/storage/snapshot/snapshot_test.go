@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+)
+
+func TestNewStoreEmptyLogger(t *testing.T) {
+	_, err := NewStore(nil, &Opts{DataDir: t.TempDir()})
+	assert.ErrorIs(t, err, errInvalidLogger)
+}
+
+func TestWriteAndLoadLatest(t *testing.T) {
+	store := newTestStore(t, &Opts{DataDir: t.TempDir()})
+
+	entries := []engine.Entry{
+		{Key: "key", Value: "value"},
+		{Key: "empty", Value: ""},
+		{Key: "with spaces", Value: "value with spaces"},
+		{Key: "volatile", Value: "value", ExpireAt: 1700000000000000000},
+		{Key: "list", Kind: engine.KindList, Items: []string{"a", "", "b c"}, ExpireAt: 1700000000000000000},
+	}
+
+	require.NoError(t, store.Write(1, []engine.Entry{{Key: "old", Value: "old"}}))
+	require.NoError(t, store.Write(10, entries))
+
+	loaded := make([]engine.Entry, 0)
+	lsn, err := store.LoadLatest(func(entry engine.Entry) error {
+		loaded = append(loaded, entry)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), lsn)
+	assert.Equal(t, entries, loaded)
+}
+
+func TestLoadLatestFailsOnCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, &Opts{DataDir: dir})
+
+	require.NoError(t, store.Write(1, []engine.Entry{{Key: "old", Value: "old"}}))
+	require.NoError(t, store.Write(2, []engine.Entry{{Key: "new", Value: "new"}}))
+
+	path := filepath.Join(dir, snapshotName(2))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[headerSize] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	// the WAL was truncated up to the corrupted snapshot, the older one
+	// misses records
+	lsn, err := store.LoadLatest(func(entry engine.Entry) error {
+		t.Fatalf("unexpected entry %v", entry)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errCorruptedSnapshot)
+	assert.Equal(t, uint64(0), lsn)
+}
+
+func TestLoadVersion2(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, &Opts{DataDir: dir})
+
+	data := append([]byte{}, magic...)
+	data = append(data, versionV2)
+	data = binary.BigEndian.AppendUint64(data, 7)
+	data = binary.AppendUvarint(data, 3)
+	data = append(data, "key"...)
+	data = binary.AppendUvarint(data, 5)
+	data = append(data, "value"...)
+	data = binary.AppendUvarint(data, 42)
+	data = binary.BigEndian.AppendUint64(data, 1)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshotName(7)), data, 0o644))
+
+	loaded := make([]engine.Entry, 0)
+	lsn, err := store.LoadLatest(func(entry engine.Entry) error {
+		loaded = append(loaded, entry)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), lsn)
+	assert.Equal(t, []engine.Entry{{Key: "key", Value: "value", ExpireAt: 42}}, loaded)
+}
+
+func TestLoadLatestWithoutSnapshots(t *testing.T) {
+	store := newTestStore(t, &Opts{DataDir: t.TempDir()})
+
+	lsn, err := store.LoadLatest(func(entry engine.Entry) error {
+		t.Fatal("unexpected entry")
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), lsn)
+}
+
+func TestOutdatedSnapshotsAreRemoved(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestStore(t, &Opts{DataDir: dir, Keep: 2})
+
+	for i := range 5 {
+		require.NoError(t, store.Write(uint64(i), []engine.Entry{{Key: strconv.Itoa(i), Value: "value"}}))
+	}
+
+	files, err := store.list()
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, uint64(3), files[0].lsn)
+	assert.Equal(t, uint64(4), files[1].lsn)
+}
+
+func newTestStore(t *testing.T, opts *Opts) *Store {
+	store, err := NewStore(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), opts)
+	require.NoError(t, err)
+
+	return store
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/snapshot"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+func TestSaveAndRecoverFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	st, closeFn := newPersistentStorage(t, dir)
+
+	require.NoError(t, st.Set(ctx, "first", "1"))
+	require.NoError(t, st.Set(ctx, "second", "2"))
+	require.NoError(t, st.Save(ctx))
+
+	// written after the snapshot, restored from the wal tail
+	require.NoError(t, st.Set(ctx, "third", "3"))
+	_, err := st.Del(ctx, "first")
+	require.NoError(t, err)
+	closeFn()
+
+	st, closeFn = newPersistentStorage(t, dir)
+	defer closeFn()
+
+	require.NoError(t, st.Recover(ctx))
+
+	expected := map[string]string{"first": "", "second": "2", "third": "3"}
+	for key, value := range expected {
+		actual, _, err := st.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, value, actual)
+	}
+}
+
+func TestSaveTruncatesWAL(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir + "/wal", MaxSegmentSize: 32})
+	require.NoError(t, err)
+	defer w.Close()
+
+	store, err := snapshot.NewStore(logger, &snapshot.Opts{DataDir: dir + "/snapshots"})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w), WithSnapshots(store))
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, st.Set(ctx, key, key))
+	}
+	require.NoError(t, st.Save(ctx))
+
+	records := 0
+	err = w.Replay(ctx, 0, func(record wal.Record) error {
+		records++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, records)
+}
+
+func TestSaveWithoutSnapshots(t *testing.T) {
+	engine := mocks.NewEngineLayer(t)
+	st, err := NewStorage(engine, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, st.Save(context.Background()), errSnapshotsDisabled)
+	assert.ErrorIs(t, st.BackgroundSave(context.Background()), errSnapshotsDisabled)
+}
+
+func newPersistentStorage(t *testing.T, dir string) (*Storage, func()) {
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	w, err := wal.NewWAL(logger, &wal.Opts{DataDir: dir + "/wal"})
+	require.NoError(t, err)
+
+	store, err := snapshot.NewStore(logger, &snapshot.Opts{DataDir: dir + "/snapshots"})
+	require.NoError(t, err)
+
+	st, err := NewStorage(engine.NewEngine(), logger, WithWAL(w), WithSnapshots(store))
+	require.NoError(t, err)
+
+	return st, func() {
+		assert.NoError(t, w.Close())
+	}
+}
@@ -2,50 +2,102 @@ package storage
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/snapshot"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
 )
 
 type Storage struct {
-	engine EngineLayer
-	logger *slog.Logger
+	engine    EngineLayer
+	logger    *slog.Logger
+	wal       *wal.WAL
+	snapshots *snapshot.Store
+	saving    *atomic.Bool
 }
 
-var errInvalidLogger error = errors.New("invalid logger")
+type Option func(*Storage)
+
+func WithWAL(w *wal.WAL) Option {
+	return func(s *Storage) {
+		s.wal = w
+	}
+}
 
-func NewStorage(engine EngineLayer, logger *slog.Logger) (*Storage, error) {
+func WithSnapshots(store *snapshot.Store) Option {
+	return func(s *Storage) {
+		s.snapshots = store
+	}
+}
+
+func NewStorage(engine EngineLayer, logger *slog.Logger, opts ...Option) (*Storage, error) {
 	if logger == nil {
 		return nil, errInvalidLogger
 	}
 
-	return &Storage{
+	s := &Storage{
 		engine: engine,
 		logger: logger,
-	}, nil
+		saving: &atomic.Bool{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if evicting, ok := engine.(evictingEngine); ok && s.wal != nil {
+		evicting.DeferEviction()
+	}
+
+	return s, nil
 }
 
 type EngineLayer interface {
-	Get(ctx context.Context, key string) (string, error)
+	Get(ctx context.Context, key string) (string, bool, error)
 	Set(ctx context.Context, key, value string) error
-	Del(ctx context.Context, key string) error
+	Del(ctx context.Context, key string) (bool, error)
+}
+
+// evictingEngine is implemented by engines with a memory limit. With the WAL
+// enabled writes do not evict keys themselves, the storage logs evictions as
+// DEL records before the write, so the replay restores the same keys.
+type evictingEngine interface {
+	DeferEviction()
+	Victims(ctx context.Context, key string, size int64) ([]string, error)
+}
+
+// growingOps are WAL operations which may need memory.
+var growingOps = map[wal.Op]bool{
+	wal.OpSet:         true,
+	wal.OpIncrBy:      true,
+	wal.OpIncrByFloat: true,
+	wal.OpLPush:       true,
+	wal.OpRPush:       true,
+	wal.OpHSet:        true,
+	wal.OpHIncrBy:     true,
+	wal.OpSAdd:        true,
+	wal.OpZAdd:        true,
+	wal.OpZIncrBy:     true,
 }
 
-func (s Storage) Get(ctx context.Context, key string) (string, error) {
+// Get reports whether the key exists.
+func (s Storage) Get(ctx context.Context, key string) (string, bool, error) {
 	logAttrs := []any{
 		slog.String("component", "storage"),
 		slog.String("method", "Get"),
 		slog.String("key", key),
 	}
 
-	res, err := s.engine.Get(ctx, key)
+	res, ok, err := s.engine.Get(ctx, key)
 	if err != nil {
 		wErr := fmt.Errorf("get from engine: %w", err)
 		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
-		return "", wErr
+		return "", false, wErr
 	}
 
-	return res, nil
+	return res, ok, nil
 }
 
 func (s Storage) Set(ctx context.Context, key, value string) error {
@@ -56,7 +108,9 @@ func (s Storage) Set(ctx context.Context, key, value string) error {
 		slog.String("value", value),
 	}
 
-	err := s.engine.Set(ctx, key, value)
+	err := s.write(ctx, wal.OpSet, []string{key, value}, func() error {
+		return s.engine.Set(ctx, key, value)
+	})
 	if err != nil {
 		wErr := fmt.Errorf("set to engine: %w", err)
 		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
@@ -66,18 +120,88 @@ func (s Storage) Set(ctx context.Context, key, value string) error {
 	return nil
 }
 
-func (s Storage) Del(ctx context.Context, key string) error {
+// Del removes the keys with a single log record and returns how many of
+// them existed.
+func (s Storage) Del(ctx context.Context, keys ...string) (int, error) {
 	logAttrs := []any{
 		slog.String("component", "storage"),
 		slog.String("method", "Del"),
-		slog.String("key", key),
+		slog.Any("keys", keys),
 	}
 
-	err := s.engine.Del(ctx, key)
+	var removed int
+	err := s.write(ctx, wal.OpDel, keys, func() error {
+		for _, key := range keys {
+			ok, err := s.engine.Del(ctx, key)
+			if err != nil {
+				return err
+			}
+			if ok {
+				removed++
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		wErr := fmt.Errorf("delete from engine: %w", err)
 		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
-		return wErr
+		return removed, wErr
+	}
+
+	return removed, nil
+}
+
+// write logs the mutation into the WAL (when it is enabled) and applies it
+// to the engine once the log record is durable.
+func (s Storage) write(ctx context.Context, op wal.Op, args []string, apply func() error) error {
+	if s.wal == nil {
+		return apply()
+	}
+
+	err := s.evict(ctx, op, args)
+	if err != nil {
+		return err
+	}
+
+	var applyErr error
+	err = s.wal.Append(ctx, op, args, func(lsn uint64) {
+		applyErr = apply()
+		s.applied(lsn)
+	})
+	if err != nil {
+		return fmt.Errorf("append to wal: %w", err)
+	}
+
+	return applyErr
+}
+
+// evict makes room for the write before it is logged, so writes rejected
+// by the memory limit never get into the WAL.
+func (s Storage) evict(ctx context.Context, op wal.Op, args []string) error {
+	engine, ok := s.engine.(evictingEngine)
+	if !ok || !growingOps[op] {
+		return nil
+	}
+
+	var size int64
+	for _, arg := range args[1:] {
+		size += int64(len(arg))
+	}
+
+	victims, err := engine.Victims(ctx, args[0], size)
+	if err != nil || len(victims) == 0 {
+		return err
+	}
+
+	err = s.wal.Append(ctx, wal.OpDel, victims, func(lsn uint64) {
+		for _, key := range victims {
+			s.engine.Del(ctx, key)
+		}
+		s.applied(lsn)
+	})
+	if err != nil {
+		return fmt.Errorf("append eviction to wal: %w", err)
 	}
 
 	return nil
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"kdb/internal/database/storage/mocks"
+	"github.com/cat-go-dev/kdb/internal/database/storage/mocks"
 	"log/slog"
 	"testing"
 
@@ -29,11 +29,12 @@ func TestGetSuccess(t *testing.T) {
 
 	st, _ := NewStorage(engine, slog.New(slog.NewTextHandler(buf, nil)))
 
-	engine.EXPECT().Get(ctx, key).Return(value, nil)
+	engine.EXPECT().Get(ctx, key).Return(value, true, nil)
 
-	actual, err := st.Get(ctx, key)
+	actual, ok, err := st.Get(ctx, key)
 
 	assert.Nil(t, err)
+	assert.True(t, ok)
 	assert.Equal(t, value, actual)
 }
 
@@ -48,9 +49,9 @@ func TestGetError(t *testing.T) {
 
 	st, _ := NewStorage(engine, slog.New(slog.NewTextHandler(buf, nil)))
 
-	engine.EXPECT().Get(ctx, key).Return(value, expectedErr)
+	engine.EXPECT().Get(ctx, key).Return(value, false, expectedErr)
 
-	actual, err := st.Get(ctx, key)
+	actual, _, err := st.Get(ctx, key)
 
 	assert.ErrorIs(t, err, expectedErr)
 	assert.Equal(t, value, actual)
@@ -100,11 +101,13 @@ func TestDelSuccess(t *testing.T) {
 
 	st, _ := NewStorage(engine, slog.New(slog.NewTextHandler(buf, nil)))
 
-	engine.EXPECT().Del(ctx, key).Return(nil)
+	engine.EXPECT().Del(ctx, key).Return(true, nil)
+	engine.EXPECT().Del(ctx, "missing").Return(false, nil)
 
-	err := st.Del(ctx, key)
+	removed, err := st.Del(ctx, key, "missing")
 
 	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
 }
 
 func TestDel(t *testing.T) {
@@ -117,9 +120,9 @@ func TestDel(t *testing.T) {
 
 	st, _ := NewStorage(engine, slog.New(slog.NewTextHandler(buf, nil)))
 
-	engine.EXPECT().Del(ctx, key).Return(expextedErr)
+	engine.EXPECT().Del(ctx, key).Return(false, expextedErr)
 
-	err := st.Del(ctx, key)
+	_, err := st.Del(ctx, key)
 
 	assert.ErrorIs(t, err, expextedErr)
 }
@@ -0,0 +1,15 @@
+package wal
+
+import (
+	"errors"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+var (
+	errInvalidLogger   = errors.New("invalid logger")
+	errClosed          = errors.New("wal is closed")
+	errCorruptedRecord = errors.New("corrupted wal record")
+
+	errRecordTooLarge = ports.NewClientError(ports.CodeInvalidArgument, "wal record is too large")
+)
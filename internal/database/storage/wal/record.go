@@ -0,0 +1,139 @@
+package wal
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+type Op uint8
+
+const (
+	OpSet Op = iota + 1
+	OpDel
+	OpExpire
+	OpPersist
+	OpIncrBy
+	OpIncrByFloat
+	OpLPush
+	OpRPush
+	OpLPop
+	OpRPop
+	OpLTrim
+	OpHSet
+	OpHDel
+	OpHIncrBy
+	OpSAdd
+	OpSRem
+	OpZAdd
+	OpZRem
+	OpZIncrBy
+)
+
+type Record struct {
+	LSN  uint64
+	Op   Op
+	Args []string
+}
+
+// record layout: | crc32 (4) | payload length (4) | payload |
+// payload layout: | lsn (8) | op (1) | args count (uvarint) | (arg length (uvarint) | arg)... |
+const recordHeaderSize = 8
+
+func (r Record) encode(buf []byte) []byte {
+	start := len(buf)
+	buf = append(buf, make([]byte, recordHeaderSize)...)
+
+	buf = binary.BigEndian.AppendUint64(buf, r.LSN)
+	buf = append(buf, byte(r.Op))
+	buf = binary.AppendUvarint(buf, uint64(len(r.Args)))
+	for _, arg := range r.Args {
+		buf = binary.AppendUvarint(buf, uint64(len(arg)))
+		buf = append(buf, arg...)
+	}
+
+	payload := buf[start+recordHeaderSize:]
+	binary.BigEndian.PutUint32(buf[start:], crc32.ChecksumIEEE(payload))
+	binary.BigEndian.PutUint32(buf[start+4:], uint32(len(payload)))
+
+	return buf
+}
+
+// payloadSize is the length of the encoded payload of the record.
+func (r Record) payloadSize() int {
+	size := 8 + 1 + uvarintSize(uint64(len(r.Args)))
+	for _, arg := range r.Args {
+		size += uvarintSize(uint64(len(arg))) + len(arg)
+	}
+
+	return size
+}
+
+func uvarintSize(n uint64) int {
+	return len(binary.AppendUvarint(nil, n))
+}
+
+// readRecord reads a single record, it returns io.EOF on a clean end of
+// the stream and errCorruptedRecord on a torn or damaged record.
+func readRecord(r io.Reader) (Record, int, error) {
+	header := make([]byte, recordHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err == io.EOF {
+		return Record{}, 0, io.EOF
+	}
+	if err != nil {
+		return Record{}, n, errCorruptedRecord
+	}
+
+	checksum := binary.BigEndian.Uint32(header)
+	length := binary.BigEndian.Uint32(header[4:])
+	if length > maxRecordSize {
+		return Record{}, n, errCorruptedRecord
+	}
+
+	payload := make([]byte, length)
+	m, err := io.ReadFull(r, payload)
+	n += m
+	if err != nil || crc32.ChecksumIEEE(payload) != checksum {
+		return Record{}, n, errCorruptedRecord
+	}
+
+	record, err := decodePayload(payload)
+	if err != nil {
+		return Record{}, n, err
+	}
+
+	return record, n, nil
+}
+
+func decodePayload(payload []byte) (Record, error) {
+	if len(payload) < 9 {
+		return Record{}, errCorruptedRecord
+	}
+
+	record := Record{
+		LSN: binary.BigEndian.Uint64(payload),
+		Op:  Op(payload[8]),
+	}
+	payload = payload[9:]
+
+	count, n := binary.Uvarint(payload)
+	if n <= 0 || count > uint64(len(payload)) {
+		return Record{}, errCorruptedRecord
+	}
+	payload = payload[n:]
+
+	record.Args = make([]string, 0, count)
+	for range count {
+		length, n := binary.Uvarint(payload)
+		if n <= 0 || length > uint64(len(payload)-n) {
+			return Record{}, errCorruptedRecord
+		}
+		payload = payload[n:]
+
+		record.Args = append(record.Args, string(payload[:length]))
+		payload = payload[length:]
+	}
+
+	return record, nil
+}
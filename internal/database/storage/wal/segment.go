@@ -0,0 +1,88 @@
+package wal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const segmentExt = ".wal"
+
+type segment struct {
+	path     string
+	firstLSN uint64
+}
+
+func segmentName(firstLSN uint64) string {
+	return fmt.Sprintf("%020d%s", firstLSN, segmentExt)
+}
+
+func listSegments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading wal directory: %w", err)
+	}
+
+	segments := make([]segment, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		firstLSN, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, segment{
+			path:     filepath.Join(dir, name),
+			firstLSN: firstLSN,
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].firstLSN < segments[j].firstLSN
+	})
+
+	return segments, nil
+}
+
+// readSegment calls fn for every valid record of the segment and returns
+// the offset right after the last valid record and the last seen LSN.
+func readSegment(path string, fn func(Record) error) (int64, uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("opening segment: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	var offset int64
+	var lastLSN uint64
+	for {
+		record, n, err := readRecord(reader)
+		if errors.Is(err, io.EOF) {
+			return offset, lastLSN, nil
+		}
+		if err != nil {
+			return offset, lastLSN, err
+		}
+
+		if fn != nil {
+			if err := fn(record); err != nil {
+				return offset, lastLSN, err
+			}
+		}
+
+		offset += int64(n)
+		lastLSN = record.LSN
+	}
+}
@@ -0,0 +1,446 @@
+package wal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type WAL struct {
+	opts   Opts
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	lsn    uint64
+	batch  []*request
+	closed bool
+
+	flushCh   chan struct{}
+	barrierCh chan func(uint64)
+	closeCh   chan struct{}
+	doneCh    chan struct{}
+
+	// appliedLSN is written by the flush loop and AdvanceLSN
+	appliedLSN atomic.Uint64
+
+	// owned by the flush loop after start
+	file     *os.File
+	fileSize int64
+	buf      []byte
+}
+
+type Opts struct {
+	DataDir        string
+	FlushTimeout   time.Duration
+	MaxBatchSize   int
+	MaxSegmentSize int64
+}
+
+type request struct {
+	record Record
+	apply  func(lsn uint64)
+	done   chan error
+}
+
+const (
+	defaultDataDir        = "./data/wal"
+	defaultFlushTimeout   = 10 * time.Millisecond
+	defaultMaxBatchSize   = 100
+	defaultMaxSegmentSize = 10 << 20
+
+	maxRecordSize = 64 << 20
+)
+
+func NewWAL(logger *slog.Logger, opts *Opts) (*WAL, error) {
+	if logger == nil {
+		return nil, errInvalidLogger
+	}
+
+	w := &WAL{
+		opts:      prepareOpts(opts),
+		logger:    logger,
+		flushCh:   make(chan struct{}, 1),
+		barrierCh: make(chan func(uint64)),
+		closeCh:   make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	err := os.MkdirAll(w.opts.DataDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("creating wal directory: %w", err)
+	}
+
+	err = w.openActiveSegment()
+	if err != nil {
+		return nil, err
+	}
+
+	go w.loop()
+
+	return w, nil
+}
+
+func prepareOpts(opts *Opts) Opts {
+	prepared := Opts{}
+	if opts != nil {
+		prepared = *opts
+	}
+
+	if prepared.DataDir == "" {
+		prepared.DataDir = defaultDataDir
+	}
+	if prepared.FlushTimeout <= 0 {
+		prepared.FlushTimeout = defaultFlushTimeout
+	}
+	if prepared.MaxBatchSize <= 0 {
+		prepared.MaxBatchSize = defaultMaxBatchSize
+	}
+	if prepared.MaxSegmentSize <= 0 {
+		prepared.MaxSegmentSize = defaultMaxSegmentSize
+	}
+
+	return prepared
+}
+
+// openActiveSegment reopens the newest segment for appending. A torn record
+// at the end of it (crash in the middle of a write) is cut off.
+func (w *WAL) openActiveSegment() error {
+	segments, err := listSegments(w.opts.DataDir)
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 0 {
+		return w.createSegment(1)
+	}
+
+	last := segments[len(segments)-1]
+	offset, lastLSN, err := readSegment(last.path, nil)
+	if err != nil && err != errCorruptedRecord {
+		return fmt.Errorf("reading last segment: %w", err)
+	}
+
+	if lastLSN == 0 {
+		lastLSN = last.firstLSN - 1
+	}
+	w.lsn = lastLSN
+
+	file, err := os.OpenFile(last.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening last segment: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat last segment: %w", err)
+	}
+
+	if info.Size() > offset {
+		w.logger.Warn("truncating torn wal tail",
+			slog.String("component", "wal"),
+			slog.String("segment", last.path),
+			slog.Int64("offset", offset),
+			slog.Int64("size", info.Size()),
+		)
+
+		err = file.Truncate(offset)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("truncating last segment: %w", err)
+		}
+	}
+
+	w.file = file
+	w.fileSize = offset
+	w.appliedLSN.Store(lastLSN)
+
+	return nil
+}
+
+func (w *WAL) createSegment(firstLSN uint64) error {
+	path := filepath.Join(w.opts.DataDir, segmentName(firstLSN))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+
+	w.file = file
+	w.fileSize = 0
+	w.lsn = firstLSN - 1
+	w.appliedLSN.Store(firstLSN - 1)
+
+	return nil
+}
+
+// Replay calls fn for every record with LSN greater than fromLSN in the log
+// order. It must be called before the first Append.
+func (w *WAL) Replay(ctx context.Context, fromLSN uint64, fn func(Record) error) error {
+	segments, err := listSegments(w.opts.DataDir)
+	if err != nil {
+		return err
+	}
+
+	for i, seg := range segments {
+		if i+1 < len(segments) && segments[i+1].firstLSN <= fromLSN+1 {
+			continue
+		}
+
+		_, _, err := readSegment(seg.path, func(record Record) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if record.LSN <= fromLSN {
+				return nil
+			}
+
+			return fn(record)
+		})
+		if err != nil {
+			return fmt.Errorf("replaying segment %s: %w", seg.path, err)
+		}
+	}
+
+	return nil
+}
+
+// Append writes the record into the log and blocks until the batch with it
+// is synced to disk. apply is invoked with the LSN of the record right after
+// the sync in the LSN order, so changes become visible in the same order as
+// they are replayed.
+func (w *WAL) Append(ctx context.Context, op Op, args []string, apply func(lsn uint64)) error {
+	req := &request{
+		record: Record{Op: op, Args: args},
+		apply:  apply,
+		done:   make(chan error, 1),
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// larger records are taken for corrupted ones when the log is read
+	if size := req.record.payloadSize(); size > maxRecordSize {
+		return errRecordTooLarge.Detail("%d bytes", size)
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errClosed
+	}
+
+	w.lsn++
+	req.record.LSN = w.lsn
+	w.batch = append(w.batch, req)
+	full := len(w.batch) >= w.opts.MaxBatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return <-req.done
+}
+
+// Barrier flushes pending records and runs fn in between batches with the
+// LSN of the last applied record, no records are applied while fn runs.
+func (w *WAL) Barrier(ctx context.Context, fn func(appliedLSN uint64)) error {
+	done := make(chan struct{})
+	barrier := func(appliedLSN uint64) {
+		defer close(done)
+		fn(appliedLSN)
+	}
+
+	select {
+	case w.barrierCh <- barrier:
+	case <-w.doneCh:
+		return errClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	<-done
+
+	return nil
+}
+
+// Truncate removes segments that contain only records with LSN less than
+// or equal to lsn. The active segment is never removed.
+func (w *WAL) Truncate(lsn uint64) error {
+	segments, err := listSegments(w.opts.DataDir)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i+1].firstLSN > lsn+1 {
+			break
+		}
+
+		err := os.Remove(segments[i].path)
+		if err != nil {
+			return fmt.Errorf("removing segment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// AdvanceLSN moves the LSN counter forward so new records are numbered
+// after lsn, e.g. after restoring a snapshot newer than the log itself.
+func (w *WAL) AdvanceLSN(lsn uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lsn < lsn {
+		w.lsn = lsn
+		w.appliedLSN.Store(lsn)
+	}
+}
+
+func (w *WAL) LastLSN() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lsn
+}
+
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.closeCh)
+	<-w.doneCh
+
+	return w.file.Close()
+}
+
+func (w *WAL) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.opts.FlushTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.flushCh:
+			w.flush()
+		case barrier := <-w.barrierCh:
+			w.flush()
+			barrier(w.appliedLSN.Load())
+		case <-ticker.C:
+			w.flush()
+		case <-w.closeCh:
+			w.flush()
+			return
+		}
+	}
+}
+
+func (w *WAL) flush() {
+	w.mu.Lock()
+	batch := w.batch
+	w.batch = nil
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	err := w.writeBatch(batch)
+	if err != nil {
+		w.logger.Error(fmt.Errorf("writing wal batch: %w", err).Error(),
+			slog.String("component", "wal"),
+			slog.String("method", "flush"),
+		)
+	}
+
+	for _, req := range batch {
+		if err == nil && req.apply != nil {
+			req.apply(req.record.LSN)
+		}
+
+		req.done <- err
+	}
+
+	if err == nil {
+		w.appliedLSN.Store(batch[len(batch)-1].record.LSN)
+	}
+}
+
+func (w *WAL) writeBatch(batch []*request) error {
+	w.buf = w.buf[:0]
+	for _, req := range batch {
+		w.buf = req.record.encode(w.buf)
+	}
+
+	_, err := w.file.Write(w.buf)
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		// drop a partially written batch, it was not acknowledged
+		if tErr := w.file.Truncate(w.fileSize); tErr != nil {
+			w.logger.Error(fmt.Errorf("truncating failed batch: %w", tErr).Error(),
+				slog.String("component", "wal"),
+				slog.String("method", "writeBatch"),
+			)
+		}
+		return err
+	}
+
+	w.fileSize += int64(len(w.buf))
+	if w.fileSize < w.opts.MaxSegmentSize {
+		return nil
+	}
+
+	// the batch is already durable, a failed rotation only means that the
+	// current segment keeps growing
+	err = w.rotate(batch[len(batch)-1].record.LSN + 1)
+	if err != nil {
+		w.logger.Error(fmt.Errorf("rotating segment: %w", err).Error(),
+			slog.String("component", "wal"),
+			slog.String("method", "writeBatch"),
+		)
+	}
+
+	return nil
+}
+
+func (w *WAL) rotate(firstLSN uint64) error {
+	path := filepath.Join(w.opts.DataDir, segmentName(firstLSN))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+
+	err = w.file.Close()
+	if err != nil {
+		w.logger.Error(fmt.Errorf("closing segment: %w", err).Error(),
+			slog.String("component", "wal"),
+			slog.String("method", "rotate"),
+		)
+	}
+
+	w.file = file
+	w.fileSize = 0
+
+	return nil
+}
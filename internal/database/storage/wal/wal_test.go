@@ -0,0 +1,198 @@
+package wal
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWALEmptyLogger(t *testing.T) {
+	_, err := NewWAL(nil, &Opts{DataDir: t.TempDir()})
+	assert.ErrorIs(t, err, errInvalidLogger)
+}
+
+func TestAppendAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	w := newTestWAL(t, &Opts{DataDir: dir})
+
+	var applied []uint64
+	apply := func(lsn uint64) { applied = append(applied, lsn) }
+	err := w.Append(ctx, OpSet, []string{"key", "value"}, apply)
+	assert.NoError(t, err)
+	err = w.Append(ctx, OpDel, []string{"key"}, apply)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, applied)
+	assert.NoError(t, w.Close())
+
+	w = newTestWAL(t, &Opts{DataDir: dir})
+	defer w.Close()
+
+	records := replayAll(t, w, 0)
+	assert.Equal(t, []Record{
+		{LSN: 1, Op: OpSet, Args: []string{"key", "value"}},
+		{LSN: 2, Op: OpDel, Args: []string{"key"}},
+	}, records)
+	assert.Equal(t, uint64(2), w.LastLSN())
+}
+
+func TestReplayFromLSN(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	w := newTestWAL(t, &Opts{DataDir: dir, MaxSegmentSize: 64})
+	for i := range 10 {
+		err := w.Append(ctx, OpSet, []string{strconv.Itoa(i), "value"}, nil)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	segments, err := listSegments(dir)
+	assert.NoError(t, err)
+	assert.Greater(t, len(segments), 1)
+
+	w = newTestWAL(t, &Opts{DataDir: dir, MaxSegmentSize: 64})
+	defer w.Close()
+
+	records := replayAll(t, w, 7)
+	assert.Len(t, records, 3)
+	assert.Equal(t, uint64(8), records[0].LSN)
+	assert.Equal(t, uint64(10), records[2].LSN)
+}
+
+func TestTornTailIsTruncated(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	w := newTestWAL(t, &Opts{DataDir: dir})
+	err := w.Append(ctx, OpSet, []string{"key", "value"}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	segments, err := listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	file, err := os.OpenFile(segments[0].path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{1, 2, 3, 4, 5})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	w = newTestWAL(t, &Opts{DataDir: dir})
+	records := replayAll(t, w, 0)
+	assert.Len(t, records, 1)
+
+	err = w.Append(ctx, OpDel, []string{"key"}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	w = newTestWAL(t, &Opts{DataDir: dir})
+	defer w.Close()
+
+	records = replayAll(t, w, 0)
+	assert.Equal(t, []Record{
+		{LSN: 1, Op: OpSet, Args: []string{"key", "value"}},
+		{LSN: 2, Op: OpDel, Args: []string{"key"}},
+	}, records)
+}
+
+func TestConcurrentAppendsAreAppliedInLogOrder(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	w := newTestWAL(t, &Opts{DataDir: dir, MaxBatchSize: 16, FlushTimeout: 100 * time.Millisecond})
+
+	mu := &sync.Mutex{}
+	order := make([]string, 0, 100)
+
+	wg := &sync.WaitGroup{}
+	for i := range 100 {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+
+			err := w.Append(ctx, OpSet, []string{key, key}, func(uint64) {
+				mu.Lock()
+				order = append(order, key)
+				mu.Unlock()
+			})
+			assert.NoError(t, err)
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	assert.NoError(t, w.Close())
+
+	w = newTestWAL(t, &Opts{DataDir: dir})
+	defer w.Close()
+
+	records := replayAll(t, w, 0)
+	replayed := make([]string, 0, len(records))
+	for _, record := range records {
+		replayed = append(replayed, record.Args[0])
+	}
+
+	assert.Equal(t, order, replayed)
+}
+
+func TestAppendAfterClose(t *testing.T) {
+	w := newTestWAL(t, &Opts{DataDir: t.TempDir()})
+	assert.NoError(t, w.Close())
+
+	err := w.Append(context.Background(), OpSet, []string{"key", "value"}, nil)
+	assert.ErrorIs(t, err, errClosed)
+}
+
+func TestAppendTooLargeRecord(t *testing.T) {
+	w := newTestWAL(t, &Opts{DataDir: t.TempDir()})
+	defer w.Close()
+
+	err := w.Append(context.Background(), OpSet, []string{"key", strings.Repeat("a", maxRecordSize)}, nil)
+	assert.ErrorIs(t, err, errRecordTooLarge)
+	assert.Equal(t, uint64(0), w.LastLSN())
+}
+
+func TestRecordEncodeDecode(t *testing.T) {
+	record := Record{LSN: 42, Op: OpSet, Args: []string{"key", "", "value with spaces"}}
+
+	buf := record.encode(nil)
+	assert.Equal(t, len(buf)-recordHeaderSize, record.payloadSize())
+
+	actual, n, err := readRecord(bytes.NewReader(buf))
+	assert.NoError(t, err)
+	assert.Equal(t, len(buf), n)
+	assert.Equal(t, record, actual)
+
+	buf[len(buf)-1] ^= 0xff
+	_, _, err = readRecord(bytes.NewReader(buf))
+	assert.ErrorIs(t, err, errCorruptedRecord)
+}
+
+func newTestWAL(t *testing.T, opts *Opts) *WAL {
+	buf := new(bytes.Buffer)
+	w, err := NewWAL(slog.New(slog.NewTextHandler(buf, nil)), opts)
+	require.NoError(t, err)
+
+	return w
+}
+
+func replayAll(t *testing.T, w *WAL, fromLSN uint64) []Record {
+	records := make([]Record, 0)
+	err := w.Replay(context.Background(), fromLSN, func(record Record) error {
+		records = append(records, record)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return records
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+)
+
+// ZAdd sets scores of members in the sorted set at the key and returns the
+// number of new members.
+func (s Storage) ZAdd(ctx context.Context, key string, members ...engine.ScoredMember) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZAdd"),
+		slog.String("key", key),
+		slog.Int("members", len(members)),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return 0, errZSetsNotSupported
+	}
+
+	args := make([]string, 0, 1+2*len(members))
+	args = append(args, key)
+	for _, m := range members {
+		args = append(args, formatScore(m.Score), m.Member)
+	}
+
+	var added int
+	err := s.write(ctx, wal.OpZAdd, args, func() error {
+		var err error
+		added, err = zsets.ZAdd(ctx, key, members...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("sorted set add to engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return added, nil
+}
+
+// ZRem removes members and returns the number of removed ones.
+func (s Storage) ZRem(ctx context.Context, key string, members ...string) (int, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZRem"),
+		slog.String("key", key),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return 0, errZSetsNotSupported
+	}
+
+	var removed int
+	err := s.write(ctx, wal.OpZRem, append([]string{key}, members...), func() error {
+		var err error
+		removed, err = zsets.ZRemove(ctx, key, members...)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("sorted set remove from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return removed, nil
+}
+
+func (s Storage) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZScore"),
+		slog.String("key", key),
+		slog.String("member", member),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return 0, false, errZSetsNotSupported
+	}
+
+	score, exists, err := zsets.ZScore(ctx, key, member)
+	if err != nil {
+		wErr := fmt.Errorf("sorted set score from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, false, wErr
+	}
+
+	return score, exists, nil
+}
+
+func (s Storage) ZRank(ctx context.Context, key, member string) (int, bool, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZRank"),
+		slog.String("key", key),
+		slog.String("member", member),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return 0, false, errZSetsNotSupported
+	}
+
+	rank, exists, err := zsets.ZRank(ctx, key, member)
+	if err != nil {
+		wErr := fmt.Errorf("sorted set rank from engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, false, wErr
+	}
+
+	return rank, exists, nil
+}
+
+func (s Storage) ZRange(ctx context.Context, key string, start, stop int) ([]engine.ScoredMember, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZRange"),
+		slog.String("key", key),
+		slog.Int("start", start),
+		slog.Int("stop", stop),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return nil, errZSetsNotSupported
+	}
+
+	members, err := zsets.ZRange(ctx, key, start, stop)
+	if err != nil {
+		wErr := fmt.Errorf("sorted set range in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return members, nil
+}
+
+func (s Storage) ZRangeByScore(ctx context.Context, key string, min, max engine.ScoreBound, offset, count int) ([]engine.ScoredMember, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZRangeByScore"),
+		slog.String("key", key),
+		slog.Float64("min", min.Value),
+		slog.Float64("max", max.Value),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return nil, errZSetsNotSupported
+	}
+
+	members, err := zsets.ZRangeByScore(ctx, key, min, max, offset, count)
+	if err != nil {
+		wErr := fmt.Errorf("sorted set range by score in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return nil, wErr
+	}
+
+	return members, nil
+}
+
+// ZIncrBy adds delta to the score of the member, the WAL keeps the delta.
+func (s Storage) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	logAttrs := []any{
+		slog.String("component", "storage"),
+		slog.String("method", "ZIncrBy"),
+		slog.String("key", key),
+		slog.String("member", member),
+		slog.Float64("delta", delta),
+	}
+
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		s.logger.ErrorContext(ctx, errZSetsNotSupported.Error(), logAttrs...)
+		return 0, errZSetsNotSupported
+	}
+
+	var score float64
+	err := s.write(ctx, wal.OpZIncrBy, []string{key, member, formatScore(delta)}, func() error {
+		var err error
+		score, err = zsets.ZIncrBy(ctx, key, member, delta)
+		return err
+	})
+	if err != nil {
+		wErr := fmt.Errorf("sorted set incr in engine: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return 0, wErr
+	}
+
+	return score, nil
+}
+
+func (s Storage) applyZSetRecord(ctx context.Context, record wal.Record) error {
+	zsets, ok := s.engine.(engine.SortedSets)
+	if !ok {
+		return errZSetsNotSupported
+	}
+
+	if len(record.Args) == 0 {
+		return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+	}
+	key := record.Args[0]
+
+	switch record.Op {
+	case wal.OpZAdd:
+		pairs := record.Args[1:]
+		if len(pairs)%2 != 0 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		members := make([]engine.ScoredMember, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			score, err := parseScore(pairs[i])
+			if err != nil {
+				return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+			}
+			members = append(members, engine.ScoredMember{Member: pairs[i+1], Score: score})
+		}
+
+		_, err := zsets.ZAdd(ctx, key, members...)
+		return err
+	case wal.OpZRem:
+		_, err := zsets.ZRemove(ctx, key, record.Args[1:]...)
+		return err
+	default:
+		if len(record.Args) != 3 {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		delta, err := parseScore(record.Args[2])
+		if err != nil {
+			return fmt.Errorf("%w: lsn %d", errInvalidWALEntry, record.LSN)
+		}
+
+		_, err = zsets.ZIncrBy(ctx, key, record.Args[1], delta)
+		return err
+	}
+}
+
+// formatScore keeps full precision of scores written to the WAL.
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+func parseScore(value string) (float64, error) {
+	score, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, errInvalidWALEntry
+	}
+
+	return score, nil
+}
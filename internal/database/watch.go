@@ -0,0 +1,71 @@
+package database
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// watches tracks sessions watching keys, a write to a watched key makes
+// EXEC of those sessions fail.
+type watches struct {
+	mu   *sync.Mutex
+	keys map[string]map[*Session]struct{}
+	// lets writes skip the lock when nothing is watched
+	count *atomic.Int64
+}
+
+func newWatches() *watches {
+	return &watches{
+		mu:    &sync.Mutex{},
+		keys:  make(map[string]map[*Session]struct{}),
+		count: &atomic.Int64{},
+	}
+}
+
+func (w *watches) add(key string, session *Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sessions, ok := w.keys[key]
+	if !ok {
+		sessions = make(map[*Session]struct{})
+		w.keys[key] = sessions
+	}
+
+	if _, ok := sessions[session]; !ok {
+		sessions[session] = struct{}{}
+		w.count.Add(1)
+	}
+}
+
+func (w *watches) remove(key string, session *Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sessions, ok := w.keys[key]
+	if !ok {
+		return
+	}
+
+	if _, ok := sessions[session]; ok {
+		delete(sessions, session)
+		w.count.Add(-1)
+	}
+	if len(sessions) == 0 {
+		delete(w.keys, key)
+	}
+}
+
+// touch marks all sessions watching the key as dirty.
+func (w *watches) touch(key string) {
+	if w.count.Load() == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for session := range w.keys[key] {
+		session.dirty.Store(true)
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"os"
 	"time"
 
-	"kdb/internal/config"
+	"github.com/cat-go-dev/kdb/internal/config"
 )
 
 const (
@@ -7,18 +7,36 @@ import (
 	"log/slog"
 	"net"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
 )
 
 type Client struct {
-	in     chan string
-	out    chan string
 	logger *slog.Logger
 	opts   *ClientOpts
+
+	// mu keeps the order of written commands and pending futures the same
+	mu      *sync.Mutex
+	conn    net.Conn
+	writer  *bufio.Writer
+	pending []*Future
+	// inflight are futures of the framed protocol by request IDs
+	inflight map[uint32]*Future
+	nextID   uint32
+	// err is set when the connection is broken, closed is closed then
+	err    error
+	closed chan struct{}
 }
 
 type ClientOpts struct {
 	Server  string
 	Port    int
+	// DialTimeout is not limited by default
+	DialTimeout time.Duration
+	// Protocol is ProtocolLine by default or ProtocolFramed
+	Protocol string
 }
 
 const (
@@ -31,84 +49,357 @@ func NewClient(logger *slog.Logger, opts *ClientOpts) (*Client, error) {
 		return nil, errInvalidLogger
 	}
 
+	if opts != nil {
+		switch opts.Protocol {
+		case "", ProtocolLine, ProtocolFramed:
+		default:
+			return nil, fmt.Errorf("%w: %q", errInvalidProtocol, opts.Protocol)
+		}
+	}
+
 	return &Client{
-		in:     make(chan string),
-		out:    make(chan string),
-		logger: logger,
-		opts:   opts,
+		logger:   logger,
+		opts:     opts,
+		mu:       &sync.Mutex{},
+		inflight: make(map[uint32]*Future),
+		closed:   make(chan struct{}),
 	}, nil
 }
 
+// Future is a reply which may be not read yet.
+type Future struct {
+	done  chan struct{}
+	reply *ports.Result
+	err   error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Wait returns the reply rendered for humans once it is read.
+func (f *Future) Wait(ctx context.Context) (string, error) {
+	reply, err := f.Reply(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return renderReply(reply), nil
+}
+
+// Reply returns the typed reply once it is read.
+func (f *Future) Reply(ctx context.Context) (*ports.Result, error) {
+	select {
+	case <-f.done:
+		return f.reply, f.err
+	case <-ctx.Done():
+		return nil, errCanceledContext
+	}
+}
+
+func (f *Future) resolve(reply *ports.Result, err error) {
+	f.reply = reply
+	f.err = err
+	close(f.done)
+}
+
 func (c *Client) Run(ctx context.Context) error {
 	logAttrs := []any{
 		slog.String("component", "tcp_client"),
 		slog.String("method", "Run"),
 	}
 
-	conn, err := net.Dial("tcp", c.getAddressToConnect())
+	dialer := net.Dialer{Timeout: c.opts.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.getAddressToConnect())
 	if err != nil {
 		wErr := fmt.Errorf("trying create tcp client connection: %w", err)
 		c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
 		return wErr
 	}
 
+	reader := bufio.NewReader(conn)
+	if c.framed() {
+		err = c.handshake(conn, reader)
+		if err != nil {
+			conn.Close()
+			wErr := fmt.Errorf("trying to start framed protocol: %w", err)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return wErr
+		}
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.writer = bufio.NewWriter(conn)
+	c.mu.Unlock()
+
 	c.logger.InfoContext(ctx, "tcp client is started", logAttrs...)
 
 	go func() {
-		defer conn.Close()
-		for {
-			select {
-			case message := <-c.in:
-				message = strings.TrimSpace(message)
-
-				_, err := conn.Write([]byte(message + "\n"))
-				if err != nil {
-					wErr := fmt.Errorf("trying to send message to server: %w", err)
-					c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
-					c.out <- "internal error"
-					continue
-				}
-
-				response, err := bufio.NewReader(conn).ReadString('\n')
-				if err != nil {
-					wErr := fmt.Errorf("trying to read response from server: %w", err)
-					c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
-					c.out <- "internal error"
-					continue
-				}
-
-				c.out <- response
-			case <-ctx.Done():
-				c.logger.WarnContext(ctx, "client stopped by canceled context", logAttrs...)
-				return
-			}
+		select {
+		case <-ctx.Done():
+			c.logger.WarnContext(ctx, "client stopped by canceled context", logAttrs...)
 
+			c.mu.Lock()
+			c.failLocked(errCanceledContext)
+			c.mu.Unlock()
+		case <-c.closed:
 		}
 	}()
 
+	if c.framed() {
+		go c.readFrames(ctx, reader)
+	} else {
+		go c.readReplies(ctx, reader)
+	}
+
 	return nil
 }
 
-func (c *Client) Call(ctx context.Context, message string) (string, error) {
+func (c *Client) framed() bool {
+	return c.opts.Protocol == ProtocolFramed
+}
+
+// handshake agrees on the version of the framed protocol, it is limited by
+// DialTimeout.
+func (c *Client) handshake(conn net.Conn, reader *bufio.Reader) error {
+	if c.opts.DialTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.opts.DialTimeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if err := writeHandshake(conn, frameVersion); err != nil {
+		return err
+	}
+
+	version, err := readHandshake(reader)
+	if err != nil {
+		return err
+	}
+	if version != frameVersion {
+		return fmt.Errorf("%w: %d", errUnsupportedFrameVersion, version)
+	}
+
+	return nil
+}
+
+// readReplies resolves pending futures in the order of their commands, a
+// broken connection fails all of them. Replies are read while the client is
+// idle too, so a connection closed by the server is noticed before reuse.
+func (c *Client) readReplies(ctx context.Context, reader *bufio.Reader) {
 	logAttrs := []any{
 		slog.String("component", "tcp_client"),
-		slog.String("method", "Call"),
+		slog.String("method", "readReplies"),
 	}
 
-	select {
-	case c.in <- message:
-	case <-ctx.Done():
-		c.logger.WarnContext(ctx, "client stopped by canceled context", logAttrs...)
-		return "", errCanceledContext
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			wErr := fmt.Errorf("trying to read response from server: %w", err)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+
+			c.mu.Lock()
+			c.failLocked(wErr)
+			c.mu.Unlock()
+			return
+		}
+
+		c.mu.Lock()
+		if len(c.pending) == 0 {
+			wErr := fmt.Errorf("%w: reply without a command", errInvalidReply)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			c.failLocked(wErr)
+			c.mu.Unlock()
+			return
+		}
+
+		future := c.pending[0]
+		c.pending = c.pending[1:]
+		c.mu.Unlock()
+
+		future.resolve(reply, nil)
 	}
+}
 
-	select {
-	case message := <-c.out:
-		return message, nil
-	case <-ctx.Done():
-		c.logger.WarnContext(ctx, "client stopped by canceled context", logAttrs...)
-		return "", errCanceledContext
+// readFrames resolves futures by IDs of replies, they may come in any order.
+func (c *Client) readFrames(ctx context.Context, reader *bufio.Reader) {
+	logAttrs := []any{
+		slog.String("component", "tcp_client"),
+		slog.String("method", "readFrames"),
 	}
+
+	for {
+		reply, err := readReplyFrame(reader)
+		if err != nil {
+			wErr := fmt.Errorf("trying to read response from server: %w", err)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+
+			c.mu.Lock()
+			c.failLocked(wErr)
+			c.mu.Unlock()
+			return
+		}
+
+		c.mu.Lock()
+		future, ok := c.inflight[reply.id]
+		delete(c.inflight, reply.id)
+		if !ok {
+			wErr := fmt.Errorf("%w: unknown request id %d", errInvalidFrame, reply.id)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			c.failLocked(wErr)
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+
+		future.resolve(reply.reply, nil)
+	}
+}
+
+// failLocked breaks the connection, commands which are not answered yet
+// get the error.
+func (c *Client) failLocked(err error) {
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	c.conn.Close()
+	close(c.closed)
+
+	for _, future := range c.pending {
+		future.resolve(nil, err)
+	}
+	c.pending = nil
+
+	for id, future := range c.inflight {
+		future.resolve(nil, err)
+		delete(c.inflight, id)
+	}
+}
+
+// Pipeline sends all commands at once without waiting for replies, the
+// futures get the replies of their commands.
+func (c *Client) Pipeline(ctx context.Context, commands ...string) ([]*Future, error) {
+	requests := make([][]string, 0, len(commands))
+	for _, command := range commands {
+		requests = append(requests, []string{strings.TrimSpace(command)})
+	}
+
+	return c.send(ctx, opQuery, requests)
+}
+
+// Transaction executes queries atomically, the reply is the one of EXEC.
+func (c *Client) Transaction(ctx context.Context, queries ...string) (*Future, error) {
+	if c.framed() {
+		futures, err := c.send(ctx, opTransaction, [][]string{queries})
+		if err != nil {
+			return nil, err
+		}
+
+		return futures[0], nil
+	}
+
+	commands := make([]string, 0, len(queries)+2)
+	commands = append(commands, commandMulti)
+	commands = append(commands, queries...)
+	commands = append(commands, commandExec)
+
+	futures, err := c.Pipeline(ctx, commands...)
+	if err != nil {
+		return nil, err
+	}
+
+	return futures[len(futures)-1], nil
+}
+
+// send writes requests, the line protocol supports only queries.
+func (c *Client) send(ctx context.Context, opcode byte, requests [][]string) ([]*Future, error) {
+	logAttrs := []any{
+		slog.String("component", "tcp_client"),
+		slog.String("method", "send"),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, errNotConnected
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	futures := make([]*Future, 0, len(requests))
+	ids := make([]uint32, 0, len(requests))
+	for _, args := range requests {
+		future := newFuture()
+		futures = append(futures, future)
+
+		if !c.framed() {
+			c.writer.WriteString(args[0] + "\n")
+			continue
+		}
+
+		c.nextID++
+		ids = append(ids, c.nextID)
+		if err := writeRequestFrame(c.writer, c.nextID, opcode, args); err != nil {
+			wErr := fmt.Errorf("trying to send message to server: %w", err)
+			c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			c.failLocked(wErr)
+
+			return nil, wErr
+		}
+	}
+
+	// replies may come as soon as requests are flushed
+	if c.framed() {
+		for i, id := range ids {
+			c.inflight[id] = futures[i]
+		}
+	}
+
+	err := c.writer.Flush()
+	if err != nil {
+		wErr := fmt.Errorf("trying to send message to server: %w", err)
+		c.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		c.failLocked(wErr)
+
+		return nil, wErr
+	}
+
+	if !c.framed() {
+		c.pending = append(c.pending, futures...)
+	}
+
+	return futures, nil
+}
+
+func (c *Client) Call(ctx context.Context, message string) (string, error) {
+	futures, err := c.Pipeline(ctx, message)
+	if err != nil {
+		return "", err
+	}
+
+	return futures[0].Wait(ctx)
+}
+
+// Close closes the connection, replies which are not read yet fail.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.failLocked(errClientClosed)
+	}
+}
+
+// broken tells if the connection is known to be broken or closed.
+func (c *Client) broken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err != nil
 }
 
 func (c *Client) getAddressToConnect() string {
@@ -1,12 +1,15 @@
 package tcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"log/slog"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClientEmptyLogger(t *testing.T) {
@@ -40,3 +43,55 @@ func TestRunWithInvalidAddress(t *testing.T) {
 
 	cancel()
 }
+
+func TestPipeline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// the server answers only after it got all commands
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for range 3 {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+		}
+
+		conn.Write([]byte("+OK\n$1\na\n_\n"))
+	}()
+
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+	client, err := NewClient(logger, &ClientOpts{
+		Server: "127.0.0.1",
+		Port:   listener.Addr().(*net.TCPAddr).Port,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = client.Pipeline(ctx, "GET a")
+	assert.ErrorIs(t, err, errNotConnected)
+
+	require.NoError(t, client.Run(ctx))
+
+	futures, err := client.Pipeline(ctx, "SET a a", "GET a", "GET b")
+	require.NoError(t, err)
+
+	for i, expected := range []string{"OK\n", "a\n", "(nil)\n"} {
+		response, err := futures[i].Wait(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, response)
+	}
+
+	// the server has closed the connection
+	_, err = client.Call(ctx, "GET a")
+	assert.Error(t, err)
+}
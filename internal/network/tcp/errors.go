@@ -1,6 +1,10 @@
 package tcp
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
 
 var (
 	errInvalidLogger            = errors.New("invalid logger")
@@ -8,4 +12,21 @@ var (
 	errCanceledContext          = errors.New("canceled context")
 	errTryingToRunServer        = errors.New("trying to run tcp server")
 	errTryingToAcceptConnection = errors.New("tryint to accept connection")
+	errInvalidRowsHeader        = errors.New("invalid rows header")
+	errInvalidReply             = errors.New("invalid reply")
+	errUnknownReply             = errors.New("unknown reply kind")
+	errInvalidRequest           = errors.New("invalid request")
+	errInvalidProtocol          = errors.New("invalid protocol")
+	errNotConnected             = errors.New("client is not connected")
+	errClientClosed             = errors.New("client is closed")
+	errInvalidPoolOpts          = errors.New("invalid pool options")
+	errInvalidFrame             = errors.New("invalid frame")
+	errUnsupportedFrameVersion  = errors.New("unsupported frame protocol version")
+
+	errUnsupportedProtocol = ports.NewClientError(ports.CodeInvalidArgument, "unsupported protocol version")
+	errAuthNotSupported    = ports.NewClientError(ports.CodeInvalidArgument, "authentication is not supported")
+	errHelloSyntax         = ports.NewClientError(ports.CodeSyntax, "syntax error in HELLO")
+	errUnknownOpcode       = ports.NewClientError(ports.CodeUnknownCommand, "unknown opcode")
+	errWrongFrameArguments = ports.NewClientError(ports.CodeWrongArguments, "wrong number of arguments in frame")
+	errNoSessions          = ports.NewClientError(ports.CodeTransaction, "transactions are not supported by the executor")
 )
@@ -0,0 +1,583 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"sync"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// The framed protocol is binary, so values may hold any bytes, and every
+// request has an ID. Requests of a connection are executed in order, only
+// replies of blocking commands which wait for data come later than the ones
+// of requests sent after them. A client starts with the magic followed by
+// the protocol version, the server echoes them back or answers with version
+// 0 when it does not support the version.
+//
+// Every frame is big endian:
+//
+//	length   uint32  size of the rest of the frame
+//	version  uint8
+//	id       uint32  request ID, the reply has the ID of its request
+//	opcode   uint8
+//	payload
+//
+// Requests carry arguments as a uint32 count followed by uint32 length
+// prefixed strings. Replies carry a typed reply: a kind byte followed by
+// a length prefixed string for simple and bulk strings, an int64 for
+// integers, the code and the message for errors and a uint32 count of
+// elements for arrays and maps.
+const (
+	frameVersion = 1
+
+	// opQuery has a single argument, a query like in the line protocol
+	opQuery byte = 0x01
+	// opCommand has the command name and its arguments
+	opCommand byte = 0x02
+	// opTransaction executes every argument as a query atomically
+	opTransaction byte = 0x03
+	opPing        byte = 0x04
+	opReply       byte = 0x80
+
+	frameHeaderLength = 1 + 4 + 1
+	maxFrameLength    = maxBulkLength + 1024*1024
+	// maxInFlightRequests bounds blocking requests of a connection waiting
+	// at once
+	maxInFlightRequests = 1024
+)
+
+// frameMagic starts with a zero byte, so it is not taken for a text command.
+var frameMagic = []byte{0x00, 'K', 'D', 'B'}
+
+type frame struct {
+	version byte
+	id      uint32
+	opcode  byte
+	// args of a request
+	args []string
+	// reply of a response
+	reply *ports.Result
+}
+
+// writeHandshake sends the magic and the version.
+func writeHandshake(w io.Writer, version byte) error {
+	_, err := w.Write(append(append([]byte{}, frameMagic...), version))
+
+	return err
+}
+
+// readHandshake reads the magic and returns the version.
+func readHandshake(reader io.Reader) (byte, error) {
+	data := make([]byte, len(frameMagic)+1)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(data[:len(frameMagic)], frameMagic) {
+		return 0, fmt.Errorf("%w: invalid magic %q", errInvalidFrame, data[:len(frameMagic)])
+	}
+
+	return data[len(frameMagic)], nil
+}
+
+func writeRequestFrame(writer *bufio.Writer, id uint32, opcode byte, args []string) error {
+	body := frameEncoder{}
+	body.header(id, opcode)
+	body.uint32(uint32(len(args)))
+	for _, arg := range args {
+		body.string(arg)
+	}
+
+	return body.writeTo(writer)
+}
+
+// writeReplyFrame encodes the reply, streamed rows are read to the end and
+// a failure of them is sent as an internal error.
+func writeReplyFrame(writer *bufio.Writer, id uint32, result *ports.Result) error {
+	body := frameEncoder{}
+	body.header(id, opReply)
+
+	rowsErr := body.reply(result)
+	if err := body.writeTo(writer); err != nil {
+		return err
+	}
+
+	return rowsErr
+}
+
+func readRequestFrame(reader *bufio.Reader) (frame, error) {
+	f, body, err := readFrame(reader)
+	if err != nil {
+		return frame{}, err
+	}
+
+	count, err := body.uint32()
+	if err != nil {
+		return frame{}, err
+	}
+	// every argument takes at least its length
+	if int(count) > body.Len()/4 {
+		return frame{}, fmt.Errorf("%w: %d arguments", errInvalidFrame, count)
+	}
+
+	f.args = make([]string, 0, count)
+	for range count {
+		arg, err := body.string()
+		if err != nil {
+			return frame{}, err
+		}
+
+		f.args = append(f.args, arg)
+	}
+
+	return f, body.end()
+}
+
+func readReplyFrame(reader *bufio.Reader) (frame, error) {
+	f, body, err := readFrame(reader)
+	if err != nil {
+		return frame{}, err
+	}
+
+	if f.opcode != opReply {
+		return frame{}, fmt.Errorf("%w: opcode %#x of a reply", errInvalidFrame, f.opcode)
+	}
+
+	f.reply, err = body.reply()
+	if err != nil {
+		return frame{}, err
+	}
+
+	return f, body.end()
+}
+
+func readFrame(reader *bufio.Reader) (frame, *frameDecoder, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return frame{}, nil, err
+	}
+
+	if length < frameHeaderLength || length > maxFrameLength {
+		return frame{}, nil, fmt.Errorf("%w: length %d", errInvalidFrame, length)
+	}
+
+	// the buffer grows with the data received, so a length alone does not
+	// allocate the maximum frame
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, reader, int64(length)); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return frame{}, nil, err
+	}
+	data := buf.Bytes()
+
+	f := frame{
+		version: data[0],
+		id:      binary.BigEndian.Uint32(data[1:5]),
+		opcode:  data[5],
+	}
+	if f.version != frameVersion {
+		return frame{}, nil, fmt.Errorf("%w: version %d", errInvalidFrame, f.version)
+	}
+
+	return f, &frameDecoder{Reader: bytes.NewReader(data[frameHeaderLength:])}, nil
+}
+
+type frameEncoder struct {
+	bytes.Buffer
+}
+
+func (e *frameEncoder) header(id uint32, opcode byte) {
+	e.WriteByte(frameVersion)
+	e.uint32(id)
+	e.WriteByte(opcode)
+}
+
+func (e *frameEncoder) uint32(n uint32) {
+	e.Write(binary.BigEndian.AppendUint32(nil, n))
+}
+
+func (e *frameEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.WriteString(s)
+}
+
+// reply encodes the result, the error of streamed rows is returned after
+// it is encoded as an error reply.
+func (e *frameEncoder) reply(result *ports.Result) error {
+	switch result.Kind {
+	case ports.KindNil:
+		e.WriteByte(byte(result.Kind))
+	case ports.KindSimple, ports.KindBulk:
+		e.WriteByte(byte(result.Kind))
+		e.string(result.Str)
+	case ports.KindInteger:
+		e.WriteByte(byte(result.Kind))
+		e.Write(binary.BigEndian.AppendUint64(nil, uint64(result.Int)))
+	case ports.KindError:
+		e.WriteByte(byte(result.Kind))
+		e.string(string(result.Code))
+		e.string(result.Str)
+	case ports.KindArray, ports.KindMap:
+		elems := result.Elems
+		if result.Rows != nil {
+			var err error
+			elems, err = readAllRows(result.Rows)
+			if err != nil {
+				e.reply(ports.Error(err))
+				return err
+			}
+		}
+
+		e.WriteByte(byte(result.Kind))
+		e.uint32(uint32(len(elems)))
+		for _, elem := range elems {
+			if err := e.reply(elem); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %d", errUnknownReply, result.Kind)
+	}
+
+	return nil
+}
+
+func (e *frameEncoder) writeTo(writer *bufio.Writer) error {
+	if e.Len() > maxFrameLength {
+		return fmt.Errorf("%w: length %d", errInvalidFrame, e.Len())
+	}
+
+	writer.Write(binary.BigEndian.AppendUint32(nil, uint32(e.Len())))
+	_, err := writer.Write(e.Bytes())
+
+	return err
+}
+
+type frameDecoder struct {
+	*bytes.Reader
+}
+
+func (d *frameDecoder) uint32() (uint32, error) {
+	var n uint32
+	if err := binary.Read(d, binary.BigEndian, &n); err != nil {
+		return 0, fmt.Errorf("%w: %w", errInvalidFrame, err)
+	}
+
+	return n, nil
+}
+
+func (d *frameDecoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+
+	if int64(n) > int64(d.Len()) {
+		return "", fmt.Errorf("%w: string of %d bytes", errInvalidFrame, n)
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(d, data); err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidFrame, err)
+	}
+
+	return string(data), nil
+}
+
+func (d *frameDecoder) reply() (*ports.Result, error) {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidFrame, err)
+	}
+
+	switch ports.Kind(kind) {
+	case ports.KindNil:
+		return ports.Nil(), nil
+	case ports.KindSimple, ports.KindBulk:
+		s, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ports.Result{Kind: ports.Kind(kind), Str: s}, nil
+	case ports.KindInteger:
+		var n int64
+		if err := binary.Read(d, binary.BigEndian, &n); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidFrame, err)
+		}
+
+		return ports.Integer(n), nil
+	case ports.KindError:
+		code, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		message, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+
+		return ports.Error(ports.NewClientError(ports.Code(code), message)), nil
+	case ports.KindArray, ports.KindMap:
+		count, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		// every element takes at least its kind
+		if int64(count) > int64(d.Len()) || count > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: %d elements", errInvalidFrame, count)
+		}
+
+		elems := make([]*ports.Result, 0, count)
+		for range count {
+			elem, err := d.reply()
+			if err != nil {
+				return nil, err
+			}
+
+			elems = append(elems, elem)
+		}
+
+		return &ports.Result{Kind: ports.Kind(kind), Elems: elems}, nil
+	default:
+		return nil, fmt.Errorf("%w: reply kind %d", errInvalidFrame, kind)
+	}
+}
+
+// end checks that the whole frame is read.
+func (d *frameDecoder) end() error {
+	if d.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", errInvalidFrame, d.Len())
+	}
+
+	return nil
+}
+
+// handleFramedConnection executes requests in order, blocking commands
+// which have to wait are left aside, so they do not hold back replies of
+// others. Transactions
+// have their own opcode as requests do not share a session.
+func (s *Server) handleFramedConnection(ctx context.Context, conn net.Conn, reader *bufio.Reader) error {
+	logAttrs := []any{
+		slog.String("component", "tcp_server"),
+		slog.String("method", "handleFramedConnection"),
+	}
+
+	version, err := readHandshake(reader)
+	if err != nil {
+		wErr := fmt.Errorf("trying to read handshake: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	if version != frameVersion {
+		writeHandshake(conn, 0)
+
+		wErr := fmt.Errorf("%w: %d", errUnsupportedFrameVersion, version)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	if err := writeHandshake(conn, frameVersion); err != nil {
+		wErr := fmt.Errorf("trying to response: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	// requests in flight are canceled when the client is gone
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	replies := &frameWriter{writer: bufio.NewWriter(conn)}
+	slots := make(chan struct{}, maxInFlightRequests)
+	for {
+		req, err := readRequestFrame(reader)
+		if err != nil {
+			wErr := fmt.Errorf("trying to read conn string: %w", err)
+			s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return wErr
+		}
+
+		if !isBlockingFrame(req) {
+			err := replies.write(req.id, s.executeFrame(ctx, req))
+			if err != nil {
+				wErr := fmt.Errorf("trying to response: %w", err)
+				s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+				return wErr
+			}
+
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return errCanceledContext
+		}
+
+		// the command runs in order until it has to wait, only then the
+		// requests after it are read
+		done := make(chan struct{})
+		blocked := make(chan struct{})
+		blockingCtx := ports.WithBlocking(ctx, sync.OnceFunc(func() { close(blocked) }))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer close(done)
+
+			result := s.executeFrame(blockingCtx, req)
+
+			err := replies.write(req.id, result)
+			if err != nil {
+				s.logger.ErrorContext(ctx, fmt.Errorf("trying to response: %w", err).Error(), logAttrs...)
+				// the reader stops on the closed connection
+				conn.Close()
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-blocked:
+		}
+	}
+}
+
+// isBlockingFrame reports whether the request may wait for data of other
+// clients.
+func isBlockingFrame(req frame) bool {
+	var command request
+	switch req.opcode {
+	case opQuery:
+		if len(req.args) != 1 {
+			return false
+		}
+		command = request{line: req.args[0]}
+	case opCommand:
+		command = request{args: req.args}
+	default:
+		return false
+	}
+
+	return blockingCommands[command.name()]
+}
+
+func (s *Server) executeFrame(ctx context.Context, req frame) *ports.Result {
+	logAttrs := []any{
+		slog.String("component", "tcp_server"),
+		slog.String("method", "executeFrame"),
+	}
+
+	var command request
+	switch req.opcode {
+	case opPing:
+		return ports.Simple(responsePong)
+	case opQuery:
+		if len(req.args) != 1 {
+			return ports.Error(errWrongFrameArguments)
+		}
+		command = request{line: req.args[0]}
+	case opCommand:
+		if len(req.args) == 0 {
+			return ports.Error(errWrongFrameArguments)
+		}
+		command = request{args: req.args}
+	case opTransaction:
+		return s.executeTransaction(ctx, req.args)
+	default:
+		return ports.Error(errUnknownOpcode.Detail("%#x", req.opcode))
+	}
+
+	if command.name() == commandPing && len(command.arguments()) == 0 {
+		return ports.Simple(responsePong)
+	}
+
+	query := command.command()
+	s.logger.InfoContext(ctx, fmt.Sprintf("Got message: %v", query), logAttrs...)
+
+	result, err := s.executor.Execute(ctx, query)
+	if err != nil {
+		wErr := fmt.Errorf("execute error: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return errorResponse(query, err)
+	}
+
+	return result
+}
+
+// executeTransaction executes queries between MULTI and EXEC in a new
+// session, the reply is the one of EXEC.
+func (s *Server) executeTransaction(ctx context.Context, queries []string) *ports.Result {
+	logAttrs := []any{
+		slog.String("component", "tcp_server"),
+		slog.String("method", "executeTransaction"),
+	}
+
+	provider, ok := s.executor.(ports.SessionProvider)
+	if !ok {
+		return ports.Error(errNoSessions)
+	}
+
+	session := provider.NewSession()
+	defer session.Close()
+
+	commands := make([]string, 0, len(queries)+2)
+	commands = append(commands, commandMulti)
+	commands = append(commands, queries...)
+	commands = append(commands, commandExec)
+
+	var result *ports.Result
+	for i, command := range commands {
+		var err error
+		result, err = session.Execute(ctx, command)
+		if err == nil {
+			continue
+		}
+
+		wErr := fmt.Errorf("execute error: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		result = errorResponse(command, err)
+
+		// a rejected command aborts the transaction, EXEC reports it
+		if i == 0 || i == len(commands)-1 {
+			return result
+		}
+	}
+
+	return result
+}
+
+// frameWriter writes replies of requests and blocking commands executed
+// aside.
+type frameWriter struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+}
+
+func (w *frameWriter) write(id uint32, result *ports.Result) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := writeReplyFrame(w.writer, id, result)
+	if flushErr := w.writer.Flush(); flushErr != nil {
+		return flushErr
+	}
+
+	return err
+}
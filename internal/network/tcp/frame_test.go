@@ -0,0 +1,275 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/network/tcp/mocks"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestWriteAndReadFrames(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := bufio.NewWriter(buf)
+
+	args := []string{"SET", "key", "a\nb\x00"}
+	require.NoError(t, writeRequestFrame(writer, 7, opCommand, args))
+
+	replies := []*ports.Result{
+		ports.Nil(),
+		ports.Simple("OK"),
+		ports.Bulk("a\r\nb"),
+		ports.Integer(-5),
+		ports.Error(ports.ErrWrongType),
+		ports.Array(ports.Bulk("a"), ports.Array(), ports.Nil()),
+		ports.Map(ports.Bulk("f"), ports.Integer(1)),
+	}
+	for i, reply := range replies {
+		require.NoError(t, writeReplyFrame(writer, uint32(i), reply))
+	}
+	require.NoError(t, writeReplyFrame(writer, 100, ports.Stream(&sliceRows{rows: []string{"x", "y"}})))
+	require.NoError(t, writer.Flush())
+
+	reader := bufio.NewReader(buf)
+	req, err := readRequestFrame(reader)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), req.id)
+	assert.Equal(t, opCommand, req.opcode)
+	assert.Equal(t, args, req.args)
+
+	for i, reply := range replies {
+		f, err := readReplyFrame(reader)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(i), f.id)
+		assert.Equal(t, renderReply(reply), renderReply(f.reply))
+		assert.Equal(t, reply.Kind, f.reply.Kind)
+	}
+
+	f, err := readReplyFrame(reader)
+	require.NoError(t, err)
+	assert.Equal(t, ports.BulkArray([]string{"x", "y"}), f.reply)
+
+	_, err = readReplyFrame(reader)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRowsFailureFrame(t *testing.T) {
+	rowsErr := errors.New("disk failure")
+	buf := new(bytes.Buffer)
+	writer := bufio.NewWriter(buf)
+
+	err := writeReplyFrame(writer, 1, ports.Stream(&sliceRows{rows: []string{"a"}, err: rowsErr}))
+	assert.ErrorIs(t, err, rowsErr)
+	require.NoError(t, writer.Flush())
+
+	f, err := readReplyFrame(bufio.NewReader(buf))
+	require.NoError(t, err)
+	assert.Equal(t, ports.KindError, f.reply.Kind)
+	assert.Equal(t, ports.CodeInternal, f.reply.Code)
+}
+
+func TestReadInvalidFrame(t *testing.T) {
+	frameOf := func(body ...byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...)
+	}
+
+	for _, input := range [][]byte{
+		frameOf(1, 0, 0),
+		frameOf(2, 0, 0, 0, 1, opQuery, 0, 0, 0, 0),
+		frameOf(1, 0, 0, 0, 1, opQuery, 0, 0, 0, 1, 0, 0, 0, 9, 'a'),
+		frameOf(1, 0, 0, 0, 1, opQuery, 0, 0, 0, 9),
+		frameOf(1, 0, 0, 0, 1, opQuery, 0, 0, 0, 0, 'x'),
+		binary.BigEndian.AppendUint32(nil, maxFrameLength+1),
+	} {
+		_, err := readRequestFrame(bufio.NewReader(bytes.NewReader(input)))
+		assert.ErrorIs(t, err, errInvalidFrame, input)
+	}
+}
+
+func TestReadTruncatedFrame(t *testing.T) {
+	input := append(binary.BigEndian.AppendUint32(nil, maxFrameLength), 1, 0, 0, 0, 1, opQuery)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readRequestFrame(bufio.NewReader(bytes.NewReader(input)))
+	runtime.ReadMemStats(&after)
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	// the length alone does not allocate the frame
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1024*1024))
+}
+
+func TestFramedConnectionOrder(t *testing.T) {
+	var mu sync.Mutex
+	var executed []string
+	record := func(query string) {
+		mu.Lock()
+		defer mu.Unlock()
+		executed = append(executed, query)
+	}
+
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, "SET key 1").RunAndReturn(func(_ context.Context, query string) (*ports.Result, error) {
+		time.Sleep(50 * time.Millisecond)
+		record(query)
+		return ports.Simple("OK"), nil
+	})
+	executor.EXPECT().Execute(mock.Anything, "GET key").RunAndReturn(func(_ context.Context, query string) (*ports.Result, error) {
+		record(query)
+		return ports.Bulk("1"), nil
+	})
+	executor.EXPECT().Execute(mock.Anything, "BLPOP list 0").RunAndReturn(func(_ context.Context, query string) (*ports.Result, error) {
+		time.Sleep(50 * time.Millisecond)
+		record(query)
+		return ports.BulkArray([]string{"list", "a"}), nil
+	})
+
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+	server, err := NewServer(executor, logger, &ServerOpts{})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		server.handleConnection(context.Background(), conn)
+	}()
+
+	client, err := NewClient(logger, &ClientOpts{
+		Server:   "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Protocol: ProtocolFramed,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Run(ctx))
+
+	// a blocking pop which does not have to wait keeps the order too
+	futures, err := client.Pipeline(ctx, "SET key 1", "BLPOP list 0", "GET key")
+	require.NoError(t, err)
+
+	reply, err := futures[2].Reply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ports.Bulk("1"), reply)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"SET key 1", "BLPOP list 0", "GET key"}, executed)
+}
+
+func TestFramedConnection(t *testing.T) {
+	release := make(chan struct{})
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, "BLPOP list 0").RunAndReturn(func(ctx context.Context, _ string) (*ports.Result, error) {
+		ports.Blocking(ctx)
+		<-release
+		return ports.BulkArray([]string{"list", "a"}), nil
+	})
+	executor.EXPECT().Execute(mock.Anything, "GET key").Return(ports.Bulk("two\nlines"), nil)
+	executor.EXPECT().Execute(mock.Anything, "NOPE").Return(nil, ports.NewClientError(ports.CodeUnknownCommand, "unknown command"))
+
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+	server, err := NewServer(executor, logger, &ServerOpts{})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		server.handleConnection(context.Background(), conn)
+	}()
+
+	client, err := NewClient(logger, &ClientOpts{
+		Server:   "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Protocol: ProtocolFramed,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Run(ctx))
+
+	futures, err := client.Pipeline(ctx, "BLPOP list 0", "GET key", "NOPE")
+	require.NoError(t, err)
+
+	// the blocked command does not hold back the others
+	reply, err := futures[1].Reply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ports.Bulk("two\nlines"), reply)
+
+	response, err := futures[2].Wait(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "(error) ERR_UNKNOWN_COMMAND unknown command\n", response)
+
+	select {
+	case <-futures[0].done:
+		t.Fatal("blocking command is answered before it is released")
+	default:
+	}
+
+	// the mock executor has no sessions
+	future, err := client.Transaction(ctx, "SET a 1")
+	require.NoError(t, err)
+	reply, err = future.Reply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ports.CodeTransaction, reply.Code)
+
+	close(release)
+	response, err = futures[0].Wait(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "list\na\n", response)
+
+	response, err = client.Call(ctx, "  ping ")
+	require.NoError(t, err)
+	assert.Equal(t, "PONG\n", response)
+}
+
+func TestFramedUnsupportedVersion(t *testing.T) {
+	server, err := NewServer(mocks.NewExecutor(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{})
+	require.NoError(t, err)
+
+	client, conn := net.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- server.handleConnection(context.Background(), conn)
+		conn.Close()
+	}()
+
+	go writeHandshake(client, frameVersion+1)
+
+	version, err := readHandshake(client)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), version)
+	assert.ErrorIs(t, <-done, errUnsupportedFrameVersion)
+}
@@ -4,7 +4,7 @@ package mocks
 
 import (
 	context "context"
-	ports "kdb/internal/ports"
+	ports "github.com/cat-go-dev/kdb/internal/ports"
 
 	mock "github.com/stretchr/testify/mock"
 )
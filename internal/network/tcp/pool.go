@@ -0,0 +1,419 @@
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Pool keeps connections to a server, a connection serves one call at a
+// time. Broken connections are dropped and opened again with exponential
+// backoff, so the pool outlives restarts of the server.
+type Pool struct {
+	logger *slog.Logger
+	opts   *PoolOpts
+
+	// ctx bounds the lifetime of connections, it is set by Run
+	ctx context.Context
+	// slots holds a token for every open connection or one being dialed
+	slots chan struct{}
+	idle  chan *Client
+}
+
+type PoolOpts struct {
+	Server string
+	Port   int
+	// MinConnections are kept open even when they are idle
+	MinConnections int
+	MaxConnections int
+	// HealthCheckInterval is how often idle connections are pinged
+	HealthCheckInterval time.Duration
+	DialTimeout         time.Duration
+	// MinBackoff and MaxBackoff bound delays between attempts to connect
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// CallTimeout limits calls with contexts without a deadline
+	CallTimeout time.Duration
+}
+
+const (
+	defaultPoolMinConnections  = 1
+	defaultPoolMaxConnections  = 10
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultDialTimeout         = 3 * time.Second
+	defaultMinBackoff          = 100 * time.Millisecond
+	defaultMaxBackoff          = 5 * time.Second
+	defaultCallTimeout         = 10 * time.Second
+
+	healthCheckTimeout = time.Second
+)
+
+func NewPool(logger *slog.Logger, opts *PoolOpts) (*Pool, error) {
+	if logger == nil {
+		return nil, errInvalidLogger
+	}
+
+	if opts.MinConnections == 0 {
+		opts.MinConnections = defaultPoolMinConnections
+	}
+	if opts.MaxConnections == 0 {
+		opts.MaxConnections = max(defaultPoolMaxConnections, opts.MinConnections)
+	}
+	if opts.HealthCheckInterval == 0 {
+		opts.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	if opts.MinBackoff == 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.MinBackoff)
+	}
+	if opts.CallTimeout == 0 {
+		opts.CallTimeout = defaultCallTimeout
+	}
+
+	if opts.MinConnections < 0 || opts.MaxConnections < opts.MinConnections {
+		return nil, fmt.Errorf("%w: connections from %d to %d", errInvalidPoolOpts, opts.MinConnections, opts.MaxConnections)
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		return nil, fmt.Errorf("%w: backoff from %s to %s", errInvalidPoolOpts, opts.MinBackoff, opts.MaxBackoff)
+	}
+
+	return &Pool{
+		logger: logger,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxConnections),
+		idle:   make(chan *Client, opts.MaxConnections),
+	}, nil
+}
+
+// Run opens MinConnections and keeps them healthy until ctx is done, it
+// fails when the server is not available.
+func (p *Pool) Run(ctx context.Context) error {
+	logAttrs := []any{
+		slog.String("component", "tcp_pool"),
+		slog.String("method", "Run"),
+	}
+
+	p.ctx = ctx
+
+	for range p.opts.MinConnections {
+		p.slots <- struct{}{}
+
+		client, err := p.dial()
+		if err != nil {
+			<-p.slots
+			wErr := fmt.Errorf("trying to open pool connection: %w", err)
+			p.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return wErr
+		}
+
+		p.idle <- client
+	}
+
+	p.logger.InfoContext(ctx, "tcp pool is started", logAttrs...)
+
+	go p.maintain(ctx)
+
+	return nil
+}
+
+func (p *Pool) Call(ctx context.Context, message string) (string, error) {
+	responses, err := p.Pipeline(ctx, message)
+	if err != nil {
+		return "", err
+	}
+
+	return responses[0], nil
+}
+
+// Pipeline is Do with replies rendered for humans.
+func (p *Pool) Pipeline(ctx context.Context, commands ...string) ([]string, error) {
+	replies, err := p.Do(ctx, commands...)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]string, 0, len(replies))
+	for _, reply := range replies {
+		responses = append(responses, renderReply(reply))
+	}
+
+	return responses, nil
+}
+
+// Do sends commands over one connection without waiting for replies, so
+// transactions have to be sent with it. Commands are sent again over a new
+// connection only when writing them to a reused one fails, once written they
+// may have been applied, so a lost reply fails the call.
+func (p *Pool) Do(ctx context.Context, commands ...string) ([]*ports.Result, error) {
+	logAttrs := []any{
+		slog.String("component", "tcp_pool"),
+		slog.String("method", "Do"),
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.CallTimeout)
+		defer cancel()
+	}
+
+	for retried := false; ; retried = true {
+		client, reused, err := p.acquire(ctx)
+		if err != nil {
+			wErr := fmt.Errorf("trying to get pool connection: %w", err)
+			p.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return nil, wErr
+		}
+
+		futures, err := client.Pipeline(ctx, commands...)
+		if err != nil {
+			p.discard(client)
+
+			if reused && !retried && ctx.Err() == nil {
+				p.logger.WarnContext(ctx, fmt.Sprintf("retrying on a new connection: %s", err), logAttrs...)
+				continue
+			}
+
+			wErr := fmt.Errorf("trying to send commands: %w", err)
+			p.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return nil, wErr
+		}
+
+		replies, err := waitReplies(ctx, futures)
+		if err != nil {
+			// the connection may still get replies of the failed call
+			p.discard(client)
+
+			wErr := fmt.Errorf("trying to call server: %w", err)
+			p.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+			return nil, wErr
+		}
+
+		p.release(client)
+		return replies, nil
+	}
+}
+
+func send(ctx context.Context, client *Client, commands []string) ([]*ports.Result, error) {
+	futures, err := client.Pipeline(ctx, commands...)
+	if err != nil {
+		return nil, err
+	}
+
+	return waitReplies(ctx, futures)
+}
+
+func waitReplies(ctx context.Context, futures []*Future) ([]*ports.Result, error) {
+	replies := make([]*ports.Result, 0, len(futures))
+	for _, future := range futures {
+		reply, err := future.Reply(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		replies = append(replies, reply)
+	}
+
+	return replies, nil
+}
+
+// acquire takes an idle connection or opens a new one while the pool has
+// free slots, otherwise it waits for a released connection.
+func (p *Pool) acquire(ctx context.Context) (*Client, bool, error) {
+	if p.ctx == nil {
+		return nil, false, errNotConnected
+	}
+	if p.ctx.Err() != nil {
+		return nil, false, errCanceledContext
+	}
+
+	for {
+		select {
+		case client := <-p.idle:
+			if client.broken() {
+				p.discard(client)
+				continue
+			}
+
+			return client, true, nil
+		default:
+		}
+
+		select {
+		case client := <-p.idle:
+			if client.broken() {
+				p.discard(client)
+				continue
+			}
+
+			return client, true, nil
+		case p.slots <- struct{}{}:
+			client, err := p.connect(ctx)
+			if err != nil {
+				<-p.slots
+				return nil, false, err
+			}
+
+			return client, false, nil
+		case <-ctx.Done():
+			return nil, false, errCanceledContext
+		}
+	}
+}
+
+func (p *Pool) release(client *Client) {
+	if p.ctx.Err() != nil || client.broken() {
+		p.discard(client)
+		return
+	}
+
+	p.idle <- client
+}
+
+func (p *Pool) discard(client *Client) {
+	client.Close()
+	<-p.slots
+}
+
+// connect dials until it succeeds or ctx is done, the delay between
+// attempts grows exponentially with jitter, so restarted servers are not
+// flooded by all clients at once.
+func (p *Pool) connect(ctx context.Context) (*Client, error) {
+	backoff := p.opts.MinBackoff
+	for {
+		client, err := p.dial()
+		if err == nil {
+			return client, nil
+		}
+
+		timer := time.NewTimer(jitter(backoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", errCanceledContext, err)
+		}
+
+		backoff = min(backoff*2, p.opts.MaxBackoff)
+	}
+}
+
+func (p *Pool) dial() (*Client, error) {
+	client, err := NewClient(p.logger, &ClientOpts{
+		Server:      p.opts.Server,
+		Port:        p.opts.Port,
+		DialTimeout: p.opts.DialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the connection lives as long as the pool, not the call opening it
+	err = client.Run(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// jitter picks a delay from the upper half of backoff.
+func jitter(backoff time.Duration) time.Duration {
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// maintain pings idle connections and opens new ones while there are less
+// than MinConnections.
+func (p *Pool) maintain(ctx context.Context) {
+	logAttrs := []any{
+		slog.String("component", "tcp_pool"),
+		slog.String("method", "maintain"),
+	}
+
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.checkIdle(ctx)
+			p.fill(ctx)
+		case <-ctx.Done():
+			p.logger.WarnContext(ctx, "pool stopped by canceled context", logAttrs...)
+			p.closeIdle()
+			return
+		}
+	}
+}
+
+func (p *Pool) checkIdle(ctx context.Context) {
+	logAttrs := []any{
+		slog.String("component", "tcp_pool"),
+		slog.String("method", "checkIdle"),
+	}
+
+	for range len(p.idle) {
+		var client *Client
+		select {
+		case client = <-p.idle:
+		default:
+			return
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		_, err := send(pingCtx, client, []string{commandPing})
+		cancel()
+		if err != nil {
+			p.logger.WarnContext(ctx, fmt.Sprintf("dropping unhealthy connection: %s", err), logAttrs...)
+			p.discard(client)
+			continue
+		}
+
+		p.release(client)
+	}
+}
+
+func (p *Pool) fill(ctx context.Context) {
+	logAttrs := []any{
+		slog.String("component", "tcp_pool"),
+		slog.String("method", "fill"),
+	}
+
+	for len(p.slots) < p.opts.MinConnections {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		client, err := p.connect(ctx)
+		if err != nil {
+			<-p.slots
+			p.logger.ErrorContext(ctx, fmt.Errorf("trying to open pool connection: %w", err).Error(), logAttrs...)
+			return
+		}
+
+		p.idle <- client
+	}
+}
+
+func (p *Pool) closeIdle() {
+	for {
+		select {
+		case client := <-p.idle:
+			p.discard(client)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers every command with OK, dropConns simulates a restart.
+type fakeServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+
+			go func() {
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := reader.ReadString('\n'); err != nil {
+						return
+					}
+					conn.Write([]byte("+OK\n"))
+				}
+			}()
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeServer) dropConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func newTestPool(t *testing.T, opts *PoolOpts) *Pool {
+	pool, err := NewPool(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), opts)
+	require.NoError(t, err)
+
+	return pool
+}
+
+// waitBroken waits until the idle connection notices it is closed.
+func waitBroken(t *testing.T, pool *Pool) {
+	t.Helper()
+
+	client := <-pool.idle
+	assert.Eventually(t, client.broken, time.Second, 10*time.Millisecond)
+	pool.idle <- client
+}
+
+func TestNewPoolInvalidOpts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	_, err := NewPool(nil, &PoolOpts{})
+	assert.ErrorIs(t, err, errInvalidLogger)
+
+	_, err = NewPool(logger, &PoolOpts{MinConnections: 5, MaxConnections: 2})
+	assert.ErrorIs(t, err, errInvalidPoolOpts)
+
+	_, err = NewPool(logger, &PoolOpts{MinBackoff: time.Second, MaxBackoff: time.Millisecond})
+	assert.ErrorIs(t, err, errInvalidPoolOpts)
+}
+
+func TestPoolReconnect(t *testing.T) {
+	server := newFakeServer(t)
+	pool := newTestPool(t, &PoolOpts{Server: "127.0.0.1", Port: server.port(), MaxConnections: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := pool.Call(ctx, "GET a")
+	assert.ErrorIs(t, err, errNotConnected)
+
+	require.NoError(t, pool.Run(ctx))
+
+	response, err := pool.Call(ctx, "SET a 1")
+	require.NoError(t, err)
+	assert.Equal(t, "OK\n", response)
+
+	// the idle connection is broken by the restart
+	server.dropConns()
+	waitBroken(t, pool)
+
+	responses, err := pool.Pipeline(ctx, "GET a", "GET b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OK\n", "OK\n"}, responses)
+}
+
+func TestPoolRetriesFailedWrites(t *testing.T) {
+	server := newFakeServer(t)
+	pool := newTestPool(t, &PoolOpts{Server: "127.0.0.1", Port: server.port(), MaxConnections: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, pool.Run(ctx))
+
+	_, err := pool.Call(ctx, "GET a")
+	require.NoError(t, err)
+
+	// writes to the reused connection fail
+	client := <-pool.idle
+	client.conn.Close()
+	pool.idle <- client
+
+	response, err := pool.Call(ctx, "GET a")
+	require.NoError(t, err)
+	assert.Equal(t, "OK\n", response)
+}
+
+func TestPoolDoesNotResendWrittenCommands(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// INCR is applied but the connection breaks before the reply
+	var mu sync.Mutex
+	var incrs int
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "INCR") {
+						mu.Lock()
+						incrs++
+						mu.Unlock()
+						return
+					}
+					conn.Write([]byte("+OK\n"))
+				}
+			}()
+		}
+	}()
+
+	pool := newTestPool(t, &PoolOpts{
+		Server:         "127.0.0.1",
+		Port:           listener.Addr().(*net.TCPAddr).Port,
+		MaxConnections: 1,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, pool.Run(ctx))
+
+	_, err = pool.Call(ctx, "GET a")
+	require.NoError(t, err)
+
+	_, err = pool.Call(ctx, "INCR a")
+	assert.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, incrs)
+}
+
+func TestPoolMaxConnections(t *testing.T) {
+	server := newFakeServer(t)
+	pool := newTestPool(t, &PoolOpts{Server: "127.0.0.1", Port: server.port(), MaxConnections: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, pool.Run(ctx))
+
+	client, _, err := pool.acquire(ctx)
+	require.NoError(t, err)
+
+	callCtx, callCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer callCancel()
+
+	_, err = pool.Call(callCtx, "GET a")
+	assert.ErrorIs(t, err, errCanceledContext)
+
+	pool.release(client)
+
+	_, err = pool.Call(ctx, "GET a")
+	assert.NoError(t, err)
+}
+
+func TestPoolCallDeadline(t *testing.T) {
+	server := newFakeServer(t)
+	pool := newTestPool(t, &PoolOpts{
+		Server:     "127.0.0.1",
+		Port:       server.port(),
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, pool.Run(ctx))
+
+	// the server is gone, connecting is retried until the deadline
+	server.listener.Close()
+	server.dropConns()
+	waitBroken(t, pool)
+
+	callCtx, callCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer callCancel()
+
+	start := time.Now()
+	_, err := pool.Call(callCtx, "GET a")
+	assert.ErrorIs(t, err, errCanceledContext)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		delay := jitter(100 * time.Millisecond)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 100*time.Millisecond)
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	server := newFakeServer(t)
+	pool := newTestPool(t, &PoolOpts{
+		Server:              "127.0.0.1",
+		Port:                server.port(),
+		HealthCheckInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, pool.Run(ctx))
+
+	server.dropConns()
+
+	// the broken connection is replaced without any call
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		return len(server.conns) == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,306 @@
+package tcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Every reply starts with a line telling its type:
+//
+//	+OK         simple string
+//	$5\nvalue   bulk string of 5 bytes followed by a newline
+//	:1          integer
+//	_           nil
+//	-CODE msg   error with its code like ERR_WRONGTYPE
+//	*<n>        chunk of n array elements
+//
+// Arrays are streamed in chunks and "*0" ends them, so the size of an array
+// does not have to be known in advance. A failure in the middle of the
+// stream is sent as a "*-1" header followed by an error reply.
+const (
+	simplePrefix  = '+'
+	bulkPrefix    = '$'
+	integerPrefix = ':'
+	nilPrefix     = '_'
+	errorPrefix   = '-'
+	arrayPrefix   = '*'
+
+	rowsChunkSize = 100
+	rowsEnd       = "*0"
+	rowsFailed    = "*-1"
+
+	emptyRowsResponse = "(empty)"
+	nilResponse       = "(nil)"
+	integerResponse   = "(integer) "
+	failedResponse    = "(error) "
+)
+
+// readLineRequest reads a command line.
+func readLineRequest(reader *bufio.Reader) (request, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return request{}, err
+	}
+
+	return request{line: line}, nil
+}
+
+// writeReply encodes the reply, streamed rows are closed.
+func writeReply(w io.Writer, result *ports.Result) error {
+	writer := bufio.NewWriter(w)
+	if err := encodeReply(writer, result); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func encodeReply(writer *bufio.Writer, result *ports.Result) error {
+	switch result.Kind {
+	case ports.KindNil:
+		writer.WriteString(string(nilPrefix) + "\n")
+	case ports.KindSimple:
+		writer.WriteString(string(simplePrefix) + result.Str + "\n")
+	case ports.KindBulk:
+		fmt.Fprintf(writer, "%c%d\n%s\n", bulkPrefix, len(result.Str), result.Str)
+	case ports.KindInteger:
+		fmt.Fprintf(writer, "%c%d\n", integerPrefix, result.Int)
+	case ports.KindError:
+		// messages are single lines
+		message := strings.ReplaceAll(result.Str, "\n", " ")
+		fmt.Fprintf(writer, "%c%s %s\n", errorPrefix, result.Code, message)
+	case ports.KindArray, ports.KindMap:
+		if result.Rows != nil {
+			return writeRows(writer, result.Rows)
+		}
+
+		return writeElems(writer, result.Elems)
+	default:
+		return fmt.Errorf("%w: %d", errUnknownReply, result.Kind)
+	}
+
+	return nil
+}
+
+func writeElems(writer *bufio.Writer, elems []*ports.Result) error {
+	for _, chunk := range chunks(elems) {
+		fmt.Fprintf(writer, "%c%d\n", arrayPrefix, len(chunk))
+		for _, elem := range chunk {
+			if err := encodeReply(writer, elem); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.WriteString(rowsEnd + "\n")
+
+	return nil
+}
+
+// writeRows streams rows as bulk strings and closes them, every chunk is
+// flushed so big replies are not kept in memory.
+func writeRows(writer *bufio.Writer, rows ports.Rows) error {
+	defer rows.Close()
+
+	chunk := make([]string, 0, rowsChunkSize)
+
+	writeChunk := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		fmt.Fprintf(writer, "%c%d\n", arrayPrefix, len(chunk))
+		for _, row := range chunk {
+			encodeReply(writer, ports.Bulk(row))
+		}
+		chunk = chunk[:0]
+
+		return writer.Flush()
+	}
+
+	for rows.Next() {
+		chunk = append(chunk, rows.Row())
+		if len(chunk) == rowsChunkSize {
+			if err := writeChunk(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeChunk(); err != nil {
+		return err
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(writer, "%s\n%c%s An error while reading rows\n", rowsFailed, errorPrefix, ports.CodeInternal)
+		if flushErr := writer.Flush(); flushErr != nil {
+			return flushErr
+		}
+
+		return err
+	}
+
+	writer.WriteString(rowsEnd + "\n")
+
+	return nil
+}
+
+// chunks splits elems into chunks of rowsChunkSize.
+func chunks(elems []*ports.Result) [][]*ports.Result {
+	var split [][]*ports.Result
+	for len(elems) > 0 {
+		n := min(len(elems), rowsChunkSize)
+		split = append(split, elems[:n])
+		elems = elems[n:]
+	}
+
+	return split
+}
+
+// readResponse reads a single reply and renders it for humans like
+// redis-cli, every element of an array is a line and an empty array is
+// "(empty)".
+func readResponse(reader *bufio.Reader) (string, error) {
+	reply, err := readReply(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return renderReply(reply), nil
+}
+
+// readReply reads a single typed reply, a stream failed in the middle is an
+// array of the rows read before followed by the error.
+func readReply(reader *bufio.Reader) (*ports.Result, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty line", errInvalidReply)
+	}
+
+	payload := line[1:]
+	switch line[0] {
+	case nilPrefix:
+		return ports.Nil(), nil
+	case simplePrefix:
+		return ports.Simple(payload), nil
+	case integerPrefix:
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errInvalidReply, line)
+		}
+
+		return ports.Integer(n), nil
+	case errorPrefix:
+		code, message, _ := strings.Cut(payload, " ")
+
+		return ports.Error(ports.NewClientError(ports.Code(code), message)), nil
+	case bulkPrefix:
+		size, err := strconv.Atoi(payload)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("%w: %q", errInvalidReply, line)
+		}
+
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		return ports.Bulk(string(data[:size])), nil
+	case arrayPrefix:
+		return readArray(reader, line)
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidReply, line)
+	}
+}
+
+func readArray(reader *bufio.Reader, header string) (*ports.Result, error) {
+	var elems []*ports.Result
+	for {
+		count, ok := parseRowsHeader(header)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errInvalidRowsHeader, header)
+		}
+
+		if count == 0 {
+			break
+		}
+
+		if count < 0 {
+			// the error reply follows the failed header
+			failure, err := readReply(reader)
+			if err != nil {
+				return nil, err
+			}
+
+			return ports.Array(append(elems, failure)...), nil
+		}
+
+		for range count {
+			elem, err := readReply(reader)
+			if err != nil {
+				return nil, err
+			}
+
+			elems = append(elems, elem)
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		header = strings.TrimRight(line, "\r\n")
+	}
+
+	return ports.Array(elems...), nil
+}
+
+func renderReply(reply *ports.Result) string {
+	var b strings.Builder
+	render(reply, &b)
+
+	return b.String()
+}
+
+func render(reply *ports.Result, b *strings.Builder) {
+	switch reply.Kind {
+	case ports.KindNil:
+		b.WriteString(nilResponse + "\n")
+	case ports.KindInteger:
+		b.WriteString(integerResponse + strconv.FormatInt(reply.Int, 10) + "\n")
+	case ports.KindError:
+		b.WriteString(failedResponse + string(reply.Code) + " " + reply.Str + "\n")
+	case ports.KindArray, ports.KindMap:
+		if len(reply.Elems) == 0 {
+			b.WriteString(emptyRowsResponse + "\n")
+		}
+
+		for _, elem := range reply.Elems {
+			render(elem, b)
+		}
+	default:
+		b.WriteString(reply.Str + "\n")
+	}
+}
+
+func parseRowsHeader(line string) (int, bool) {
+	if len(line) == 0 || line[0] != arrayPrefix {
+		return 0, false
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < -1 {
+		return 0, false
+	}
+
+	return count, true
+}
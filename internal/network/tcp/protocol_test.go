@@ -0,0 +1,131 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+type sliceRows struct {
+	rows   []string
+	pos    int
+	err    error
+	closed bool
+}
+
+func (r *sliceRows) Next() bool {
+	if r.pos == len(r.rows) {
+		return false
+	}
+	r.pos++
+
+	return true
+}
+
+func (r *sliceRows) Row() string {
+	return r.rows[r.pos-1]
+}
+
+func (r *sliceRows) Err() error {
+	return r.err
+}
+
+func (r *sliceRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestWriteAndReadRows(t *testing.T) {
+	rows := &sliceRows{}
+	for i := range 2*rowsChunkSize + 1 {
+		rows.rows = append(rows.rows, fmt.Sprintf("key%d value%d", i, i))
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeReply(buf, ports.Stream(rows)))
+	assert.True(t, rows.closed)
+	assert.Equal(t, 3, strings.Count(buf.String(), "*")-1)
+
+	// the next reply must stay unread
+	buf.WriteString("+OK\n")
+	reader := bufio.NewReader(buf)
+
+	response, err := readResponse(reader)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(rows.rows, "\n")+"\n", response)
+
+	response, err = readResponse(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "OK\n", response)
+}
+
+func TestReadEmptyRows(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeReply(buf, ports.Stream(&sliceRows{})))
+	assert.Equal(t, rowsEnd+"\n", buf.String())
+
+	response, err := readResponse(bufio.NewReader(buf))
+	require.NoError(t, err)
+	assert.Equal(t, emptyRowsResponse+"\n", response)
+}
+
+func TestRowsFailure(t *testing.T) {
+	rowsErr := errors.New("disk failure")
+	rows := &sliceRows{rows: []string{"a 1"}, err: rowsErr}
+
+	buf := new(bytes.Buffer)
+	assert.ErrorIs(t, writeReply(buf, ports.Stream(rows)), rowsErr)
+
+	response, err := readResponse(bufio.NewReader(buf))
+	require.NoError(t, err)
+	assert.Equal(t, "a 1\n(error) ERR_INTERNAL An error while reading rows\n", response)
+}
+
+func TestWriteAndReadReplies(t *testing.T) {
+	tests := []struct {
+		reply    *ports.Result
+		expected string
+	}{
+		{reply: ports.Nil(), expected: "(nil)\n"},
+		{reply: ports.Bulk(""), expected: "\n"},
+		{reply: ports.Bulk("*5"), expected: "*5\n"},
+		{reply: ports.Bulk("two\nlines"), expected: "two\nlines\n"},
+		{reply: ports.Simple("OK"), expected: "OK\n"},
+		{reply: ports.Integer(-2), expected: "(integer) -2\n"},
+		{reply: ports.Error(ports.ErrWrongType), expected: "(error) ERR_WRONGTYPE Operation against a key holding the wrong kind of value\n"},
+		{reply: ports.Error(errors.New("disk failure")), expected: "(error) ERR_INTERNAL internal error\n"},
+		{reply: ports.Array(), expected: "(empty)\n"},
+		{
+			reply:    ports.Array(ports.Bulk("0"), ports.Array(), ports.Nil(), ports.BulkArray([]string{"a", "b"})),
+			expected: "0\n(empty)\n(nil)\na\nb\n",
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	for _, tt := range tests {
+		require.NoError(t, writeReply(buf, tt.reply))
+	}
+
+	// replies are read one by one from the same stream
+	reader := bufio.NewReader(buf)
+	for _, tt := range tests {
+		response, err := readResponse(reader)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, response)
+	}
+}
+
+func TestReadInvalidReply(t *testing.T) {
+	for _, line := range []string{"\n", "?\n", "$x\n", "*1\n+a\nb\n"} {
+		_, err := readResponse(bufio.NewReader(strings.NewReader(line)))
+		assert.Error(t, err, line)
+	}
+}
@@ -0,0 +1,309 @@
+package tcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// RESP is the redis serialization protocol, requests are either inline
+// command lines or arrays of bulk strings. RESP2 is used until a client
+// switches to RESP3 with HELLO 3.
+const (
+	resp2 = 2
+	resp3 = 3
+
+	respMultiBulkPrefix = '*'
+
+	// limits protect the server from huge allocations, they are the same
+	// as in redis
+	maxMultiBulkLength = 1024 * 1024
+	maxBulkLength      = 512 * 1024 * 1024
+)
+
+// request is a command read from a connection.
+type request struct {
+	// line is a command of the line protocol or an inline RESP command
+	line string
+	// args are the arguments of a RESP multi-bulk request
+	args []string
+	resp bool
+	// more is set when the client has sent the next request already
+	more bool
+}
+
+// command formats the request for the executor, arguments are quoted so
+// the query tokenizer gets them back as they are.
+func (r request) command() string {
+	if r.args == nil {
+		return strings.TrimSpace(r.line)
+	}
+
+	return FormatCommand(r.args...)
+}
+
+// FormatCommand quotes every argument, so commands keep spaces, quotes and
+// newlines of values.
+func FormatCommand(args ...string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quoteArg(arg))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// name is the command name in upper case.
+func (r request) name() string {
+	if r.args != nil {
+		if len(r.args) == 0 {
+			return ""
+		}
+
+		return strings.ToUpper(r.args[0])
+	}
+
+	fields := strings.Fields(r.line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
+
+// arguments lists the arguments after the command name, inline commands are
+// split by whitespace.
+func (r request) arguments() []string {
+	args := r.args
+	if args == nil {
+		args = strings.Fields(r.line)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	return args[1:]
+}
+
+func quoteArg(arg string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// readRESPRequest reads an inline command or a multi-bulk request.
+func readRESPRequest(reader *bufio.Reader) (request, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return request{}, err
+	}
+
+	if first[0] != respMultiBulkPrefix {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return request{}, err
+		}
+
+		return request{line: line, resp: true}, nil
+	}
+
+	count, err := readRESPLength(reader, respMultiBulkPrefix, maxMultiBulkLength)
+	if err != nil {
+		return request{}, err
+	}
+
+	args := make([]string, 0, count)
+	for range count {
+		size, err := readRESPLength(reader, bulkPrefix, maxBulkLength)
+		if err != nil {
+			return request{}, err
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return request{}, err
+		}
+		if string(data[size:]) != "\r\n" {
+			return request{}, fmt.Errorf("%w: bulk string is not terminated", errInvalidRequest)
+		}
+
+		args = append(args, string(data[:size]))
+	}
+
+	return request{args: args, resp: true}, nil
+}
+
+func readRESPLength(reader *bufio.Reader, prefix byte, limit int) (int, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 || line[0] != prefix {
+		return 0, fmt.Errorf("%w: expected '%c', got %q", errInvalidRequest, prefix, line)
+	}
+
+	length, err := strconv.Atoi(line[1:])
+	if err != nil || length < 0 || length > limit {
+		return 0, fmt.Errorf("%w: invalid length %q", errInvalidRequest, line)
+	}
+
+	return length, nil
+}
+
+// writeRESPReply encodes the reply for the protocol version, streamed rows
+// are read to the end since RESP arrays start with their length.
+func writeRESPReply(w io.Writer, result *ports.Result, version int) error {
+	writer := bufio.NewWriter(w)
+	if err := encodeRESP(writer, result, version); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func encodeRESP(writer *bufio.Writer, result *ports.Result, version int) error {
+	switch result.Kind {
+	case ports.KindNil:
+		switch {
+		case version == resp3:
+			writer.WriteString("_\r\n")
+		case result.Array:
+			writer.WriteString("*-1\r\n")
+		default:
+			writer.WriteString("$-1\r\n")
+		}
+	case ports.KindSimple:
+		writer.WriteString("+" + singleLine(result.Str) + "\r\n")
+	case ports.KindBulk:
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(result.Str), result.Str)
+	case ports.KindInteger:
+		fmt.Fprintf(writer, ":%d\r\n", result.Int)
+	case ports.KindError:
+		fmt.Fprintf(writer, "-%s %s\r\n", respErrorPrefix(result.Code), singleLine(result.Str))
+	case ports.KindArray, ports.KindMap:
+		elems := result.Elems
+		if result.Rows != nil {
+			var err error
+			elems, err = readAllRows(result.Rows)
+			if err != nil {
+				fmt.Fprintf(writer, "-%s An error while reading rows\r\n", respErrorPrefix(ports.CodeInternal))
+				writer.Flush()
+
+				return err
+			}
+		}
+
+		if result.Kind == ports.KindMap && version == resp3 {
+			fmt.Fprintf(writer, "%%%d\r\n", len(elems)/2)
+		} else {
+			fmt.Fprintf(writer, "*%d\r\n", len(elems))
+		}
+
+		for _, elem := range elems {
+			if err := encodeRESP(writer, elem, version); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %d", errUnknownReply, result.Kind)
+	}
+
+	return nil
+}
+
+// respErrorPrefixes are the prefixes redis gives to errors of the same
+// kind, other errors start with ERR like in redis.
+var respErrorPrefixes = map[ports.Code]string{
+	ports.CodeWrongType:   "WRONGTYPE",
+	ports.CodeExecAborted: "EXECABORT",
+	ports.CodeOutOfMemory: "OOM",
+	ports.CodeReadOnly:    "READONLY",
+	ports.CodeBusy:        "BUSY",
+}
+
+func respErrorPrefix(code ports.Code) string {
+	if prefix, ok := respErrorPrefixes[code]; ok {
+		return prefix
+	}
+
+	return "ERR"
+}
+
+func readAllRows(rows ports.Rows) ([]*ports.Result, error) {
+	defer rows.Close()
+
+	var elems []*ports.Result
+	for rows.Next() {
+		elems = append(elems, ports.Bulk(rows.Row()))
+	}
+
+	return elems, rows.Err()
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+const (
+	serverName    = "kdb"
+	serverVersion = "1.0.0"
+
+	optionAuth    = "AUTH"
+	optionSetName = "SETNAME"
+)
+
+// hello handles "HELLO [protover [SETNAME name]]", it switches the protocol
+// version of the connection and describes the server.
+func hello(args []string, version int) (*ports.Result, int) {
+	if len(args) > 0 {
+		proto, err := strconv.Atoi(args[0])
+		if err != nil || (proto != resp2 && proto != resp3) {
+			return ports.Error(errUnsupportedProtocol), version
+		}
+
+		for i := 1; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case optionAuth:
+				return ports.Error(errAuthNotSupported), version
+			case optionSetName:
+				// names of connections are not kept
+				i++
+				if i == len(args) {
+					return ports.Error(errHelloSyntax), version
+				}
+			default:
+				return ports.Error(errHelloSyntax), version
+			}
+		}
+
+		version = proto
+	}
+
+	return ports.Map(
+		ports.Bulk("server"), ports.Bulk(serverName),
+		ports.Bulk("version"), ports.Bulk(serverVersion),
+		ports.Bulk("proto"), ports.Integer(int64(version)),
+		ports.Bulk("mode"), ports.Bulk("standalone"),
+		ports.Bulk("role"), ports.Bulk("master"),
+	), version
+}
@@ -0,0 +1,155 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/network/tcp/mocks"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+func TestReadRESPRequest(t *testing.T) {
+	input := "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$6\r\na \"b\"\n\r\n" + "GET key\r\n" + "*1\r\n$0\r\n\r\n"
+	reader := bufio.NewReader(strings.NewReader(input))
+
+	req, err := readRESPRequest(reader)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SET", "key", "a \"b\"\n"}, req.args)
+	assert.Equal(t, `"SET" "key" "a \"b\"\x0a"`, req.command())
+
+	req, err = readRESPRequest(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "GET key", req.command())
+	assert.Equal(t, "GET", req.name())
+	assert.Equal(t, []string{"key"}, req.arguments())
+
+	req, err = readRESPRequest(reader)
+	require.NoError(t, err)
+	assert.Equal(t, `""`, req.command())
+
+	_, err = readRESPRequest(reader)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadInvalidRESPRequest(t *testing.T) {
+	for _, input := range []string{
+		"*x\r\n",
+		"*-1\r\n",
+		"*1\r\n+GET\r\n",
+		"*1\r\n$3\r\nGETX\r\n",
+		"*1\r\n$1048576000\r\n",
+	} {
+		_, err := readRESPRequest(bufio.NewReader(strings.NewReader(input)))
+		assert.ErrorIs(t, err, errInvalidRequest, input)
+	}
+}
+
+func TestWriteRESPReply(t *testing.T) {
+	tests := []struct {
+		reply *ports.Result
+		resp2 string
+		resp3 string
+	}{
+		{reply: ports.Nil(), resp2: "$-1\r\n", resp3: "_\r\n"},
+		{reply: ports.NilArray(), resp2: "*-1\r\n", resp3: "_\r\n"},
+		{reply: ports.Simple("OK"), resp2: "+OK\r\n"},
+		{reply: ports.Bulk("a\r\nb"), resp2: "$4\r\na\r\nb\r\n"},
+		{reply: ports.Integer(-1), resp2: ":-1\r\n"},
+		{reply: ports.Error(ports.ErrWrongType), resp2: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{reply: ports.Error(ports.ErrNotInteger), resp2: "-ERR " + ports.ErrNotInteger.Message + "\r\n"},
+		{reply: ports.Array(ports.Bulk("a"), ports.Nil()), resp2: "*2\r\n$1\r\na\r\n$-1\r\n", resp3: "*2\r\n$1\r\na\r\n_\r\n"},
+		{reply: ports.Map(ports.Bulk("f"), ports.Integer(1)), resp2: "*2\r\n$1\r\nf\r\n:1\r\n", resp3: "%1\r\n$1\r\nf\r\n:1\r\n"},
+		{reply: ports.Stream(&sliceRows{rows: []string{"a", "b"}}), resp2: "*2\r\n$1\r\na\r\n$1\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		require.NoError(t, writeRESPReply(buf, tt.reply, resp2))
+		assert.Equal(t, tt.resp2, buf.String())
+
+		if tt.resp3 == "" || tt.reply.Rows != nil {
+			continue
+		}
+
+		buf.Reset()
+		require.NoError(t, writeRESPReply(buf, tt.reply, resp3))
+		assert.Equal(t, tt.resp3, buf.String())
+	}
+
+	rowsErr := errors.New("disk failure")
+	buf := new(bytes.Buffer)
+	err := writeRESPReply(buf, ports.Stream(&sliceRows{rows: []string{"a"}, err: rowsErr}), resp2)
+	assert.ErrorIs(t, err, rowsErr)
+	assert.Equal(t, "-ERR An error while reading rows\r\n", buf.String())
+}
+
+func TestHello(t *testing.T) {
+	result, version := hello(nil, resp2)
+	assert.Equal(t, resp2, version)
+	assert.Equal(t, ports.KindMap, result.Kind)
+
+	result, version = hello([]string{"3", "SETNAME", "app"}, resp2)
+	assert.Equal(t, resp3, version)
+	assert.Contains(t, result.Elems, ports.Integer(resp3))
+
+	for _, args := range [][]string{{"4"}, {"3", "AUTH", "user", "pass"}, {"3", "SETNAME"}} {
+		result, version = hello(args, resp2)
+		assert.Equal(t, resp2, version)
+		assert.Equal(t, ports.KindError, result.Kind)
+	}
+}
+
+func TestRESPConnection(t *testing.T) {
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, `"SET" "key" "two words"`).Return(ports.Simple("OK"), nil)
+	executor.EXPECT().Execute(mock.Anything, "GET missing").Return(ports.Nil(), nil)
+
+	server, err := NewServer(executor, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{})
+	require.NoError(t, err)
+
+	client, conn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.handleConnection(context.Background(), conn)
+	}()
+
+	reader := bufio.NewReader(client)
+	call := func(request string, lines int) string {
+		_, err := client.Write([]byte(request))
+		require.NoError(t, err)
+
+		var reply strings.Builder
+		for range lines {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			reply.WriteString(line)
+		}
+
+		return reply.String()
+	}
+
+	assert.Equal(t, "+OK\r\n", call("*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$9\r\ntwo words\r\n", 1))
+	assert.Equal(t, "$-1\r\n", call("GET missing\r\n", 1))
+	assert.True(t, strings.HasPrefix(call("*2\r\n$5\r\nhello\r\n$1\r\n3\r\n", 20), "%5"))
+	assert.Equal(t, "_\r\n", call("GET missing\r\n", 1))
+
+	client.Close()
+	<-done
+}
+
+func TestInvalidProtocol(t *testing.T) {
+	_, err := NewServer(mocks.NewExecutor(t), slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{Protocol: "http"})
+	assert.ErrorIs(t, err, errInvalidProtocol)
+}
@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
-	"strings"
+	"sync"
+	"time"
 
-	"kdb/internal/ports"
+	"github.com/cat-go-dev/kdb/internal/ports"
 )
 
 type Server struct {
@@ -16,14 +17,49 @@ type Server struct {
 	logger   *slog.Logger
 	executor Executor
 	conns    map[string]struct{}
+	// handlers are awaited by Run, so writes are done when it returns
+	handlers sync.WaitGroup
 }
 
 type ServerOpts struct {
 	Host           string
 	Port           uint
 	MaxConnections uint
+	// Protocol is ProtocolAuto by default
+	Protocol string
 }
 
+// Protocols of a listener, ProtocolAuto detects RESP and framed clients by
+// the first byte they send.
+const (
+	ProtocolAuto   = "auto"
+	ProtocolLine   = "line"
+	ProtocolRESP   = "resp"
+	ProtocolFramed = "framed"
+)
+
+// Connection commands are answered by the server itself.
+const (
+	commandHello = "HELLO"
+	commandPing  = "PING"
+	commandMulti = "MULTI"
+	commandExec  = "EXEC"
+	commandBLPop = "BLPOP"
+	commandBRPop = "BRPOP"
+
+	responsePong = "PONG"
+)
+
+// blockingCommands wait for data pushed by other clients.
+var blockingCommands = map[string]bool{
+	commandBLPop: true,
+	commandBRPop: true,
+}
+
+// pipelineFlushDelay bounds how long replies of pipelined requests wait in
+// the buffer for a slow or blocking command.
+const pipelineFlushDelay = 10 * time.Millisecond
+
 type Executor interface {
 	Execute(ctx context.Context, commandStr string) (*ports.Result, error)
 }
@@ -48,6 +84,14 @@ func NewServer(executor Executor, logger *slog.Logger, opts *ServerOpts) (*Serve
 		opts.MaxConnections = defaultMaxConnections
 	}
 
+	switch opts.Protocol {
+	case "":
+		opts.Protocol = ProtocolAuto
+	case ProtocolAuto, ProtocolLine, ProtocolRESP, ProtocolFramed:
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidProtocol, opts.Protocol)
+	}
+
 	return &Server{
 		executor: executor,
 		opts:     opts,
@@ -84,7 +128,13 @@ func (s *Server) Run(ctx context.Context) error {
 	for {
 		select {
 		case conn := <-conns:
+			s.handlers.Add(1)
 			go func() {
+				defer s.handlers.Done()
+				// a canceled server closes the connection to stop its reads
+				stop := context.AfterFunc(ctx, func() { conn.Close() })
+				defer stop()
+
 				defer func() {
 					if r := recover(); r != nil {
 						s.logger.ErrorContext(ctx, "caught panic: %s", r)
@@ -113,6 +163,7 @@ func (s *Server) Run(ctx context.Context) error {
 			}()
 		case <-ctx.Done():
 			s.logger.WarnContext(ctx, "server stopped by canceled context", logAttrs...)
+			s.handlers.Wait()
 			return errCanceledContext
 		}
 	}
@@ -149,30 +200,95 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) error {
 	}
 
 	reader := bufio.NewReader(conn)
+	protocol, err := s.detectProtocol(reader)
+	if err != nil {
+		wErr := fmt.Errorf("trying to read conn string: %w", err)
+		s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+		return wErr
+	}
+
+	if protocol == ProtocolFramed {
+		return s.handleFramedConnection(ctx, conn, reader)
+	}
+
+	readRequest := readLineRequest
+	if protocol == ProtocolRESP {
+		readRequest = readRESPRequest
+	}
+
+	// databases with sessions keep transactions per connection
+	executor := s.executor
+	if provider, ok := s.executor.(ports.SessionProvider); ok {
+		session := provider.NewSession()
+		defer session.Close()
+
+		executor = session
+	}
+
+	// commands are read in the background, so a client which disconnects
+	// while a blocking command waits cancels the command
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var readErr error
+	requests := make(chan request)
+	go func() {
+		defer cancel()
+		defer close(requests)
+
+		for {
+			req, err := readRequest(reader)
+			if err != nil {
+				readErr = err
+				return
+			}
+			req.more = reader.Buffered() > 0
+
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				readErr = ctx.Err()
+				return
+			}
+		}
+	}()
+
+	// replies are flushed once the client has no more pipelined requests
+	replies := &replyWriter{writer: bufio.NewWriter(conn)}
+
+	// RESP connections start with RESP2 until HELLO switches the version
+	version := resp2
 	for {
-		command, err := reader.ReadString('\n')
-		if err != nil {
-			wErr := fmt.Errorf("trying to read conn string: %w", err)
+		req, ok := <-requests
+		if !ok {
+			wErr := fmt.Errorf("trying to read conn string: %w", readErr)
 			s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
 			return wErr
 		}
 
-		command = strings.TrimSpace(command)
+		command := req.command()
 		s.logger.InfoContext(ctx, fmt.Sprintf("Got message: %v", command), logAttrs...)
 
-		var response string
-		result, err := s.executor.Execute(ctx, command)
-		if err != nil {
-			wErr := fmt.Errorf("execute error: %w", err)
-			s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
-			response = fmt.Sprintf("An error while executing command: %s", command)
-		} else {
-			response = result.Msg
+		var result *ports.Result
+		switch {
+		case req.resp && req.name() == commandHello:
+			result, version = hello(req.arguments(), version)
+		case req.name() == commandPing && len(req.arguments()) == 0:
+			result = ports.Simple(responsePong)
+		default:
+			stop := replies.flushLater()
+
+			var err error
+			result, err = executor.Execute(ctx, command)
+			stop()
+			if err != nil {
+				wErr := fmt.Errorf("execute error: %w", err)
+				s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
+				result = errorResponse(command, err)
+			}
 		}
 
-		response = fmt.Sprintf("%s\n", response)
-
-		_, err = conn.Write([]byte(response))
+		err := replies.write(req, result, version)
 		if err != nil {
 			wErr := fmt.Errorf("trying to response: %w", err)
 			s.logger.ErrorContext(ctx, wErr.Error(), logAttrs...)
@@ -181,6 +297,77 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) error {
 	}
 }
 
+// replyWriter buffers replies of a connection, the buffer is flushed when
+// the request has no followers already sent by the client.
+type replyWriter struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+}
+
+func (w *replyWriter) write(req request, result *ports.Result, version int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	if req.resp {
+		err = encodeRESP(w.writer, result, version)
+	} else {
+		err = encodeReply(w.writer, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if req.more {
+		return nil
+	}
+
+	return w.writer.Flush()
+}
+
+// flushLater flushes buffered replies if the next command does not finish
+// within pipelineFlushDelay, the returned function cancels it.
+func (w *replyWriter) flushLater() func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.writer.Buffered() == 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(pipelineFlushDelay, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		// an error stays in the writer and fails the next reply
+		w.writer.Flush()
+	})
+
+	return func() { timer.Stop() }
+}
+
+// detectProtocol picks the protocol of a connection, in the auto mode it is
+// told by the first byte.
+func (s *Server) detectProtocol(reader *bufio.Reader) (string, error) {
+	if s.opts.Protocol != ProtocolAuto {
+		return s.opts.Protocol, nil
+	}
+
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	switch first[0] {
+	case respMultiBulkPrefix:
+		return ProtocolRESP, nil
+	case frameMagic[0]:
+		return ProtocolFramed, nil
+	}
+
+	return ProtocolLine, nil
+}
+
 func (s *Server) rejectConnByMaxConnCount(ctx context.Context, conn net.Conn) error {
 	logAttrs := []any{
 		slog.String("component", "tcp_server"),
@@ -230,3 +417,14 @@ func (s *Server) getAddress() string {
 
 	return fmt.Sprintf("%s:%d", host, port)
 }
+
+// errorResponse reports client errors with their codes, internal errors
+// are hidden behind a generic message.
+func errorResponse(command string, err error) *ports.Result {
+	result := ports.Error(err)
+	if result.Code == ports.CodeInternal {
+		result.Str = fmt.Sprintf("An error while executing command: %s", command)
+	}
+
+	return result
+}
@@ -1,15 +1,24 @@
 package tcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
-	"kdb/internal/network/tcp/mocks"
+	"github.com/cat-go-dev/kdb/internal/network/tcp/mocks"
+	"github.com/cat-go-dev/kdb/internal/ports"
 )
 
 func TestNewServerEmptyExecutor(t *testing.T) {
@@ -70,3 +79,156 @@ func TestExitWithContextTimeout(t *testing.T) {
 	assert.ErrorContains(t, err, expectedErr)
 	cancel()
 }
+
+func TestRunWaitsForConnections(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, "SET a 1").RunAndReturn(func(context.Context, string) (*ports.Result, error) {
+		close(started)
+		<-release
+		finished.Store(true)
+		return ports.Simple("OK"), nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	server, err := NewServer(executor, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{
+		Host: "127.0.0.1",
+		Port: uint(port),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		server.Run(ctx)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("SET a 1\n"))
+	require.NoError(t, err)
+	<-started
+
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("server stopped before the command finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+	assert.True(t, finished.Load())
+}
+
+func TestErrorResponse(t *testing.T) {
+	wrapped := fmt.Errorf("storage call: %w", ports.ErrWrongType)
+
+	result := errorResponse("LPUSH key a", wrapped)
+	assert.Equal(t, ports.CodeWrongType, result.Code)
+	assert.Equal(t, ports.ErrWrongType.Message, result.Str)
+
+	result = errorResponse("GET key", errors.New("disk failure"))
+	assert.Equal(t, ports.CodeInternal, result.Code)
+	assert.Equal(t, "An error while executing command: GET key", result.Str)
+}
+
+// countingConn counts writes to the connection.
+type countingConn struct {
+	net.Conn
+	writes atomic.Int32
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.writes.Add(1)
+	return c.Conn.Write(b)
+}
+
+func TestPipelinedReplies(t *testing.T) {
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, "SET a 1").Return(ports.Simple("OK"), nil)
+	executor.EXPECT().Execute(mock.Anything, "GET a").Return(ports.Bulk("1"), nil)
+	executor.EXPECT().Execute(mock.Anything, "DEL a").Return(ports.Integer(1), nil)
+
+	server, err := NewServer(executor, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{})
+	require.NoError(t, err)
+
+	client, serverConn := net.Pipe()
+	conn := &countingConn{Conn: serverConn}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.handleConnection(context.Background(), conn)
+	}()
+
+	_, err = client.Write([]byte("SET a 1\nGET a\nPING\nDEL a\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(client)
+	var replies strings.Builder
+	for range 5 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		replies.WriteString(line)
+	}
+
+	assert.Equal(t, "+OK\n$1\n1\n+PONG\n:1\n", replies.String())
+	// replies of pipelined requests are flushed at once
+	assert.Equal(t, int32(1), conn.writes.Load())
+
+	client.Close()
+	<-done
+}
+
+func TestFlushBeforeSlowCommand(t *testing.T) {
+	executor := mocks.NewExecutor(t)
+	executor.EXPECT().Execute(mock.Anything, "GET a").Return(ports.Bulk("1"), nil)
+
+	release := make(chan struct{})
+	executor.EXPECT().Execute(mock.Anything, "BLPOP list 0").RunAndReturn(func(context.Context, string) (*ports.Result, error) {
+		<-release
+		return ports.Nil(), nil
+	})
+
+	server, err := NewServer(executor, slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), &ServerOpts{})
+	require.NoError(t, err)
+
+	client, conn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.handleConnection(context.Background(), conn)
+	}()
+
+	_, err = client.Write([]byte("GET a\nBLPOP list 0\n"))
+	require.NoError(t, err)
+
+	// the reply of GET is not held back by the blocked command
+	reader := bufio.NewReader(client)
+	for _, expected := range []string{"$1\n", "1\n"} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+
+	close(release)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "_\n", line)
+
+	client.Close()
+	<-done
+}
@@ -0,0 +1,91 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is a stable machine-readable kind of an error, clients can branch
+// on it while messages may change.
+type Code string
+
+const (
+	CodeSyntax          Code = "ERR_SYNTAX"
+	CodeUnknownCommand  Code = "ERR_UNKNOWN_COMMAND"
+	CodeWrongArguments  Code = "ERR_WRONG_ARGUMENTS"
+	CodeInvalidArgument Code = "ERR_INVALID_ARGUMENT"
+	CodeWrongType       Code = "ERR_WRONGTYPE"
+	CodeOutOfMemory     Code = "ERR_OOM"
+	CodeNotInteger      Code = "ERR_NOT_INTEGER"
+	CodeNotFloat        Code = "ERR_NOT_FLOAT"
+	CodeOverflow        Code = "ERR_OVERFLOW"
+	CodeTransaction     Code = "ERR_TRANSACTION"
+	CodeExecAborted     Code = "ERR_EXECABORT"
+	CodeReadOnly        Code = "ERR_READONLY"
+	// CodeNotSupported is a feature which is disabled or missing in the engine
+	CodeNotSupported Code = "ERR_NOT_SUPPORTED"
+	// CodeBusy is an operation which is already running, like a snapshot save
+	CodeBusy Code = "ERR_BUSY"
+	// CodeInternal hides errors which are not meant for clients
+	CodeInternal Code = "ERR_INTERNAL"
+)
+
+// ClientError is an error which is reported to clients with its code and
+// message, other errors are internal.
+type ClientError struct {
+	Code    Code
+	Message string
+	// err is the general error detailed by this one
+	err error
+}
+
+func NewClientError(code Code, message string) *ClientError {
+	return &ClientError{Code: code, Message: message}
+}
+
+func (e *ClientError) Error() string {
+	return e.Message
+}
+
+func (e *ClientError) Unwrap() error {
+	return e.err
+}
+
+// Detail extends the message keeping the code, errors.Is still matches the
+// detailed error.
+func (e *ClientError) Detail(format string, args ...any) *ClientError {
+	return &ClientError{
+		Code:    e.Code,
+		Message: e.Message + ": " + fmt.Sprintf(format, args...),
+		err:     e,
+	}
+}
+
+// AsClientError finds the outermost client error in the chain of err.
+func AsClientError(err error) (*ClientError, bool) {
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr, true
+	}
+
+	return nil, false
+}
+
+// CodeOf returns the code of err, errors without one are internal.
+func CodeOf(err error) Code {
+	if clientErr, ok := AsClientError(err); ok {
+		return clientErr.Code
+	}
+
+	return CodeInternal
+}
+
+var (
+	ErrOutOfMemory = NewClientError(CodeOutOfMemory, "command not allowed when used memory > 'max_memory'")
+
+	ErrNotInteger = NewClientError(CodeNotInteger, "value is not an integer or out of range")
+	ErrNotFloat   = NewClientError(CodeNotFloat, "value is not a valid float")
+	ErrOverflow   = NewClientError(CodeOverflow, "increment or decrement would overflow")
+
+	ErrWrongType = NewClientError(CodeWrongType, "Operation against a key holding the wrong kind of value")
+)
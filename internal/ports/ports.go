@@ -6,6 +6,141 @@ type Database interface {
 	Execute(ctx context.Context, commandStr string) (*Result, error)
 }
 
+// SessionProvider is implemented by databases which keep per-connection
+// state like transactions. Every connection gets its own session.
+type SessionProvider interface {
+	NewSession() Session
+}
+
+// Session executes commands of a single connection, it is not safe for
+// concurrent use and has to be closed when the connection is gone.
+type Session interface {
+	Execute(ctx context.Context, commandStr string) (*Result, error)
+	Close()
+}
+
+// Kind is the type of a reply.
+type Kind int
+
+const (
+	// KindNil is a missing value, it differs from an empty string
+	KindNil Kind = iota
+	// KindSimple is a status like OK
+	KindSimple
+	// KindBulk is a stored value
+	KindBulk
+	KindInteger
+	// KindArray holds either Elems or streamed Rows of bulk strings
+	KindArray
+	KindError
+	// KindMap holds flat key value pairs in Elems, protocols without maps
+	// send it as an array
+	KindMap
+)
+
+// Result is a typed reply of a command.
 type Result struct {
-	Msg string
+	Kind  Kind
+	Str   string
+	Int   int64
+	Elems []*Result
+	// Rows streams big arrays of bulk strings, the receiver has to close it
+	Rows Rows
+	// Code and Err of error replies, Str is the message for clients
+	Code Code
+	Err  error
+	// Array marks a nil reply given in place of an array
+	Array bool
+}
+
+func Nil() *Result {
+	return &Result{Kind: KindNil}
+}
+
+// NilArray is a missing array like the timeout of a blocking pop, RESP2
+// tells it apart from a missing string.
+func NilArray() *Result {
+	return &Result{Kind: KindNil, Array: true}
+}
+
+func Simple(s string) *Result {
+	return &Result{Kind: KindSimple, Str: s}
+}
+
+func Bulk(s string) *Result {
+	return &Result{Kind: KindBulk, Str: s}
+}
+
+func Integer(n int64) *Result {
+	return &Result{Kind: KindInteger, Int: n}
+}
+
+func Array(elems ...*Result) *Result {
+	if elems == nil {
+		elems = []*Result{}
+	}
+
+	return &Result{Kind: KindArray, Elems: elems}
+}
+
+// Map is a map of flat key value pairs.
+func Map(pairs ...*Result) *Result {
+	if pairs == nil {
+		pairs = []*Result{}
+	}
+
+	return &Result{Kind: KindMap, Elems: pairs}
+}
+
+// BulkArray is an array of bulk strings.
+func BulkArray(values []string) *Result {
+	elems := make([]*Result, 0, len(values))
+	for _, value := range values {
+		elems = append(elems, Bulk(value))
+	}
+
+	return Array(elems...)
+}
+
+// Stream is an array of bulk strings read lazily from rows.
+func Stream(rows Rows) *Result {
+	return &Result{Kind: KindArray, Rows: rows}
+}
+
+// Error is a reply with a failure of a command. Messages of internal
+// errors are not shown to clients.
+func Error(err error) *Result {
+	clientErr, ok := AsClientError(err)
+	if !ok {
+		return &Result{Kind: KindError, Str: internalErrorMessage, Code: CodeInternal, Err: err}
+	}
+
+	return &Result{Kind: KindError, Str: clientErr.Message, Code: clientErr.Code, Err: err}
+}
+
+const internalErrorMessage = "internal error"
+
+// Rows is a lazily read sequence of reply rows, Next has to be called
+// before reading the first row.
+type Rows interface {
+	Next() bool
+	Row() string
+	Err() error
+	Close() error
+}
+
+type blockingKey struct{}
+
+// WithBlocking returns a context which calls fn every time a command starts
+// waiting for data of other clients, so requests after it can be served
+// meanwhile.
+func WithBlocking(ctx context.Context, fn func()) context.Context {
+	return context.WithValue(ctx, blockingKey{}, fn)
+}
+
+// Blocking reports that the command of ctx starts waiting.
+func Blocking(ctx context.Context) {
+	if fn, ok := ctx.Value(blockingKey{}).(func()); ok {
+		fn()
+	}
 }
@@ -0,0 +1,18 @@
+// Package transport lets the public client run over backends of the module
+// without exposing internal types in its API.
+package transport
+
+import (
+	"context"
+
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// Backend executes commands in order and returns their replies.
+type Backend interface {
+	Do(ctx context.Context, commands ...string) ([]*ports.Result, error)
+}
+
+// NewClient builds a *client.Client which sends commands to backend, it is
+// set by pkg/client when it is imported.
+var NewClient func(backend Backend) any
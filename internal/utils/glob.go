@@ -0,0 +1,98 @@
+package utils
+
+// MatchGlob reports whether s matches the redis style glob pattern:
+// '*' matches any sequence, '?' any single byte, '[abc]', '[^abc]' and
+// '[a-z]' match a set of bytes and '\' escapes the next byte.
+func MatchGlob(pattern, s string) bool {
+	// the last star and the position in s it was tried from, so a mismatch
+	// backtracks by letting the star consume one more byte
+	star, starS := -1, 0
+	p, i := 0, 0
+
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				star, starS = p, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '[':
+				if next, ok := matchClass(pattern, p, s[i]); ok {
+					p = next
+					i++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		if star < 0 {
+			return false
+		}
+
+		starS++
+		p, i = star+1, starS
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// matchClass matches c against the class starting at pattern[start] and
+// returns the position after the class. An unclosed class matches a
+// literal '['.
+func matchClass(pattern string, start int, c byte) (int, bool) {
+	p := start + 1
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+	for ; p < len(pattern) && pattern[p] != ']'; p++ {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			p++
+			if pattern[p] == c {
+				matched = true
+			}
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			low, high := pattern[p], pattern[p+2]
+			if low > high {
+				low, high = high, low
+			}
+			if low <= c && c <= high {
+				matched = true
+			}
+			p += 2
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+		}
+	}
+
+	if p == len(pattern) {
+		return start + 1, c == '['
+	}
+
+	return p + 1, matched != negate
+}
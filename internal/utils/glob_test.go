@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{pattern: "*", s: "", expected: true},
+		{pattern: "*", s: "anything", expected: true},
+		{pattern: "user:*", s: "user:1", expected: true},
+		{pattern: "user:*", s: "session:1", expected: false},
+		{pattern: "*:name", s: "user:1:name", expected: true},
+		{pattern: "a*b*c", s: "aXbYbZc", expected: true},
+		{pattern: "a*b*c", s: "aXbYbZ", expected: false},
+		{pattern: "h?llo", s: "hello", expected: true},
+		{pattern: "h?llo", s: "hllo", expected: false},
+		{pattern: "h[ae]llo", s: "hallo", expected: true},
+		{pattern: "h[ae]llo", s: "hillo", expected: false},
+		{pattern: "h[^e]llo", s: "hallo", expected: true},
+		{pattern: "h[^e]llo", s: "hello", expected: false},
+		{pattern: "key[0-9]", s: "key5", expected: true},
+		{pattern: "key[0-9]", s: "keyx", expected: false},
+		{pattern: `key\*`, s: "key*", expected: true},
+		{pattern: `key\*`, s: "key1", expected: false},
+		{pattern: `[\]]`, s: "]", expected: true},
+		{pattern: "[abc", s: "[abc", expected: true},
+		{pattern: "exact", s: "exact", expected: true},
+		{pattern: "exact", s: "exactly", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.s, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchGlob(tt.pattern, tt.s))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errInvalidSize = errors.New("invalid size")
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{suffix: "GB", multiplier: 1 << 30},
+	{suffix: "MB", multiplier: 1 << 20},
+	{suffix: "KB", multiplier: 1 << 10},
+	{suffix: "B", multiplier: 1},
+}
+
+// ParseSize parses human readable sizes like "4KB" or "512MB" into bytes.
+// A value without suffix is treated as bytes.
+func ParseSize(size string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(size))
+	if str == "" {
+		return 0, errInvalidSize
+	}
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(str, unit.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%w: %q", errInvalidSize, size)
+	}
+
+	return value * multiplier, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     string
+		expected int64
+	}{
+		{name: "bytes without suffix", size: "100", expected: 100},
+		{name: "bytes", size: "100B", expected: 100},
+		{name: "kilobytes", size: "4KB", expected: 4 << 10},
+		{name: "megabytes", size: "512MB", expected: 512 << 20},
+		{name: "gigabytes", size: "2GB", expected: 2 << 30},
+		{name: "lower case with spaces", size: " 10 mb ", expected: 10 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseSize(tt.size)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseSizeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		size string
+	}{
+		{name: "empty", size: ""},
+		{name: "unknown suffix", size: "10TB"},
+		{name: "negative", size: "-1KB"},
+		{name: "only suffix", size: "MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSize(tt.size)
+			assert.ErrorIs(t, err, errInvalidSize)
+		})
+	}
+}
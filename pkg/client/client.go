@@ -0,0 +1,227 @@
+// Package client is a Go client of kdb servers with typed commands.
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/ports"
+	"github.com/cat-go-dev/kdb/internal/transport"
+)
+
+// Client sends commands over a pool of connections which survives restarts
+// of the server, it is safe for concurrent use.
+type Client struct {
+	backend transport.Backend
+	cancel  context.CancelFunc
+	closed  context.Context
+}
+
+func init() {
+	transport.NewClient = func(backend transport.Backend) any {
+		return newWithBackend(backend)
+	}
+}
+
+type Options struct {
+	Host string
+	Port int
+	// MinConnections and MaxConnections bound the pool of connections
+	MinConnections int
+	MaxConnections int
+	DialTimeout    time.Duration
+	// CallTimeout limits calls with contexts without a deadline
+	CallTimeout time.Duration
+	// Logger discards logs by default
+	Logger *slog.Logger
+}
+
+// New connects to the server, the connections are kept until Close.
+func New(ctx context.Context, opts *Options) (*Client, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("%w: options are required", errInvalidOptions)
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	pool, err := tcp.NewPool(logger, &tcp.PoolOpts{
+		Server:         opts.Host,
+		Port:           opts.Port,
+		MinConnections: opts.MinConnections,
+		MaxConnections: opts.MaxConnections,
+		DialTimeout:    opts.DialTimeout,
+		CallTimeout:    opts.CallTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidOptions, err)
+	}
+
+	// connections outlive ctx of New
+	poolCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	err = pool.Run(poolCtx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("connecting to server: %w", err)
+	}
+
+	return &Client{backend: pool, cancel: cancel, closed: poolCtx}, nil
+}
+
+// newWithBackend builds a client which sends commands to backend, other
+// packages of the module reach it through transport.NewClient.
+func newWithBackend(backend transport.Backend) *Client {
+	closed, cancel := context.WithCancel(context.Background())
+
+	return &Client{backend: backend, cancel: cancel, closed: closed}
+}
+
+// Close closes all connections.
+func (c *Client) Close() error {
+	c.cancel()
+
+	return nil
+}
+
+// Cmd is a command with its arguments like Cmd{"SET", "key", "value"}.
+type Cmd []string
+
+// Kind is the type of a reply.
+type Kind int
+
+const (
+	// KindNil is a missing value, it differs from an empty string
+	KindNil Kind = iota
+	// KindSimple is a status like OK
+	KindSimple
+	// KindBulk is a stored value
+	KindBulk
+	KindInteger
+	// KindArray holds Elems, maps are flattened into key value pairs
+	KindArray
+	KindError
+)
+
+var kinds = map[ports.Kind]Kind{
+	ports.KindNil:     KindNil,
+	ports.KindSimple:  KindSimple,
+	ports.KindBulk:    KindBulk,
+	ports.KindInteger: KindInteger,
+	ports.KindArray:   KindArray,
+	ports.KindError:   KindError,
+	ports.KindMap:     KindArray,
+}
+
+// Reply is a reply of a raw command.
+type Reply struct {
+	Kind  Kind
+	Str   string
+	Int   int64
+	Elems []*Reply
+	// Err is the *Error of an error reply
+	Err error
+}
+
+func newReply(result *ports.Result) *Reply {
+	reply := &Reply{Kind: kinds[result.Kind], Str: result.Str, Int: result.Int}
+	if result.Kind == ports.KindError {
+		reply.Err = &Error{Code: Code(result.Code), Message: result.Str}
+	}
+
+	for _, elem := range result.Elems {
+		reply.Elems = append(reply.Elems, newReply(elem))
+	}
+
+	return reply
+}
+
+// Do executes a command with its arguments, error replies are returned as
+// *Error.
+func (c *Client) Do(ctx context.Context, args ...string) (*Reply, error) {
+	return c.Execute(ctx, tcp.FormatCommand(args...))
+}
+
+// Execute executes a query like `SET key "a value"`, arguments are split
+// and unquoted by the server.
+func (c *Client) Execute(ctx context.Context, query string) (*Reply, error) {
+	replies, err := c.send(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	if replies[0].Err != nil {
+		return nil, replies[0].Err
+	}
+
+	return replies[0], nil
+}
+
+// Pipeline sends all commands at once and returns their replies in the same
+// order, error replies of single commands are kept in Reply.Err.
+func (c *Client) Pipeline(ctx context.Context, cmds ...Cmd) ([]*Reply, error) {
+	commands := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		commands = append(commands, tcp.FormatCommand(cmd...))
+	}
+
+	return c.send(ctx, commands)
+}
+
+func (c *Client) send(ctx context.Context, commands []string) ([]*Reply, error) {
+	if c.closed.Err() != nil {
+		return nil, ErrClosed
+	}
+
+	results, err := c.backend.Do(ctx, commands...)
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]*Reply, 0, len(results))
+	for _, result := range results {
+		replies = append(replies, newReply(result))
+	}
+
+	return replies, nil
+}
+
+// Transaction executes commands atomically with MULTI and EXEC, a command
+// rejected while queued aborts all of them with ErrExecAborted.
+func (c *Client) Transaction(ctx context.Context, cmds ...Cmd) ([]*Reply, error) {
+	pipeline := make([]Cmd, 0, len(cmds)+2)
+	pipeline = append(pipeline, Cmd{"MULTI"})
+	pipeline = append(pipeline, cmds...)
+	pipeline = append(pipeline, Cmd{"EXEC"})
+
+	replies, err := c.Pipeline(ctx, pipeline...)
+	if err != nil {
+		return nil, err
+	}
+
+	if replies[0].Err != nil {
+		return nil, replies[0].Err
+	}
+
+	exec := replies[len(replies)-1]
+	if exec.Err != nil {
+		// the reason of the abort is the reply of the rejected command
+		for _, queued := range replies[1 : len(replies)-1] {
+			if queued.Err != nil {
+				return nil, fmt.Errorf("%w: %w", exec.Err, queued.Err)
+			}
+		}
+
+		return nil, exec.Err
+	}
+	if exec.Kind != KindArray {
+		return nil, fmt.Errorf("%w: EXEC replied with kind %d", ErrUnexpectedReply, exec.Kind)
+	}
+
+	return exec.Elems, nil
+}
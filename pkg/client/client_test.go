@@ -0,0 +1,253 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/internal/database"
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	"github.com/cat-go-dev/kdb/internal/network/tcp"
+	"github.com/cat-go-dev/kdb/internal/ports"
+)
+
+// newTestClient runs a server with an in-memory database.
+func newTestClient(t *testing.T) *Client {
+	logger := slog.New(slog.NewTextHandler(new(bytes.Buffer), nil))
+
+	compute, err := compute.NewCompute(logger)
+	require.NoError(t, err)
+	storage, err := storage.NewStorage(engine.NewEngine(), logger)
+	require.NoError(t, err)
+	db, err := database.NewDatabase(compute, storage, logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	server, err := tcp.NewServer(db, logger, &tcp.ServerOpts{Host: "127.0.0.1", Port: uint(port)})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go server.Run(ctx)
+
+	var client *Client
+	require.Eventually(t, func() bool {
+		client, err = New(ctx, &Options{Host: "127.0.0.1", Port: port})
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestStrings(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	_, found, err := client.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, client.Set(ctx, "key", "two words\nand \"quotes\""))
+	value, found, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "two words\nand \"quotes\"", value)
+
+	require.NoError(t, client.Set(ctx, "empty", ""))
+	values, err := client.MGet(ctx, "key", "empty", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "two words\nand \"quotes\"", "empty": ""}, values)
+
+	n, err := client.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = client.DecrBy(ctx, "counter", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), n)
+
+	removed, err := client.Del(ctx, "key", "counter", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+}
+
+func TestExpiration(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	require.NoError(t, client.Set(ctx, "key", "value", WithTTL(time.Minute)))
+	ttl, found, err := client.TTL(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	persisted, err := client.Persist(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, persisted)
+
+	ttl, _, err = client.TTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, NoExpiration, ttl)
+
+	ok, err := client.Expire(ctx, "missing", time.Second)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, found, err = client.TTL(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestSubSecondExpiration(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	require.NoError(t, client.Set(ctx, "set", "value", WithTTL(time.Nanosecond)))
+	require.NoError(t, client.Set(ctx, "expire", "value"))
+	ok, err := client.Expire(ctx, "expire", 500*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ttl, found, err := client.TTL(ctx, "expire")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Greater(t, ttl, time.Duration(0))
+
+	require.Eventually(t, func() bool {
+		_, found, err := client.Get(ctx, "expire")
+		return err == nil && !found
+	}, 2*time.Second, 50*time.Millisecond)
+	_, found, err = client.Get(ctx, "set")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCollections(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	_, err := client.RPush(ctx, "list", "a", "b", "c")
+	require.NoError(t, err)
+	elems, err := client.LRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, elems)
+
+	value, found, err := client.LPop(ctx, "list")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a", value)
+
+	_, err = client.HSet(ctx, "hash", map[string]string{"f1": "v1", "f2": "v2"})
+	require.NoError(t, err)
+	fields, err := client.HGetAll(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"f1": "v1", "f2": "v2"}, fields)
+	_, found, err = client.HGet(ctx, "hash", "f3")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = client.SAdd(ctx, "set", "x", "y")
+	require.NoError(t, err)
+	member, err := client.SIsMember(ctx, "set", "y")
+	require.NoError(t, err)
+	assert.True(t, member)
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	_, err := client.LPush(ctx, "list", "a")
+	require.NoError(t, err)
+
+	_, _, err = client.Get(ctx, "list")
+	assert.ErrorIs(t, err, ErrWrongType)
+
+	var serverErr *Error
+	require.ErrorAs(t, err, &serverErr)
+	assert.Equal(t, CodeWrongType, serverErr.Code)
+
+	_, err = client.Do(ctx, "NOPE")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+
+	require.NoError(t, client.Set(ctx, "text", "abc"))
+	_, err = client.Incr(ctx, "text")
+	assert.ErrorIs(t, err, ErrNotInteger)
+
+	client.Close()
+	_, _, err = client.Get(ctx, "text")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestPipelineAndTransaction(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	replies, err := client.Pipeline(ctx, Cmd{"SET", "a", "1"}, Cmd{"INCR", "a"}, Cmd{"LPOP", "a"})
+	require.NoError(t, err)
+	require.Len(t, replies, 3)
+	assert.Equal(t, "OK", replies[0].Str)
+	assert.Equal(t, int64(2), replies[1].Int)
+	assert.ErrorIs(t, replies[2].Err, ErrWrongType)
+
+	replies, err = client.Transaction(ctx, Cmd{"INCR", "a"}, Cmd{"GET", "a"})
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, int64(3), replies[0].Int)
+	assert.Equal(t, "3", replies[1].Str)
+
+	_, err = client.Transaction(ctx, Cmd{"INCR", "a"}, Cmd{"SET", "a"})
+	assert.ErrorIs(t, err, ErrExecAborted)
+	assert.ErrorIs(t, err, ErrWrongArguments)
+
+	value, _, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "3", value)
+}
+
+func TestCodesMatchServer(t *testing.T) {
+	codes := map[Code]ports.Code{
+		CodeSyntax:          ports.CodeSyntax,
+		CodeUnknownCommand:  ports.CodeUnknownCommand,
+		CodeWrongArguments:  ports.CodeWrongArguments,
+		CodeInvalidArgument: ports.CodeInvalidArgument,
+		CodeWrongType:       ports.CodeWrongType,
+		CodeOutOfMemory:     ports.CodeOutOfMemory,
+		CodeNotInteger:      ports.CodeNotInteger,
+		CodeNotFloat:        ports.CodeNotFloat,
+		CodeOverflow:        ports.CodeOverflow,
+		CodeTransaction:     ports.CodeTransaction,
+		CodeExecAborted:     ports.CodeExecAborted,
+		CodeReadOnly:        ports.CodeReadOnly,
+		CodeNotSupported:    ports.CodeNotSupported,
+		CodeBusy:            ports.CodeBusy,
+		CodeInternal:        ports.CodeInternal,
+	}
+
+	for code, expected := range codes {
+		assert.Equal(t, string(expected), string(code))
+	}
+}
+
+func TestReplyKinds(t *testing.T) {
+	reply := newReply(ports.Map(ports.Bulk("field"), ports.Nil()))
+	assert.Equal(t, KindArray, reply.Kind)
+	assert.Equal(t, []Kind{KindBulk, KindNil}, []Kind{reply.Elems[0].Kind, reply.Elems[1].Kind})
+
+	reply = newReply(ports.Error(ports.NewClientError(ports.CodeWrongType, "wrong type")))
+	assert.Equal(t, KindError, reply.Kind)
+	assert.ErrorIs(t, reply.Err, ErrWrongType)
+}
@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// NoExpiration is the TTL of keys which do not expire.
+const NoExpiration time.Duration = -1
+
+// Get returns the value of key, found is false for a missing key.
+func (c *Client) Get(ctx context.Context, key string) (value string, found bool, err error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return "", false, err
+	}
+
+	return optionalString("GET", reply)
+}
+
+// SetOption changes how Set stores a value.
+type SetOption func(args []string) []string
+
+// WithTTL expires the key after ttl, it is rounded up to milliseconds.
+func WithTTL(ttl time.Duration) SetOption {
+	return func(args []string) []string {
+		if ttl%time.Second == 0 {
+			return append(args, "EX", strconv.FormatInt(int64(ttl/time.Second), 10))
+		}
+
+		return append(args, "PX", milliseconds(ttl))
+	}
+}
+
+func (c *Client) Set(ctx context.Context, key, value string, opts ...SetOption) error {
+	args := []string{"SET", key, value}
+	for _, opt := range opts {
+		args = opt(args)
+	}
+
+	_, err := c.Do(ctx, args...)
+
+	return err
+}
+
+// Del removes keys and returns how many of them existed.
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"DEL"}, keys...)...)
+}
+
+// MGet gets values of keys in one round trip, missing keys are not in the
+// map.
+func (c *Client) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	cmds := make([]Cmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, Cmd{"GET", key})
+	}
+
+	replies, err := c.Pipeline(ctx, cmds...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(keys))
+	for i, reply := range replies {
+		value, found, err := optionalString("GET", reply)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			values[keys[i]] = value
+		}
+	}
+
+	return values, nil
+}
+
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return c.integer(ctx, "INCR", key)
+}
+
+func (c *Client) IncrBy(ctx context.Context, key string, increment int64) (int64, error) {
+	return c.integer(ctx, "INCRBY", key, strconv.FormatInt(increment, 10))
+}
+
+func (c *Client) Decr(ctx context.Context, key string) (int64, error) {
+	return c.integer(ctx, "DECR", key)
+}
+
+func (c *Client) DecrBy(ctx context.Context, key string, decrement int64) (int64, error) {
+	return c.integer(ctx, "DECRBY", key, strconv.FormatInt(decrement, 10))
+}
+
+// Expire sets the TTL of key, it is rounded up to milliseconds and false is
+// returned for a missing key. A TTL which is not positive deletes the key.
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl%time.Second == 0 {
+		return c.boolean(ctx, "EXPIRE", key, strconv.FormatInt(int64(ttl/time.Second), 10))
+	}
+
+	return c.boolean(ctx, "PEXPIRE", key, milliseconds(ttl))
+}
+
+// milliseconds rounds ttl up, so short positive TTLs do not become zero.
+func milliseconds(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ttl%time.Millisecond > 0 {
+		ms++
+	}
+
+	return strconv.FormatInt(ms, 10)
+}
+
+// TTL returns the time to live of key or NoExpiration, found is false for
+// a missing key.
+func (c *Client) TTL(ctx context.Context, key string) (ttl time.Duration, found bool, err error) {
+	ms, err := c.integer(ctx, "PTTL", key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch ms {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return NoExpiration, true, nil
+	}
+
+	return time.Duration(ms) * time.Millisecond, true, nil
+}
+
+// Persist removes the TTL of key, it is false when key has no TTL.
+func (c *Client) Persist(ctx context.Context, key string) (bool, error) {
+	return c.boolean(ctx, "PERSIST", key)
+}
+
+func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return c.values(ctx, "KEYS", pattern)
+}
+
+func (c *Client) LPush(ctx context.Context, key string, values ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"LPUSH", key}, values...)...)
+}
+
+func (c *Client) RPush(ctx context.Context, key string, values ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"RPUSH", key}, values...)...)
+}
+
+func (c *Client) LPop(ctx context.Context, key string) (value string, found bool, err error) {
+	reply, err := c.Do(ctx, "LPOP", key)
+	if err != nil {
+		return "", false, err
+	}
+
+	return optionalString("LPOP", reply)
+}
+
+func (c *Client) RPop(ctx context.Context, key string) (value string, found bool, err error) {
+	reply, err := c.Do(ctx, "RPOP", key)
+	if err != nil {
+		return "", false, err
+	}
+
+	return optionalString("RPOP", reply)
+}
+
+// LRange returns elements from start to stop inclusive, negative indexes
+// count from the end.
+func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.values(ctx, "LRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+}
+
+func (c *Client) LLen(ctx context.Context, key string) (int64, error) {
+	return c.integer(ctx, "LLEN", key)
+}
+
+// HSet sets fields of a hash and returns the number of new fields.
+func (c *Client) HSet(ctx context.Context, key string, fields map[string]string) (int64, error) {
+	args := []string{"HSET", key}
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+
+	return c.integer(ctx, args...)
+}
+
+// HGet returns the value of a field, missing fields are empty.
+// HGet returns the value of the field, found is false for a missing field.
+func (c *Client) HGet(ctx context.Context, key, field string) (value string, found bool, err error) {
+	reply, err := c.Do(ctx, "HGET", key, field)
+	if err != nil {
+		return "", false, err
+	}
+
+	return optionalString("HGET", reply)
+}
+
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	pairs, err := c.values(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of hash elements", ErrUnexpectedReply)
+	}
+
+	fields := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		fields[pairs[i]] = pairs[i+1]
+	}
+
+	return fields, nil
+}
+
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"HDEL", key}, fields...)...)
+}
+
+func (c *Client) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"SADD", key}, members...)...)
+}
+
+func (c *Client) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.integer(ctx, append([]string{"SREM", key}, members...)...)
+}
+
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.values(ctx, "SMEMBERS", key)
+}
+
+func (c *Client) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	return c.boolean(ctx, "SISMEMBER", key, member)
+}
+
+func (c *Client) integer(ctx context.Context, args ...string) (int64, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if reply.Kind != KindInteger {
+		return 0, unexpected(args[0], reply)
+	}
+
+	return reply.Int, nil
+}
+
+func (c *Client) boolean(ctx context.Context, args ...string) (bool, error) {
+	n, err := c.integer(ctx, args...)
+
+	return n == 1, err
+}
+
+func (c *Client) values(ctx context.Context, args ...string) ([]string, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Kind != KindArray {
+		return nil, unexpected(args[0], reply)
+	}
+
+	values := make([]string, 0, len(reply.Elems))
+	for _, elem := range reply.Elems {
+		// streamed replies end with the error when they fail
+		if elem.Err != nil {
+			return nil, elem.Err
+		}
+
+		values = append(values, elem.Str)
+	}
+
+	return values, nil
+}
+
+func optionalString(command string, reply *Reply) (string, bool, error) {
+	switch reply.Kind {
+	case KindNil:
+		return "", false, nil
+	case KindBulk, KindSimple:
+		return reply.Str, true, nil
+	case KindError:
+		return "", false, reply.Err
+	}
+
+	return "", false, unexpected(command, reply)
+}
+
+func unexpected(command string, reply *Reply) error {
+	return fmt.Errorf("%w: %s replied with kind %d", ErrUnexpectedReply, command, reply.Kind)
+}
@@ -0,0 +1,71 @@
+package client
+
+import "errors"
+
+// Code is a machine-readable kind of a server error.
+type Code string
+
+const (
+	CodeSyntax          Code = "ERR_SYNTAX"
+	CodeUnknownCommand  Code = "ERR_UNKNOWN_COMMAND"
+	CodeWrongArguments  Code = "ERR_WRONG_ARGUMENTS"
+	CodeInvalidArgument Code = "ERR_INVALID_ARGUMENT"
+	CodeWrongType       Code = "ERR_WRONGTYPE"
+	CodeOutOfMemory     Code = "ERR_OOM"
+	CodeNotInteger      Code = "ERR_NOT_INTEGER"
+	CodeNotFloat        Code = "ERR_NOT_FLOAT"
+	CodeOverflow        Code = "ERR_OVERFLOW"
+	CodeTransaction     Code = "ERR_TRANSACTION"
+	CodeExecAborted     Code = "ERR_EXECABORT"
+	CodeReadOnly        Code = "ERR_READONLY"
+	CodeNotSupported    Code = "ERR_NOT_SUPPORTED"
+	CodeBusy            Code = "ERR_BUSY"
+	CodeInternal        Code = "ERR_INTERNAL"
+)
+
+// Error is an error reply of the server.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+
+	return string(e.Code) + " " + e.Message
+}
+
+// Is matches errors of the same code, so errors.Is(err, ErrWrongType)
+// holds whatever the message is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+var (
+	ErrSyntax          = &Error{Code: CodeSyntax}
+	ErrUnknownCommand  = &Error{Code: CodeUnknownCommand}
+	ErrWrongArguments  = &Error{Code: CodeWrongArguments}
+	ErrInvalidArgument = &Error{Code: CodeInvalidArgument}
+	ErrWrongType       = &Error{Code: CodeWrongType}
+	ErrOutOfMemory     = &Error{Code: CodeOutOfMemory}
+	ErrNotInteger      = &Error{Code: CodeNotInteger}
+	ErrNotFloat        = &Error{Code: CodeNotFloat}
+	ErrOverflow        = &Error{Code: CodeOverflow}
+	ErrTransaction     = &Error{Code: CodeTransaction}
+	ErrExecAborted     = &Error{Code: CodeExecAborted}
+	ErrReadOnly        = &Error{Code: CodeReadOnly}
+	ErrNotSupported    = &Error{Code: CodeNotSupported}
+	ErrBusy            = &Error{Code: CodeBusy}
+	ErrInternal        = &Error{Code: CodeInternal}
+)
+
+var (
+	ErrUnexpectedReply = errors.New("unexpected reply")
+	ErrClosed          = errors.New("client is closed")
+
+	errInvalidOptions = errors.New("invalid options")
+)
@@ -0,0 +1,261 @@
+// Package embedded runs a kdb database inside the process, it has the same
+// typed API as the network client.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/cat-go-dev/kdb/internal/config"
+	"github.com/cat-go-dev/kdb/internal/database"
+	"github.com/cat-go-dev/kdb/internal/database/compute"
+	"github.com/cat-go-dev/kdb/internal/database/storage"
+	"github.com/cat-go-dev/kdb/internal/database/storage/engine"
+	_ "github.com/cat-go-dev/kdb/internal/database/storage/engine/lsm"
+	_ "github.com/cat-go-dev/kdb/internal/database/storage/engine/mvcc"
+	"github.com/cat-go-dev/kdb/internal/database/storage/snapshot"
+	"github.com/cat-go-dev/kdb/internal/database/storage/wal"
+	"github.com/cat-go-dev/kdb/internal/ports"
+	"github.com/cat-go-dev/kdb/internal/transport"
+	"github.com/cat-go-dev/kdb/pkg/client"
+)
+
+// DB is a database with the typed API of client.Client, it is safe for
+// concurrent use.
+type DB struct {
+	*client.Client
+
+	logger  *slog.Logger
+	storage *storage.Storage
+	engine  engine.Interface
+	wal     *wal.WAL
+	// snapshots is set when the state is saved on Close, engines which can
+	// not be dumped rely on the WAL and their own files
+	snapshots bool
+	// cancel stops expiration and periodic snapshots
+	cancel context.CancelFunc
+}
+
+type options struct {
+	engine           config.Engine
+	dataDir          string
+	snapshotInterval time.Duration
+	logger           *slog.Logger
+}
+
+type Option func(*options)
+
+// WithEngine selects the engine by type like "lsm", settings are the config
+// subsection of the engine.
+func WithEngine(engineType string, settings map[string]any) Option {
+	return func(o *options) {
+		o.engine = config.Engine{
+			Type:     engineType,
+			Settings: map[string]any{engineType: settings},
+		}
+	}
+}
+
+// WithDataDir persists the database with a WAL and snapshots in dir, the
+// state is recovered from them by Open. Without it the data lives only in
+// memory.
+func WithDataDir(dir string) Option {
+	return func(o *options) {
+		o.dataDir = dir
+	}
+}
+
+// WithSnapshotInterval saves snapshots periodically, it requires a data
+// directory.
+func WithSnapshotInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.snapshotInterval = interval
+	}
+}
+
+// WithLogger sets the logger, logs are discarded by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+const (
+	walDir      = "wal"
+	snapshotDir = "snapshots"
+)
+
+// Open builds the database and recovers persisted data, it has to be closed.
+func Open(ctx context.Context, opts ...Option) (*DB, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.logger == nil {
+		o.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if o.snapshotInterval > 0 && o.dataDir == "" {
+		return nil, fmt.Errorf("%w: snapshots require a data directory", errInvalidOption)
+	}
+
+	db := &DB{logger: o.logger}
+
+	var storageOpts []storage.Option
+	if o.dataDir != "" {
+		w, err := wal.NewWAL(o.logger, &wal.Opts{DataDir: filepath.Join(o.dataDir, walDir)})
+		if err != nil {
+			return nil, fmt.Errorf("creating wal: %w", err)
+		}
+		db.wal = w
+
+		store, err := snapshot.NewStore(o.logger, &snapshot.Opts{DataDir: filepath.Join(o.dataDir, snapshotDir)})
+		if err != nil {
+			db.close()
+			return nil, fmt.Errorf("creating snapshot store: %w", err)
+		}
+
+		storageOpts = append(storageOpts, storage.WithWAL(w), storage.WithSnapshots(store))
+	}
+
+	e, err := engine.New(o.engine, o.logger)
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("creating engine: %w", err)
+	}
+	db.engine = e
+
+	db.storage, err = storage.NewStorage(e, o.logger, storageOpts...)
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("creating storage: %w", err)
+	}
+	db.snapshots = o.dataDir != "" && db.storage.SupportsSnapshots()
+
+	err = db.storage.Recover(ctx)
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("recovering storage: %w", err)
+	}
+
+	c, err := compute.NewCompute(o.logger)
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("creating compute: %w", err)
+	}
+
+	d, err := database.NewDatabase(c, db.storage, o.logger)
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("creating database: %w", err)
+	}
+
+	// background jobs live until Close, not as long as ctx of Open
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	db.cancel = cancel
+
+	go db.storage.RunExpiration(runCtx)
+	if o.snapshotInterval > 0 {
+		go db.storage.RunSnapshots(runCtx, o.snapshotInterval)
+	}
+
+	db.Client = transport.NewClient(sessions{database: d}).(*client.Client)
+
+	return db, nil
+}
+
+// Close saves a snapshot when the engine is persisted with snapshots and
+// releases files, the database can not be used after it.
+func (db *DB) Close() error {
+	db.Client.Close()
+	db.cancel()
+
+	var errs []error
+	if db.snapshots {
+		if err := db.storage.Save(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("saving snapshot: %w", err))
+		}
+	}
+
+	if err := db.close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// close releases the WAL and the engine, the WAL is flushed by Close.
+func (db *DB) close() error {
+	var errs []error
+	if db.wal != nil {
+		if err := db.wal.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing wal: %w", err))
+		}
+	}
+
+	if closer, ok := db.engine.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing engine: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sessions executes every batch of commands in its own session, so
+// transactions of client.Client work like over a connection.
+type sessions struct {
+	database *database.Database
+}
+
+func (s sessions) Do(ctx context.Context, commands ...string) ([]*ports.Result, error) {
+	session := s.database.NewSession()
+	defer session.Close()
+
+	results := make([]*ports.Result, 0, len(commands))
+	for _, command := range commands {
+		result, err := session.Execute(ctx, command)
+		if err == nil && result.Rows != nil {
+			result, err = readRows(result.Rows)
+		}
+		if err != nil {
+			result = errorResult(err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// errorResult does not hide messages of internal errors, they stay in the
+// process.
+func errorResult(err error) *ports.Result {
+	result := ports.Error(err)
+	if result.Code == ports.CodeInternal {
+		result.Str = err.Error()
+	}
+
+	return result
+}
+
+// readRows reads streamed rows while the session is open.
+func readRows(rows ports.Rows) (*ports.Result, error) {
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		values = append(values, rows.Row())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ports.BulkArray(values), nil
+}
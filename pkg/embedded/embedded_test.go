@@ -0,0 +1,130 @@
+package embedded
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cat-go-dev/kdb/pkg/client"
+)
+
+func TestInMemory(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := Open(ctx, WithEngine("ordered", nil))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.Set(ctx, "key", "a value"))
+	value, found, err := db.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a value", value)
+
+	reply, err := db.Execute(ctx, `SET other "two words"`)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", reply.Str)
+
+	reply, err = db.Execute(ctx, "RANGE a z")
+	require.NoError(t, err)
+	assert.Len(t, reply.Elems, 4)
+
+	_, err = db.Execute(ctx, "INCR key")
+	assert.ErrorIs(t, err, client.ErrNotInteger)
+
+	replies, err := db.Transaction(ctx, client.Cmd{"INCR", "n"}, client.Cmd{"INCR", "n"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), replies[1].Int)
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := Open(ctx, WithDataDir(dir))
+	require.NoError(t, err)
+
+	require.NoError(t, db.Set(ctx, "key", "value"))
+	_, err = db.RPush(ctx, "list", "a", "b")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, _, err = db.Get(ctx, "key")
+	assert.ErrorIs(t, err, client.ErrClosed)
+
+	db, err = Open(ctx, WithDataDir(dir))
+	require.NoError(t, err)
+	defer db.Close()
+
+	value, found, err := db.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+
+	elems, err := db.LRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, elems)
+}
+
+func TestReopenEngines(t *testing.T) {
+	ctx := context.Background()
+
+	engines := map[string]func(dir string) map[string]any{
+		"in_memory":         func(string) map[string]any { return nil },
+		"sharded_in_memory": func(string) map[string]any { return nil },
+		"ordered":           func(string) map[string]any { return nil },
+		"mvcc":              func(string) map[string]any { return nil },
+		"lsm": func(dir string) map[string]any {
+			return map[string]any{"data_dir": filepath.Join(dir, "lsm")}
+		},
+	}
+
+	for name, settings := range engines {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			open := func() *DB {
+				db, err := Open(ctx, WithEngine(name, settings(dir)), WithDataDir(dir))
+				require.NoError(t, err)
+				return db
+			}
+
+			db := open()
+			require.NoError(t, db.Set(ctx, "key", "value"))
+			_, err := db.Incr(ctx, "counter")
+			require.NoError(t, err)
+			require.NoError(t, db.Close())
+
+			db = open()
+			value, found, err := db.Get(ctx, "key")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "value", value)
+
+			n, err := db.Incr(ctx, "counter")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			require.NoError(t, db.Close())
+		})
+	}
+}
+
+func TestEngineOption(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "lsm")
+
+	db, err := Open(ctx, WithEngine("lsm", map[string]any{"data_dir": dir}))
+	require.NoError(t, err)
+
+	require.NoError(t, db.Set(ctx, "key", "value"))
+	require.NoError(t, db.Close())
+
+	_, err = Open(ctx, WithEngine("unknown", nil))
+	assert.Error(t, err)
+
+	_, err = Open(ctx, WithSnapshotInterval(time.Second))
+	assert.ErrorIs(t, err, errInvalidOption)
+}
@@ -0,0 +1,5 @@
+package embedded
+
+import "errors"
+
+var errInvalidOption = errors.New("invalid option")